@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	quotaModel "media-service/internal/quota/model"
+	quotaRepository "media-service/internal/quota/repository"
+	quotaService "media-service/internal/quota/service"
+	"media-service/internal/s3"
+	gcRepository "media-service/internal/storagegc/repository"
+	"media-service/internal/storagemigration/model"
+	migrationRepository "media-service/internal/storagemigration/repository"
+	migrationService "media-service/internal/storagemigration/service"
+	trashModel "media-service/internal/trash/model"
+	"media-service/pkg/config"
+	"media-service/pkg/db"
+	"media-service/pkg/objectkey"
+)
+
+const usage = `usage: mediactl <command> [flags]
+
+commands:
+  migrate-storage   copy every object referenced in Mongo to the storage in migration.destination
+  backfill-usage    record objects referenced in Mongo that are missing from the storage usage ledger
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "migrate-storage":
+		os.Exit(migrateStorage(os.Args[2:]))
+	case "backfill-usage":
+		os.Exit(backfillUsage(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// rewriteFlags gom nhiều lần -rewrite-key / -rewrite-url
+type rewriteFlags []model.Rewrite
+
+func (r *rewriteFlags) String() string {
+	parts := make([]string, 0, len(*r))
+	for _, rw := range *r {
+		parts = append(parts, rw.From+"="+rw.To)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (r *rewriteFlags) Set(s string) error {
+	rw, err := model.ParseRewrite(s)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, rw)
+	return nil
+}
+
+func migrateStorage(args []string) int {
+	fs := flag.NewFlagSet("migrate-storage", flag.ExitOnError)
+	configPath := fs.String("config", "configs/config.yaml", "service config file")
+	dryRun := fs.Bool("dry-run", false, "only report what would be copied / rewritten")
+	concurrency := fs.Int("concurrency", 0, "parallel copies, default migration.concurrency or 8")
+	statePath := fs.String("state", "migrate-storage.state", "file of finished keys, re-run with the same file to resume (empty = no resume)")
+	reportPath := fs.String("report", "", "write the JSON report to this file instead of stdout")
+	var keyRewrites, urlRewrites rewriteFlags
+	fs.Var(&keyRewrites, "rewrite-key", "rewrite key prefix from=to, repeatable; Mongo references are updated")
+	fs.Var(&urlRewrites, "rewrite-url", "rewrite stored url prefix from=to, repeatable")
+	_ = fs.Parse(args)
+
+	config.LoadConfig(*configPath)
+	configureStorage()
+	cfg := config.AppConfig
+	if err := objectkey.Init(); err != nil {
+		log.Printf("migrate-storage: %v", err)
+		return 2
+	}
+
+	source := config.StorageTarget{
+		Provider: cfg.Storage.Provider,
+		Local:    cfg.Storage.Local,
+		S3:       cfg.S3.SenboxFormSubmitBucket,
+	}
+	if cfg.Migration.Source != nil {
+		source = *cfg.Migration.Source
+	}
+	destination := cfg.Migration.Destination
+	if sameStorage(source, destination) && len(keyRewrites) == 0 {
+		log.Println("migrate-storage: source and destination are the same storage, nothing to do")
+		return 2
+	}
+	if *concurrency <= 0 {
+		*concurrency = cfg.Migration.Concurrency
+	}
+
+	db.ConnectMongoDB()
+
+	svc := migrationService.NewMigrationService(
+		gcRepository.NewReferenceRepository(
+			db.MediaAssetCollection,
+			db.UploadSessionCollection,
+			db.TopicCollection,
+			db.VocabularyCollection,
+			db.TopicResourceCollection,
+			db.VideoUploaderCollection,
+			db.PDFCollection,
+		),
+		migrationRepository.NewReferenceRepository(
+			db.StorageUsageCollection,
+			db.TopicCollection,
+			db.VocabularyCollection,
+			db.TopicResourceCollection,
+			db.VideoUploaderCollection,
+			db.PDFCollection,
+			db.MediaAssetCollection,
+			db.UploadSessionCollection,
+		),
+		s3.NewProvider(source),
+		s3.NewProvider(destination),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := svc.Run(ctx, model.Options{
+		DryRun:      *dryRun,
+		Concurrency: *concurrency,
+		StatePath:   *statePath,
+		KeyRewrites: keyRewrites,
+		URLRewrites: urlRewrites,
+	})
+	if report != nil {
+		if werr := writeReport(report, *reportPath); werr != nil {
+			log.Printf("migrate-storage: write report: %v", werr)
+		}
+	}
+	if err != nil {
+		log.Printf("migrate-storage: %v", err)
+		return 1
+	}
+	log.Printf("migrate-storage: copied %d objects (%d bytes), skipped %d, %d failures",
+		report.CopiedObjects, report.CopiedBytes, report.SkippedObjects, len(report.Failures))
+	if len(report.Failures) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func backfillUsage(args []string) int {
+	fs := flag.NewFlagSet("backfill-usage", flag.ExitOnError)
+	configPath := fs.String("config", "configs/config.yaml", "service config file")
+	dryRun := fs.Bool("dry-run", false, "only report what would be recorded")
+	reportPath := fs.String("report", "", "write the JSON report to this file instead of stdout")
+	_ = fs.Parse(args)
+
+	config.LoadConfig(*configPath)
+	configureStorage()
+	db.ConnectMongoDB()
+
+	usageRepo := quotaRepository.NewUsageRepository(db.StorageUsageCollection)
+	if err := usageRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("backfill-usage: ensure indexes: %v", err)
+		return 1
+	}
+	svc := quotaService.NewQuotaService(
+		usageRepo,
+		quotaRepository.NewObjectRepository(quotaRepository.ObjectCollections{
+			Topics:         db.TopicCollection,
+			Vocabularies:   db.VocabularyCollection,
+			TopicResources: db.TopicResourceCollection,
+			PDFResources:   db.PDFCollection,
+			VideoUploaders: db.VideoUploaderCollection,
+			MediaAssets:    db.MediaAssetCollection,
+		}),
+		s3.NewFromConfig(),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := svc.Backfill(ctx, *dryRun)
+	if report != nil {
+		if werr := writeReport(report, *reportPath); werr != nil {
+			log.Printf("backfill-usage: write report: %v", werr)
+		}
+	}
+	if err != nil {
+		log.Printf("backfill-usage: %v", err)
+		return 1
+	}
+	log.Printf("backfill-usage: recorded %d objects (%d bytes), %d already recorded, %d missing, %d errors",
+		report.RecordedObjects, report.RecordedBytes, report.ExistingObjects, report.MissingObjects, len(report.Errors))
+	if len(report.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// configureStorage truyền cho package s3 các phần thuộc domain giống server (xem router.SetupRouter),
+// sổ dung lượng không được cập nhật vì các lệnh ở đây không xoá object
+func configureStorage() {
+	s3.Configure(s3.Options{
+		MirrorPrefixes: []string{trashModel.Prefix},
+		Categories:     quotaModel.CategoryNames(),
+	})
+}
+
+func sameStorage(a, b config.StorageTarget) bool {
+	if a.Provider != b.Provider {
+		return false
+	}
+	if a.Provider == s3.ProviderLocal {
+		return a.Local.RootDir == b.Local.RootDir
+	}
+	return a.S3.BucketName == b.S3.BucketName && a.S3.Endpoint == b.S3.Endpoint
+}
+
+func writeReport(report any, path string) error {
+	out := os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
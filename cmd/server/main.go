@@ -11,6 +11,7 @@ import (
 	"media-service/pkg/config"
 	"media-service/pkg/consul"
 	"media-service/pkg/db"
+	"media-service/pkg/objectkey"
 	"media-service/pkg/router"
 
 	"media-service/pkg/zap"
@@ -36,6 +37,11 @@ func main() {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 
+	// key policy sai phải làm hỏng lúc khởi động, không phải ở lần upload đầu tiên
+	if err := objectkey.Init(); err != nil {
+		logger.Fatalf("Invalid storage config: %v", err)
+	}
+
 	//consul
 	consulConn := consul.NewConsulConn(logger, cfg)
 	consulClient := consulConn.Connect()
@@ -73,6 +79,9 @@ func main() {
 		db.VideoUploaderCollection,
 		db.MediaAssetCollection,
 		db.VocabularyCollection,
+		db.UploadSessionCollection,
+		db.DeletionOutboxCollection,
+		db.StorageUsageCollection,
 	)
 	port := cfg.Server.Port
 	if err := app.Listen(":" + port); err != nil {
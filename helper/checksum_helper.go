@@ -0,0 +1,86 @@
+package helper
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strings"
+
+	"media-service/pkg/uploader"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// checksumFieldSuffix: form field "<tên field file>_checksum", giá trị dạng "sha256:<base64 hoặc hex>"
+const checksumFieldSuffix = "_checksum"
+
+// FormChecksums đọc checksum client gửi qua form field, key là tên form field của file
+// (vd "audio_file"). Request chỉ có một file thì header x-amz-checksum-* của request cũng
+// được áp cho file đó. Request không phải multipart thì trả về map rỗng.
+func FormChecksums(c *fiber.Ctx) (map[string]*uploader.Checksum, error) {
+	checksums := map[string]*uploader.Checksum{}
+	form, err := c.MultipartForm()
+	if err != nil {
+		return checksums, nil
+	}
+
+	total := 0
+	var onlyField string
+	for field, files := range form.File {
+		if len(files) == 0 {
+			continue
+		}
+		total += len(files)
+		onlyField = field
+		values := form.Value[field+checksumFieldSuffix]
+		if len(values) == 0 || values[0] == "" {
+			continue
+		}
+		alg, value, ok := strings.Cut(values[0], ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be <algorithm>:<value>", uploader.ErrInvalidChecksum, field+checksumFieldSuffix)
+		}
+		checksum, err := uploader.ParseChecksum(alg, value)
+		if err != nil {
+			return nil, err
+		}
+		checksums[field] = checksum
+	}
+
+	if total != 1 || checksums[onlyField] != nil {
+		return checksums, nil
+	}
+	// Content-MD5 của request là md5 của cả body multipart nên không dùng ở đây
+	checksum, err := uploader.ChecksumFromHeader(func(name string) string {
+		if name == uploader.ChecksumSHA256.HeaderName() || name == uploader.ChecksumCRC32C.HeaderName() {
+			return c.Get(name)
+		}
+		return ""
+	})
+	if err != nil {
+		return nil, err
+	}
+	if checksum != nil {
+		checksums[onlyField] = checksum
+	}
+	return checksums, nil
+}
+
+// VerifyFileChecksum đọc trước file để so với checksum client gửi, dùng khi phải từ chối
+// trước khi động vào dữ liệu cũ. expected nil thì bỏ qua.
+func VerifyFileChecksum(fh *multipart.FileHeader, expected *uploader.Checksum) error {
+	if fh == nil || expected == nil {
+		return nil
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hasher := uploader.NewChecksumHasher(expected.Algorithm)
+	if _, err := io.Copy(hasher, f); err != nil {
+		return err
+	}
+	return expected.Verify(hasher.Checksum())
+}
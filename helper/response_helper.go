@@ -1,7 +1,11 @@
 package helper
 
 import (
+	"errors"
 	"media-service/logger"
+	"media-service/pkg/uploader"
+	"net/http"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -11,6 +15,8 @@ const (
 	ErrInvalidRequest   = "ERR_INVALID_REQUEST"
 	ErrNotFound         = "ERR_NOT_FOUND"
 	ErrInternal         = "ERR_INTERNAL"
+	ErrQuotaExceeded    = "ERR_QUOTA_EXCEEDED"
+	ErrChecksumMismatch = "ERR_CHECKSUM_MISMATCH"
 )
 
 type APIResponse struct {
@@ -29,6 +35,41 @@ func SendSuccess(c *fiber.Ctx, statusCode int, message string, data interface{})
 	})
 }
 
+type errorMapping struct {
+	err        error
+	statusCode int
+	errorCode  string
+}
+
+// errorMappings là các lỗi domain được đăng ký qua RegisterError
+var errorMappings []errorMapping
+
+// RegisterError đăng ký status / mã lỗi MapError trả về cho lỗi domain err (và lỗi bọc nó).
+// Gọi lúc khởi động, trước khi nhận request; helper không import package domain.
+func RegisterError(err error, statusCode int, errorCode string) {
+	errorMappings = append(errorMappings, errorMapping{err: err, statusCode: statusCode, errorCode: errorCode})
+}
+
+// MapError trả về status / mã lỗi chung cho các lỗi dùng ở nhiều handler (sai checksum, các lỗi
+// đăng ký qua RegisterError như vượt quota...). Lỗi khác giữ nguyên statusCode / errorCode handler truyền vào.
+func MapError(err error, statusCode int, errorCode string) (int, string) {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m.statusCode, m.errorCode
+		}
+	}
+	switch {
+	case errors.Is(err, uploader.ErrChecksumMismatch):
+		return http.StatusUnprocessableEntity, ErrChecksumMismatch
+	case errors.Is(err, uploader.ErrInvalidChecksum):
+		return http.StatusBadRequest, ErrInvalidRequest
+	case errors.Is(err, uploader.ErrPresignNotSupported):
+		// object SSE-C phải upload qua multipart session
+		return http.StatusBadRequest, ErrInvalidOperation
+	}
+	return statusCode, errorCode
+}
+
 func SendError(c *fiber.Ctx, statusCode int, err error, errorCode string) error {
 	var errMsg string
 	if err != nil {
@@ -52,3 +93,47 @@ func SendError(c *fiber.Ctx, statusCode int, err error, errorCode string) error
 		ErrorCode:  errorCode,
 	})
 }
+
+// SendObject stream một object đã được mở (xem s3.Service.Open) và tự đóng body.
+// err là lỗi trả về từ Open: 304 / 416 / 404 được map về đúng status.
+func SendObject(c *fiber.Ctx, obj *uploader.ObjectReader, err error) error {
+	switch {
+	case errors.Is(err, uploader.ErrNotModified):
+		// RFC 9110: 304 phải mang ETag để client giữ đúng bản cache
+		if obj != nil && obj.ETag != "" {
+			c.Set(fiber.HeaderETag, obj.ETag)
+		}
+		return c.SendStatus(http.StatusNotModified)
+	case errors.Is(err, uploader.ErrInvalidRange):
+		if obj != nil && obj.Size > 0 {
+			c.Set(fiber.HeaderContentRange, "bytes */"+strconv.FormatInt(obj.Size, 10))
+		}
+		return SendError(c, http.StatusRequestedRangeNotSatisfiable, err, ErrInvalidRequest)
+	case errors.Is(err, uploader.ErrObjectNotFound):
+		return SendError(c, http.StatusNotFound, err, ErrNotFound)
+	case err != nil:
+		return SendError(c, http.StatusInternalServerError, err, ErrInvalidOperation)
+	}
+
+	// không cho proxy / CDN dùng chung, chỉ trình duyệt của chính user được cache và revalidate bằng ETag
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	if obj.ETag != "" {
+		c.Set(fiber.HeaderETag, obj.ETag)
+	}
+	if obj.ContentType != "" {
+		c.Set(fiber.HeaderContentType, obj.ContentType)
+	}
+	if !obj.LastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, obj.LastModified.UTC().Format(http.TimeFormat))
+	}
+	c.Set(fiber.HeaderContentLength, strconv.FormatInt(obj.ContentLength, 10))
+
+	status := http.StatusOK
+	if obj.ContentRange != "" {
+		c.Set(fiber.HeaderContentRange, obj.ContentRange)
+		status = http.StatusPartialContent
+	}
+	// fasthttp đóng body khi stream xong
+	return c.Status(status).SendStream(obj.Body, int(obj.ContentLength))
+}
@@ -3,10 +3,13 @@ package helper
 import (
 	"context"
 	"fmt"
+	gw_response "media-service/internal/gateway/dto/response"
 	"media-service/internal/media/model"
 	"media-service/pkg/constants"
+	"media-service/pkg/imagevariant"
+	"media-service/pkg/mediaprobe"
+	"media-service/pkg/uploader"
 	"mime/multipart"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -46,10 +49,57 @@ func GetUserID(ctx context.Context) string {
 	return ""
 }
 
+func GetCurrentUser(ctx context.Context) *gw_response.CurrentUser {
+	currentUser, _ := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser)
+	return currentUser
+}
+
+// GetCurrentOrganizationID ưu tiên organization user đang làm admin, sau đó tới organization đang active
+func GetCurrentOrganizationID(ctx context.Context) string {
+	currentUser := GetCurrentUser(ctx)
+	if currentUser == nil {
+		return ""
+	}
+	if currentUser.OrganizationAdmin != nil && currentUser.OrganizationAdmin.ID != "" {
+		return currentUser.OrganizationAdmin.ID
+	}
+	return currentUser.OrganizationIdActive
+}
+
+// IsOrganizationMember kiểm tra user thuộc (hoặc là admin của) organization
+func IsOrganizationMember(user *gw_response.CurrentUser, organizationID string) bool {
+	if user == nil || organizationID == "" {
+		return false
+	}
+	if user.OrganizationAdmin != nil && user.OrganizationAdmin.ID == organizationID {
+		return true
+	}
+	if user.OrganizationIdActive == organizationID {
+		return true
+	}
+	for _, id := range user.Organization {
+		if id == organizationID {
+			return true
+		}
+	}
+	return false
+}
+
 func IsValidFile(f *multipart.FileHeader) bool {
 	return f != nil && f.Size > 0
 }
 
+// TotalFileSize cộng dung lượng các file hợp lệ trong request
+func TotalFileSize(files ...*multipart.FileHeader) int64 {
+	var total int64
+	for _, f := range files {
+		if IsValidFile(f) {
+			total += f.Size
+		}
+	}
+	return total
+}
+
 func GetAudioKeyByLanguage(topic *model.Topic, languageID uint) string {
 	for _, lc := range topic.LanguageConfig {
 		if lc.LanguageID == languageID {
@@ -88,6 +138,86 @@ func GetImageKeyByLanguageAndType(topic *model.Topic, languageID uint, imageType
 	return ""
 }
 
+// checksum của file đang gắn với topic, dùng lại khi chỉ cập nhật metadata mà giữ key cũ
+func GetAudioChecksumByLanguage(topic *model.Topic, languageID uint) *uploader.Checksum {
+	for _, lc := range topic.LanguageConfig {
+		if lc.LanguageID == languageID {
+			return lc.Audio.Checksum
+		}
+	}
+	return nil
+}
+
+func GetVideoChecksumByLanguage(topic *model.Topic, languageID uint) *uploader.Checksum {
+	for _, lc := range topic.LanguageConfig {
+		if lc.LanguageID == languageID {
+			return lc.Video.Checksum
+		}
+	}
+	return nil
+}
+
+func GetAudioMediaByLanguage(topic *model.Topic, languageID uint) *mediaprobe.Info {
+	for _, lc := range topic.LanguageConfig {
+		if lc.LanguageID == languageID {
+			return lc.Audio.Media
+		}
+	}
+	return nil
+}
+
+func GetVideoMediaByLanguage(topic *model.Topic, languageID uint) *mediaprobe.Info {
+	for _, lc := range topic.LanguageConfig {
+		if lc.LanguageID == languageID {
+			return lc.Video.Media
+		}
+	}
+	return nil
+}
+
+func GetImageChecksumByLanguageAndType(topic *model.Topic, languageID uint, imageType string) *uploader.Checksum {
+	for _, lc := range topic.LanguageConfig {
+		if lc.LanguageID == languageID {
+			for _, img := range lc.Images {
+				if img.ImageType == imageType {
+					return img.Checksum
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// variant của ảnh đang gắn với topic, giữ lại khi chỉ cập nhật link mà không đổi file
+func GetImageVariantsByLanguageAndType(topic *model.Topic, languageID uint, imageType string) []model.ImageVariant {
+	for _, lc := range topic.LanguageConfig {
+		if lc.LanguageID == languageID {
+			for _, img := range lc.Images {
+				if img.ImageType == imageType {
+					return img.Variants
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func GetImagePlaceholderByLanguageAndType(topic *model.Topic, languageID uint, imageType string) *imagevariant.Placeholder {
+	for _, lc := range topic.LanguageConfig {
+		if lc.LanguageID == languageID {
+			for _, img := range lc.Images {
+				if img.ImageType == imageType {
+					return img.Placeholder
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
 func RemoveDuplicateString(slice []string) []string {
 	keys := make(map[string]bool)
 	list := []string{}
@@ -104,16 +234,6 @@ func BuildVideoUploaderRedisKey(videoUploaderID string) string {
 	return fmt.Sprintf("media_video:upload_status:%s", videoUploaderID)
 }
 
-func BuildObjectKeyS3(folder, originalFilename, baseName string) string {
-	ext := strings.ToLower(filepath.Ext(originalFilename))
-	name := strings.TrimSpace(baseName)
-	if name == "" {
-		name = strings.TrimSuffix(originalFilename, ext)
-	}
-	name = strings.NewReplacer(" ", "-", "/", "-", "\\", "-").Replace(name)
-	return fmt.Sprintf("%s/%d_%s%s", strings.Trim(folder, "/"), time.Now().UnixNano(), name, ext)
-}
-
 func GetVocabularyAudioKeyByLanguage(vocabulary *model.Vocabulary, languageID uint) string {
 	for _, lc := range vocabulary.LanguageConfig {
 		if lc.LanguageID == languageID {
@@ -152,6 +272,84 @@ func GetVocabularyImageKeyByLanguageAndType(vocabulary *model.Vocabulary, langua
 	return ""
 }
 
+func GetVocabularyAudioChecksumByLanguage(vocabulary *model.Vocabulary, languageID uint) *uploader.Checksum {
+	for _, lc := range vocabulary.LanguageConfig {
+		if lc.LanguageID == languageID {
+			return lc.Audio.Checksum
+		}
+	}
+	return nil
+}
+
+func GetVocabularyVideoChecksumByLanguage(vocabulary *model.Vocabulary, languageID uint) *uploader.Checksum {
+	for _, lc := range vocabulary.LanguageConfig {
+		if lc.LanguageID == languageID {
+			return lc.Video.Checksum
+		}
+	}
+	return nil
+}
+
+func GetVocabularyAudioMediaByLanguage(vocabulary *model.Vocabulary, languageID uint) *mediaprobe.Info {
+	for _, lc := range vocabulary.LanguageConfig {
+		if lc.LanguageID == languageID {
+			return lc.Audio.Media
+		}
+	}
+	return nil
+}
+
+func GetVocabularyVideoMediaByLanguage(vocabulary *model.Vocabulary, languageID uint) *mediaprobe.Info {
+	for _, lc := range vocabulary.LanguageConfig {
+		if lc.LanguageID == languageID {
+			return lc.Video.Media
+		}
+	}
+	return nil
+}
+
+func GetVocabularyImageChecksumByLanguageAndType(vocabulary *model.Vocabulary, languageID uint, imageType string) *uploader.Checksum {
+	for _, lc := range vocabulary.LanguageConfig {
+		if lc.LanguageID == languageID {
+			for _, img := range lc.Images {
+				if img.ImageType == imageType {
+					return img.Checksum
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func GetVocabularyImageVariantsByLanguageAndType(vocabulary *model.Vocabulary, languageID uint, imageType string) []model.ImageVariant {
+	for _, lc := range vocabulary.LanguageConfig {
+		if lc.LanguageID == languageID {
+			for _, img := range lc.Images {
+				if img.ImageType == imageType {
+					return img.Variants
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func GetVocabularyImagePlaceholderByLanguageAndType(vocabulary *model.Vocabulary, languageID uint, imageType string) *imagevariant.Placeholder {
+	for _, lc := range vocabulary.LanguageConfig {
+		if lc.LanguageID == languageID {
+			for _, img := range lc.Images {
+				if img.ImageType == imageType {
+					return img.Placeholder
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
 func RemoveDuplicatesString(slice []string) []string {
 	keys := make(map[string]bool)
 	list := []string{}
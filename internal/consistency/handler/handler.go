@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"fmt"
+	"media-service/helper"
+	"media-service/internal/consistency/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ConsistencyHandler struct {
+	svc service.ConsistencyService
+}
+
+func NewConsistencyHandler(svc service.ConsistencyService) *ConsistencyHandler {
+	return &ConsistencyHandler{svc: svc}
+}
+
+// Run kiểm tra tham chiếu tới object không còn tồn tại, action=report|clear|flag (mặc định report)
+func (h *ConsistencyHandler) Run(c *fiber.Ctx) error {
+	action := c.Query("action", "report")
+	if !service.ValidAction(action) {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("invalid action %s", action), helper.ErrInvalidRequest)
+	}
+
+	report, err := h.svc.Run(c.UserContext(), action)
+	if err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "storage consistency check finished", report)
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ActionReport = "report" // chỉ báo cáo
+	ActionClear  = "clear"  // bỏ key hỏng khỏi document
+	ActionFlag   = "flag"   // đánh dấu topic bị ảnh hưởng là incomplete
+)
+
+// MaxReportedBroken giới hạn số tham chiếu hỏng trả về chi tiết, các số đếm vẫn tính đủ
+const MaxReportedBroken = 1000
+
+// Reference là một object key được document tham chiếu
+type Reference struct {
+	Collection string
+	DocumentID primitive.ObjectID
+	TopicID    string // topic bị ảnh hưởng: chính nó với topics, topic_id với vocabularies / topic_resources
+	Bucket     string
+	LanguageID *int64
+	Slot       string // audio, video, image:full_background, pdf, signature...
+	Field      string // đường dẫn của field trong document, ví dụ language_config.0.images.2.image_key
+	Key        string
+}
+
+type BrokenReference struct {
+	Collection string `json:"collection"`
+	DocumentID string `json:"document_id"`
+	LanguageID *int64 `json:"language_id,omitempty"`
+	Slot       string `json:"slot"`
+	Field      string `json:"field"`
+	Key        string `json:"key"`
+	Bucket     string `json:"bucket,omitempty"`
+	Cleared    bool   `json:"cleared,omitempty"`
+}
+
+// Report là kết quả của một lần consistency check
+type Report struct {
+	Action            string            `json:"action"`
+	CheckedReferences int               `json:"checked_references"`
+	CheckedObjects    int               `json:"checked_objects"` // key dùng chung chỉ HEAD một lần
+	BrokenCount       int               `json:"broken_count"`
+	ByCollection      map[string]int    `json:"by_collection"`
+	Broken            []BrokenReference `json:"broken"`
+	ClearedReferences int               `json:"cleared_references"`
+	FlaggedTopics     int64             `json:"flagged_topics"`
+	UnflaggedTopics   int64             `json:"unflagged_topics"`
+	Errors            []string          `json:"errors,omitempty"`
+	StartedAt         time.Time         `json:"started_at"`
+	FinishedAt        time.Time         `json:"finished_at"`
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"media-service/internal/consistency/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// keyFields là các field chứa object key được kiểm tra
+var keyFields = map[string]bool{
+	"image_key":         true,
+	"audio_key":         true,
+	"video_key":         true,
+	"image_preview_key": true,
+	"pdf_key":           true,
+	"signature_key":     true,
+}
+
+type ReferenceRepository interface {
+	// EachReference gọi fn cho từng key được document chưa xoá mềm tham chiếu
+	EachReference(ctx context.Context, fn func(model.Reference) error) error
+	// ClearReference bỏ key khỏi document, false nếu field đã đổi sang giá trị khác từ lúc quét
+	ClearReference(ctx context.Context, ref model.Reference) (bool, error)
+	// SetIncompleteTopics đánh dấu các topic trong topicIDs và bỏ đánh dấu các topic còn lại
+	SetIncompleteTopics(ctx context.Context, topicIDs []string) (flagged, unflagged int64, err error)
+}
+
+type referenceRepository struct {
+	topicCol    *mongo.Collection
+	collections []*mongo.Collection
+}
+
+// NewReferenceRepository nhận topics và các collection khác có chứa key (vocabularies, topic_resources,
+// video_uploaders, pdf_resources).
+func NewReferenceRepository(topicCol *mongo.Collection, collections ...*mongo.Collection) ReferenceRepository {
+	return &referenceRepository{
+		topicCol:    topicCol,
+		collections: append([]*mongo.Collection{topicCol}, collections...),
+	}
+}
+
+func (r *referenceRepository) EachReference(ctx context.Context, fn func(model.Reference) error) error {
+	for _, col := range r.collections {
+		if err := r.eachInCollection(ctx, col, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *referenceRepository) eachInCollection(ctx context.Context, col *mongo.Collection, fn func(model.Reference) error) error {
+	// object của document đã xoá mềm nằm trong thùng rác, không còn ở key gốc
+	cursor, err := col.Find(ctx, bson.M{"deleted_at": bson.M{"$exists": false}}, options.Find().SetBatchSize(500))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		id, ok := doc["_id"].(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		base := model.Reference{Collection: col.Name(), DocumentID: id}
+		base.Bucket, _ = doc["bucket"].(string)
+		if col == r.topicCol {
+			base.TopicID = id.Hex()
+		} else {
+			base.TopicID, _ = doc["topic_id"].(string)
+		}
+
+		var refs []model.Reference
+		walk(doc, "", base, "", &refs)
+		for _, ref := range refs {
+			if err := fn(ref); err != nil {
+				return err
+			}
+		}
+	}
+	return cursor.Err()
+}
+
+// walk duyệt document, ghi lại đường dẫn field cùng language_id / image_type gần nhất
+func walk(v interface{}, path string, ref model.Reference, imageType string, out *[]model.Reference) {
+	switch val := v.(type) {
+	case bson.M:
+		if lang, ok := toInt64(val["language_id"]); ok {
+			ref.LanguageID = &lang
+		}
+		if t, ok := val["image_type"].(string); ok {
+			imageType = t
+		}
+		for field, child := range val {
+			if s, ok := child.(string); ok {
+				if keyFields[field] && s != "" {
+					r := ref
+					r.Field, r.Key, r.Slot = join(path, field), s, slot(field, imageType)
+					*out = append(*out, r)
+				}
+				continue
+			}
+			walk(child, join(path, field), ref, imageType, out)
+		}
+	case bson.D:
+		walk(val.Map(), path, ref, imageType, out)
+	case bson.A:
+		for i, child := range val {
+			walk(child, join(path, strconv.Itoa(i)), ref, imageType, out)
+		}
+	}
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func slot(field, imageType string) string {
+	name := strings.TrimSuffix(field, "_key")
+	if field == "image_key" && imageType != "" {
+		return name + ":" + imageType
+	}
+	return name
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+func (r *referenceRepository) ClearReference(ctx context.Context, ref model.Reference) (bool, error) {
+	col := r.collection(ref.Collection)
+	if col == nil {
+		return false, nil
+	}
+	// chỉ xoá khi field vẫn giữ đúng key đã kiểm tra (vị trí trong mảng có thể đã đổi)
+	res, err := col.UpdateOne(ctx,
+		bson.M{"_id": ref.DocumentID, ref.Field: ref.Key},
+		bson.M{"$unset": bson.M{ref.Field: ""}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+func (r *referenceRepository) collection(name string) *mongo.Collection {
+	for _, col := range r.collections {
+		if col.Name() == name {
+			return col
+		}
+	}
+	return nil
+}
+
+func (r *referenceRepository) SetIncompleteTopics(ctx context.Context, topicIDs []string) (int64, int64, error) {
+	ids := make([]primitive.ObjectID, 0, len(topicIDs))
+	for _, id := range topicIDs {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			ids = append(ids, oid)
+		}
+	}
+
+	var flagged int64
+	if len(ids) > 0 {
+		res, err := r.topicCol.UpdateMany(ctx,
+			bson.M{"_id": bson.M{"$in": ids}, "incomplete": bson.M{"$ne": true}},
+			bson.M{"$set": bson.M{"incomplete": true}},
+		)
+		if err != nil {
+			return 0, 0, err
+		}
+		flagged = res.ModifiedCount
+	}
+
+	res, err := r.topicCol.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$nin": ids}, "incomplete": true},
+		bson.M{"$unset": bson.M{"incomplete": ""}},
+	)
+	if err != nil {
+		return flagged, 0, err
+	}
+	return flagged, res.ModifiedCount, nil
+}
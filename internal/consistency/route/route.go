@@ -0,0 +1,16 @@
+package route
+
+import (
+	"media-service/internal/consistency/handler"
+	"media-service/internal/gateway"
+	"media-service/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterConsistencyRoutes(app *fiber.App, h *handler.ConsistencyHandler, userGw gateway.UserGateway) {
+	admin := app.Group("/api/v2/admin/storage")
+	admin.Use(middleware.Secured(userGw))
+
+	admin.Post("/consistency", middleware.RequireAdmin(), h.Run)
+}
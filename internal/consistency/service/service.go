@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"media-service/internal/consistency/model"
+	"media-service/internal/consistency/repository"
+	"media-service/internal/s3"
+	"media-service/logger"
+	"media-service/pkg/config"
+	"media-service/pkg/uploader"
+)
+
+const (
+	defaultIntervalHours = 24
+	defaultConcurrency   = 8
+)
+
+type ConsistencyService interface {
+	// Run kiểm tra mọi key được tham chiếu còn tồn tại trong storage, action quyết định cách xử lý key hỏng
+	Run(ctx context.Context, action string) (*model.Report, error)
+	// Start chạy kiểm tra định kỳ với action trong config cho tới khi ctx bị huỷ
+	Start(ctx context.Context)
+}
+
+type consistencyService struct {
+	repo        repository.ReferenceRepository
+	s3Service   s3.Service
+	interval    time.Duration
+	action      string
+	concurrency int
+
+	running sync.Mutex
+}
+
+func NewConsistencyService(repo repository.ReferenceRepository, s3Service s3.Service) ConsistencyService {
+	cfg := config.AppConfig.Consistency
+
+	intervalHours := cfg.IntervalHours
+	if intervalHours <= 0 {
+		intervalHours = defaultIntervalHours
+	}
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	action := cfg.Action
+	if !ValidAction(action) {
+		action = model.ActionReport
+	}
+
+	return &consistencyService{
+		repo:        repo,
+		s3Service:   s3Service,
+		interval:    time.Duration(intervalHours) * time.Hour,
+		action:      action,
+		concurrency: concurrency,
+	}
+}
+
+// ValidAction báo action có được hỗ trợ không
+func ValidAction(action string) bool {
+	switch action {
+	case model.ActionReport, model.ActionClear, model.ActionFlag:
+		return true
+	}
+	return false
+}
+
+func (s *consistencyService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := s.Run(ctx, s.action)
+				if err != nil {
+					logger.WriteLogEx("error", "storage consistency check failed", err)
+					continue
+				}
+				logger.WriteLogData("info", report)
+			}
+		}
+	}()
+}
+
+// object là một cặp bucket + key, key dùng chung giữa nhiều document chỉ HEAD một lần
+type object struct {
+	bucket string
+	key    string
+}
+
+func (s *consistencyService) Run(ctx context.Context, action string) (*model.Report, error) {
+	if !ValidAction(action) {
+		return nil, fmt.Errorf("invalid action %s", action)
+	}
+	if !s.running.TryLock() {
+		return nil, fmt.Errorf("storage consistency check is already running")
+	}
+	defer s.running.Unlock()
+
+	report := &model.Report{
+		Action:       action,
+		ByCollection: map[string]int{},
+		Broken:       []model.BrokenReference{},
+		StartedAt:    time.Now(),
+	}
+
+	refsByObject := map[object][]model.Reference{}
+	err := s.repo.EachReference(ctx, func(ref model.Reference) error {
+		report.CheckedReferences++
+		obj := object{bucket: ref.Bucket, key: ref.Key}
+		refsByObject[obj] = append(refsByObject[obj], ref)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.CheckedObjects = len(refsByObject)
+
+	missing, headErrors := s.findMissing(ctx, refsByObject)
+	report.Errors = append(report.Errors, headErrors...)
+
+	brokenTopics := map[string]struct{}{}
+	for obj := range missing {
+		for _, ref := range refsByObject[obj] {
+			report.BrokenCount++
+			report.ByCollection[ref.Collection]++
+			if ref.TopicID != "" {
+				brokenTopics[ref.TopicID] = struct{}{}
+			}
+
+			broken := model.BrokenReference{
+				Collection: ref.Collection,
+				DocumentID: ref.DocumentID.Hex(),
+				LanguageID: ref.LanguageID,
+				Slot:       ref.Slot,
+				Field:      ref.Field,
+				Key:        ref.Key,
+				Bucket:     ref.Bucket,
+			}
+			if action == model.ActionClear {
+				cleared, err := s.repo.ClearReference(ctx, ref)
+				if err != nil {
+					report.Errors = append(report.Errors, fmt.Sprintf("clear %s %s %s: %v", ref.Collection, broken.DocumentID, ref.Field, err))
+				} else if cleared {
+					broken.Cleared = true
+					report.ClearedReferences++
+				}
+			}
+			if len(report.Broken) < model.MaxReportedBroken {
+				report.Broken = append(report.Broken, broken)
+			}
+		}
+	}
+
+	if action == model.ActionFlag {
+		if len(headErrors) > 0 {
+			// kết quả không đầy đủ: không bỏ cờ của topic nào để tránh đánh dấu sai là đã lành
+			report.Errors = append(report.Errors, "flag skipped: some objects could not be checked")
+		} else {
+			topicIDs := make([]string, 0, len(brokenTopics))
+			for id := range brokenTopics {
+				topicIDs = append(topicIDs, id)
+			}
+			flagged, unflagged, err := s.repo.SetIncompleteTopics(ctx, topicIDs)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("flag topics: %v", err))
+			}
+			report.FlaggedTopics, report.UnflaggedTopics = flagged, unflagged
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// findMissing HEAD song song các object, lỗi khác not found không được tính là hỏng
+func (s *consistencyService) findMissing(ctx context.Context, objects map[object][]model.Reference) (map[object]struct{}, []string) {
+	var (
+		mu      sync.Mutex
+		missing = map[object]struct{}{}
+		errs    []string
+		wg      sync.WaitGroup
+	)
+
+	jobs := make(chan object)
+	for i := 0; i < s.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for obj := range jobs {
+				_, err := s.s3Service.For(obj.bucket).Head(ctx, obj.key)
+				if err == nil {
+					continue
+				}
+				mu.Lock()
+				if errors.Is(err, uploader.ErrObjectNotFound) {
+					missing[obj] = struct{}{}
+				} else {
+					errs = append(errs, fmt.Sprintf("head %s: %v", obj.key, err))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for obj := range objects {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- obj
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	return missing, errs
+}
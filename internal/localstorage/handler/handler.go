@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"media-service/helper"
+	"net/http"
+	"net/url"
+	"os"
+
+	"media-service/pkg/uploader"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocalFileStore is the subset of the local upload provider needed to serve files.
+type LocalFileStore interface {
+	VerifySignature(method, key, expires, signature string) error
+	FilePath(key string) (string, error)
+	SaveFileUploadedReader(ctx context.Context, r io.Reader, key string, contentType string, mode uploader.UploadMode, checksum *uploader.Checksum) (*string, error)
+}
+
+type LocalFileHandler struct {
+	store LocalFileStore
+}
+
+func NewLocalFileHandler(store LocalFileStore) *LocalFileHandler {
+	return &LocalFileHandler{store: store}
+}
+
+func (h *LocalFileHandler) Serve(c *fiber.Ctx) error {
+	key, err := url.PathUnescape(c.Params("*"))
+	if err != nil || key == "" {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("key is required"), helper.ErrInvalidRequest)
+	}
+
+	// vùng public được đọc không cần chữ ký, giống CDN phía trước bucket
+	if !uploader.IsPublicKey(key) {
+		if err := h.store.VerifySignature(http.MethodGet, key, c.Query("expires"), c.Query("signature")); err != nil {
+			return helper.SendError(c, http.StatusForbidden, err, helper.ErrInvalidRequest)
+		}
+	}
+
+	filePath, err := h.store.FilePath(key)
+	if err != nil {
+		return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return helper.SendError(c, http.StatusNotFound, fmt.Errorf("file not found"), helper.ErrNotFound)
+		}
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+	}
+
+	return c.SendFile(filePath)
+}
+
+// Upload accepts the raw body of a presigned PUT issued by the local provider,
+// mirroring what a client would send straight to the bucket.
+func (h *LocalFileHandler) Upload(c *fiber.Ctx) error {
+	key, err := url.PathUnescape(c.Params("*"))
+	if err != nil || key == "" {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("key is required"), helper.ErrInvalidRequest)
+	}
+
+	if err := h.store.VerifySignature(http.MethodPut, key, c.Query("expires"), c.Query("signature")); err != nil {
+		return helper.SendError(c, http.StatusForbidden, err, helper.ErrInvalidRequest)
+	}
+
+	// client gửi checksum như khi PUT thẳng lên S3
+	checksum, err := uploader.ChecksumFromHeader(func(name string) string { return c.Get(name) })
+	if err != nil {
+		return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+	}
+
+	if _, err := h.store.SaveFileUploadedReader(c.Context(), bytes.NewReader(c.Body()), key, c.Get(fiber.HeaderContentType), uploader.UploadPrivate, checksum); err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
@@ -0,0 +1,18 @@
+package route
+
+import (
+	"media-service/internal/localstorage/handler"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocalFilesPath is where files stored by the local upload provider are served from.
+// storage.local.base_url should point at this path.
+const LocalFilesPath = "/api/v2/files"
+
+func RegisterLocalFileRoutes(app *fiber.App, h *handler.LocalFileHandler) {
+	files := app.Group(LocalFilesPath)
+
+	files.Get("/*", h.Serve)
+	files.Put("/*", h.Upload)
+}
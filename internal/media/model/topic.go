@@ -3,30 +3,58 @@ package model
 import (
 	"time"
 
+	"media-service/pkg/imagevariant"
+	"media-service/pkg/mediaprobe"
+	"media-service/pkg/uploader"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type TopicImageConfig struct {
-	ImageType   string `json:"image_type" bson:"image_type"`
-	ImageKey    string `json:"image_key" bson:"image_key"`
-	LinkUrl     string `json:"link_url" bson:"link_url"`
-	UploadedUrl string `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	ImageType            string                    `json:"image_type" bson:"image_type"`
+	ImageKey             string                    `json:"image_key" bson:"image_key"`
+	LinkUrl              string                    `json:"link_url" bson:"link_url"`
+	UploadedUrl          string                    `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	UploadedUrlExpiresAt *time.Time                `json:"uploaded_url_expires_at,omitempty" bson:"-"` // nil = url không hết hạn
+	Checksum             *uploader.Checksum        `json:"checksum,omitempty" bson:"checksum,omitempty"`
+	Variants             []ImageVariant            `json:"variants,omitempty" bson:"variants,omitempty"`
+	Placeholder          *imagevariant.Placeholder `json:"placeholder,omitempty" bson:"placeholder,omitempty"`
+}
+
+// ImageVariant là một bản resize của ảnh, lưu cạnh key gốc (xem imagevariant.Key)
+type ImageVariant struct {
+	Name                 string     `json:"name" bson:"name"`
+	ImageKey             string     `json:"image_key" bson:"image_key"`
+	Width                int        `json:"width" bson:"width"`
+	Height               int        `json:"height" bson:"height"`
+	UploadedUrl          string     `json:"uploaded_url,omitempty" bson:"-"`
+	UploadedUrlExpiresAt *time.Time `json:"uploaded_url_expires_at,omitempty" bson:"-"`
 }
 
 type TopicVideoConfig struct {
-	VideoKey    string `json:"video_key" bson:"video_key"`
-	LinkUrl     string `json:"link_url" bson:"link_url"`
-	StartTime   string `json:"start_time" bson:"start_time"`
-	EndTime     string `json:"end_time" bson:"end_time"`
-	UploadedUrl string `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	VideoKey             string             `json:"video_key" bson:"video_key"`
+	LinkUrl              string             `json:"link_url" bson:"link_url"`
+	StartTime            string             `json:"start_time" bson:"start_time"`
+	EndTime              string             `json:"end_time" bson:"end_time"`
+	StartMs              *int64             `json:"start_ms,omitempty" bson:"start_ms,omitempty"`
+	EndMs                *int64             `json:"end_ms,omitempty" bson:"end_ms,omitempty"`
+	UploadedUrl          string             `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	UploadedUrlExpiresAt *time.Time         `json:"uploaded_url_expires_at,omitempty" bson:"-"`
+	Checksum             *uploader.Checksum `json:"checksum,omitempty" bson:"checksum,omitempty"`
+	Media                *mediaprobe.Info   `json:"media,omitempty" bson:"media,omitempty"`
 }
 
 type TopicAudioConfig struct {
-	AudioKey    string `json:"audio_key" bson:"audio_key"`
-	LinkUrl     string `json:"link_url" bson:"link_url"`
-	StartTime   string `json:"start_time" bson:"start_time"`
-	EndTime     string `json:"end_time" bson:"end_time"`
-	UploadedUrl string `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	AudioKey             string             `json:"audio_key" bson:"audio_key"`
+	LinkUrl              string             `json:"link_url" bson:"link_url"`
+	StartTime            string             `json:"start_time" bson:"start_time"`
+	EndTime              string             `json:"end_time" bson:"end_time"`
+	StartMs              *int64             `json:"start_ms,omitempty" bson:"start_ms,omitempty"`
+	EndMs                *int64             `json:"end_ms,omitempty" bson:"end_ms,omitempty"`
+	UploadedUrl          string             `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	UploadedUrlExpiresAt *time.Time         `json:"uploaded_url_expires_at,omitempty" bson:"-"`
+	Checksum             *uploader.Checksum `json:"checksum,omitempty" bson:"checksum,omitempty"`
+	Media                *mediaprobe.Info   `json:"media,omitempty" bson:"media,omitempty"`
 }
 
 type TopicLanguageConfig struct {
@@ -47,6 +75,10 @@ type Topic struct {
 	OrganizationID string                `json:"organization_id" bson:"organization_id"`
 	IsPublished    bool                  `json:"is_published" bson:"is_published"`
 	LanguageConfig []TopicLanguageConfig `json:"language_config" bson:"language_config"`
-	CreatedAt      time.Time             `json:"created_at" bson:"created_at"`
-	UpdatedAt      time.Time             `json:"updated_at" bson:"updated_at"`
+	// Incomplete được đặt bởi consistency check khi topic (hoặc vocabulary / ảnh của nó) tham chiếu file đã mất
+	Incomplete bool `json:"incomplete,omitempty" bson:"incomplete,omitempty"`
+	// bucket chứa mọi file của topic (xem s3.Service.Bucket), rỗng = bucket mặc định
+	Bucket    string    `json:"bucket,omitempty" bson:"bucket,omitempty"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
 }
@@ -3,6 +3,8 @@ package model
 import (
 	"time"
 
+	"media-service/pkg/imagevariant"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -13,7 +15,16 @@ type TopicResource struct {
 	IsOutput  bool               `json:"is_output" bson:"is_output"`
 	FileName  string             `json:"file_name" bson:"file_name"`
 	ImageKey  string             `json:"image_key" bson:"image_key"`
-	CreatedBy string             `json:"created_by" bson:"created_by"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	Bucket    string             `json:"bucket,omitempty" bson:"bucket,omitempty"`
+	// blurhash + màu chủ đạo của ảnh, tính lúc upload
+	Placeholder *imagevariant.Placeholder `json:"placeholder,omitempty" bson:"placeholder,omitempty"`
+	CreatedBy   string                    `json:"created_by" bson:"created_by"`
+	CreatedAt   time.Time                 `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at" bson:"updated_at"`
+	// storage class của ảnh do internal/tiering ghi, rỗng = STANDARD
+	StorageClass string     `json:"storage_class,omitempty" bson:"storage_class,omitempty"`
+	TieredAt     *time.Time `json:"tiered_at,omitempty" bson:"tiered_at,omitempty"`
+	// xoá mềm
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 }
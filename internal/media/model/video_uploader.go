@@ -3,6 +3,9 @@ package model
 import (
 	"time"
 
+	"media-service/pkg/imagevariant"
+	"media-service/pkg/mediaprobe"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -13,17 +16,27 @@ type VideoUploader struct {
 	Title          string                        `bson:"title" json:"title"`
 	WikiCode       string                        `bson:"wiki_code" json:"wiki_code"`
 	LanguageConfig []VideoUploaderLanguageConfig `bson:"language_config" json:"language_config"`
+	Bucket         string                        `bson:"bucket,omitempty" json:"bucket,omitempty"`
 	CreatedAt      time.Time                     `bson:"created_at" json:"created_at"`
 	UpdatedAt      time.Time                     `bson:"updated_at" json:"updated_at"`
+	// xoá mềm, xem internal/trash
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
 }
 
 type VideoUploaderLanguageConfig struct {
 	ID                    primitive.ObjectID `bson:"_id" json:"id"`
 	LanguageID            uint               `json:"language_id" bson:"language_id"`
 	VideoKey              string             `json:"video_key" bson:"video_key"`
-	VideoPublicUrl        string             `json:"video_public_url" bson:"video_public_url"`
+	VideoPublicUrl        string             `json:"video_public_url" bson:"-"` // tính từ key mỗi lần đọc, không lưu
+	VideoUrlExpiresAt     *time.Time         `json:"video_url_expires_at,omitempty" bson:"-"`
 	ImagePreviewKey       string             `json:"image_preview_key" bson:"image_preview_key"`
-	ImagePreviewPublicUrl string             `json:"image_preview_public_url" bson:"image_preview_public_url"`
-	Transcript            string             `json:"transcript" bson:"transcript"`
-	Note                  string             `json:"note" bson:"note"`
+	ImagePreviewPublicUrl string             `json:"image_preview_public_url" bson:"-"`
+	ImagePreviewExpiresAt *time.Time         `json:"image_preview_url_expires_at,omitempty" bson:"-"`
+	// hiển thị trong lúc ảnh preview đang tải
+	ImagePreviewPlaceholder *imagevariant.Placeholder `json:"image_preview_placeholder,omitempty" bson:"image_preview_placeholder,omitempty"`
+	Transcript              string                    `json:"transcript" bson:"transcript"`
+	Note                    string                    `json:"note" bson:"note"`
+	// duration / kích thước / codec đọc lúc upload, nil với video upload trước khi có probe
+	VideoMedia *mediaprobe.Info `json:"video_media,omitempty" bson:"video_media,omitempty"`
 }
@@ -3,30 +3,48 @@ package model
 import (
 	"time"
 
+	"media-service/pkg/imagevariant"
+	"media-service/pkg/mediaprobe"
+	"media-service/pkg/uploader"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type VocabularyImageConfig struct {
-	ImageType   string `json:"image_type" bson:"image_type"`
-	ImageKey    string `json:"image_key" bson:"image_key"`
-	LinkUrl     string `json:"link_url" bson:"link_url"`
-	UploadedUrl string `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	ImageType            string                    `json:"image_type" bson:"image_type"`
+	ImageKey             string                    `json:"image_key" bson:"image_key"`
+	LinkUrl              string                    `json:"link_url" bson:"link_url"`
+	UploadedUrl          string                    `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	UploadedUrlExpiresAt *time.Time                `json:"uploaded_url_expires_at,omitempty" bson:"-"`
+	Checksum             *uploader.Checksum        `json:"checksum,omitempty" bson:"checksum,omitempty"`
+	Variants             []ImageVariant            `json:"variants,omitempty" bson:"variants,omitempty"`
+	Placeholder          *imagevariant.Placeholder `json:"placeholder,omitempty" bson:"placeholder,omitempty"`
 }
 
 type VocabularyVideoConfig struct {
-	VideoKey    string `json:"video_key" bson:"video_key"`
-	LinkUrl     string `json:"link_url" bson:"link_url"`
-	StartTime   string `json:"start_time" bson:"start_time"`
-	EndTime     string `json:"end_time" bson:"end_time"`
-	UploadedUrl string `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	VideoKey             string             `json:"video_key" bson:"video_key"`
+	LinkUrl              string             `json:"link_url" bson:"link_url"`
+	StartTime            string             `json:"start_time" bson:"start_time"`
+	EndTime              string             `json:"end_time" bson:"end_time"`
+	StartMs              *int64             `json:"start_ms,omitempty" bson:"start_ms,omitempty"`
+	EndMs                *int64             `json:"end_ms,omitempty" bson:"end_ms,omitempty"`
+	UploadedUrl          string             `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	UploadedUrlExpiresAt *time.Time         `json:"uploaded_url_expires_at,omitempty" bson:"-"`
+	Checksum             *uploader.Checksum `json:"checksum,omitempty" bson:"checksum,omitempty"`
+	Media                *mediaprobe.Info   `json:"media,omitempty" bson:"media,omitempty"`
 }
 
 type VocabularyAudioConfig struct {
-	AudioKey    string `json:"audio_key" bson:"audio_key"`
-	LinkUrl     string `json:"link_url" bson:"link_url"`
-	StartTime   string `json:"start_time" bson:"start_time"`
-	EndTime     string `json:"end_time" bson:"end_time"`
-	UploadedUrl string `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	AudioKey             string             `json:"audio_key" bson:"audio_key"`
+	LinkUrl              string             `json:"link_url" bson:"link_url"`
+	StartTime            string             `json:"start_time" bson:"start_time"`
+	EndTime              string             `json:"end_time" bson:"end_time"`
+	StartMs              *int64             `json:"start_ms,omitempty" bson:"start_ms,omitempty"`
+	EndMs                *int64             `json:"end_ms,omitempty" bson:"end_ms,omitempty"`
+	UploadedUrl          string             `json:"uploaded_url" bson:"uploaded_url,omitempty"`
+	UploadedUrlExpiresAt *time.Time         `json:"uploaded_url_expires_at,omitempty" bson:"-"`
+	Checksum             *uploader.Checksum `json:"checksum,omitempty" bson:"checksum,omitempty"`
+	Media                *mediaprobe.Info   `json:"media,omitempty" bson:"media,omitempty"`
 }
 
 type VocabularyLanguageConfig struct {
@@ -45,6 +63,7 @@ type Vocabulary struct {
 	TopicID        string                     `json:"topic_id" bson:"topic_id"`
 	IsPublished    bool                       `json:"is_published" bson:"is_published"`
 	LanguageConfig []VocabularyLanguageConfig `json:"language_config" bson:"language_config"`
+	Bucket         string                     `json:"bucket,omitempty" bson:"bucket,omitempty"`
 	CreatedAt      time.Time                  `json:"created_at" bson:"created_at"`
 	UpdatedAt      time.Time                  `json:"updated_at" bson:"updated_at"`
 }
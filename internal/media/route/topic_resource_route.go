@@ -15,6 +15,7 @@ func RegisterTopicResourceRoutes(app *fiber.App, h *handler.TopicResourceHandler
 	topicResourceGroup.Post("", h.CreateTopicResource)
 	topicResourceGroup.Get("", h.GetTopicResources)
 	topicResourceGroup.Get("/:topic_resource_id", h.GetTopicResource)
+	topicResourceGroup.Get("/:topic_resource_id/content", h.GetTopicResourceContent)
 	topicResourceGroup.Put("/:topic_resource_id", h.UpdateTopicResource)
 	topicResourceGroup.Delete("/:topic_resource_id", h.DeleteTopicResource)
 
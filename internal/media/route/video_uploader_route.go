@@ -21,6 +21,7 @@ func RegisterVideoUploaderRoutes(app *fiber.App, h *handler.VideoUploaderHandler
 	videoUploaderAdmin.Delete("/:video_uploader_id", h.DeleteVideoUploader)
 	videoUploaderAdmin.Get("/:video_uploader_id", h.GetVideo4Web)
 	videoUploaderAdmin.Get("/wiki_code/:wiki_code", h.GetVideosByWikiCode4Web)
+	videoUploaderAdmin.Post("/migrations/public_urls", middleware.RequireAdmin(), h.MigratePublicURLs)
 
 	// gateway routes
 	gatewayGroup := app.Group("/api/v1/gateway")
@@ -1,6 +1,10 @@
 package request
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+
+	"media-service/pkg/uploader"
+)
 
 type UploadTopicRequest struct {
 	TopicID     string `form:"topic_id"`
@@ -13,6 +17,7 @@ type UploadTopicRequest struct {
 
 	// audio
 	AudioFile      *multipart.FileHeader `form:"audio_file"`
+	AudioUploadID  string                `form:"audio_upload_id"` // completed resumable upload session, used instead of audio_file
 	AudioLinkUrl   string                `form:"audio_link_url"`
 	AudioStart     string                `form:"audio_start_time"`
 	AudioEnd       string                `form:"audio_end_time"`
@@ -20,6 +25,7 @@ type UploadTopicRequest struct {
 
 	// video
 	VideoFile      *multipart.FileHeader `form:"video_file"`
+	VideoUploadID  string                `form:"video_upload_id"` // completed resumable upload session, used instead of video_file
 	VideoLinkUrl   string                `form:"video_link_url"`
 	VideoStart     string                `form:"video_start_time"`
 	VideoEnd       string                `form:"video_end_time"`
@@ -61,4 +67,7 @@ type UploadTopicRequest struct {
 	OrderFile      *multipart.FileHeader `form:"order_file"`
 	OrderLink      string                `form:"order_link_url"`
 	IsDeletedOrder bool                  `form:"is_deleted_order"`
+
+	// checksum client gửi kèm file, key là tên form field của file (vd "audio_file")
+	Checksums map[string]*uploader.Checksum `form:"-"`
 }
@@ -7,6 +7,7 @@ type UploadVideoUploaderRequest struct {
 	Title                 string                `form:"title"`
 	WikiCode              string                `form:"wiki_code"`
 	VideoFile             *multipart.FileHeader `form:"video_file"`
+	VideoUploadID         string                `form:"video_upload_id"` // completed resumable upload session, used instead of video_file
 	ImagePreviewFile      *multipart.FileHeader `form:"image_preview_file"`
 	IsVisible             bool                  `form:"is_visible"`
 	LanguageID            uint                  `form:"language_id"`
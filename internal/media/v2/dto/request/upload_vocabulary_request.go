@@ -1,6 +1,10 @@
 package request
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+
+	"media-service/pkg/uploader"
+)
 
 type UploadVocabularyRequest struct {
 	VocabularyID string `form:"vocabulary_id"`
@@ -14,6 +18,7 @@ type UploadVocabularyRequest struct {
 
 	// audio
 	AudioFile      *multipart.FileHeader `form:"audio_file"`
+	AudioUploadID  string                `form:"audio_upload_id"` // completed resumable upload session, used instead of audio_file
 	AudioLinkUrl   string                `form:"audio_link_url"`
 	AudioStart     string                `form:"audio_start_time"`
 	AudioEnd       string                `form:"audio_end_time"`
@@ -21,6 +26,7 @@ type UploadVocabularyRequest struct {
 
 	// video
 	VideoFile      *multipart.FileHeader `form:"video_file"`
+	VideoUploadID  string                `form:"video_upload_id"` // completed resumable upload session, used instead of video_file
 	VideoLinkUrl   string                `form:"video_link_url"`
 	VideoStart     string                `form:"video_start_time"`
 	VideoEnd       string                `form:"video_end_time"`
@@ -62,4 +68,7 @@ type UploadVocabularyRequest struct {
 	OrderFile      *multipart.FileHeader `form:"order_file"`
 	OrderLink      string                `form:"order_link_url"`
 	IsDeletedOrder bool                  `form:"is_deleted_order"`
+
+	// checksum client gửi kèm file, key là tên form field của file (vd "audio_file")
+	Checksums map[string]*uploader.Checksum `form:"-"`
 }
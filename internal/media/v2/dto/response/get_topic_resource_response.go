@@ -2,35 +2,40 @@ package response
 
 import (
 	"media-service/internal/gateway/dto/response"
+	"media-service/pkg/imagevariant"
 	"time"
 )
 
 type GetTopicResourceResponse struct {
-	ID        string                    `json:"id"`
-	Topic     *TopicResponse2Assign4Web `json:"topic"`
-	Student   *response.StudentResponse `json:"student"`
-	FileName  string                    `json:"file_name"`
-	ImageUrl  string                    `json:"image_url"`
-	CreatedBy *response.TeacherResponse `json:"created_by"`
-	CreatedAt time.Time                 `json:"created_at"`
-	UpdatedAt time.Time                 `json:"updated_at"`
+	ID          string                    `json:"id"`
+	Topic       *TopicResponse2Assign4Web `json:"topic"`
+	Student     *response.StudentResponse `json:"student"`
+	FileName    string                    `json:"file_name"`
+	ImageUrl    string                    `json:"image_url"`
+	Placeholder *imagevariant.Placeholder `json:"placeholder,omitempty"` // hiển thị trong lúc tải image_url
+	CreatedBy   *response.TeacherResponse `json:"created_by"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
 }
 
 type TopicResourceResponse struct {
-	ID        string    `json:"id"`
-	FileName  string    `json:"file_name"`
-	ImageUrl  string    `json:"image_url"`
-	CreatedAt time.Time `json:"created_at"`
-	PicID     string    `json:"pic_id"`
+	ID          string                    `json:"id"`
+	FileName    string                    `json:"file_name"`
+	ImageUrl    string                    `json:"image_url"`
+	Placeholder *imagevariant.Placeholder `json:"placeholder,omitempty"`
+	Restoring   bool                      `json:"restoring,omitempty"` // ảnh đang được khôi phục từ lưu trữ, image_url rỗng
+	CreatedAt   time.Time                 `json:"created_at"`
+	PicID       string                    `json:"pic_id"`
 }
 
 type GetTopicResourcesResponse4Web struct {
-	ID        string                    `json:"id"`
-	FileName  string                    `json:"file_name"`
-	ImageUrl  string                    `json:"image_url"`
-	CreatedAt time.Time                 `json:"created_at"`
-	PicID     string                    `json:"pic_id"`
-	Topic     *TopicResponse2Assign4Web `json:"topic"`
+	ID          string                    `json:"id"`
+	FileName    string                    `json:"file_name"`
+	ImageUrl    string                    `json:"image_url"`
+	Placeholder *imagevariant.Placeholder `json:"placeholder,omitempty"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	PicID       string                    `json:"pic_id"`
+	Topic       *TopicResponse2Assign4Web `json:"topic"`
 }
 
 type GetTopicResourcesResponse4WebV2 struct {
@@ -39,23 +44,25 @@ type GetTopicResourcesResponse4WebV2 struct {
 }
 
 type TopicResourceResponseV2 struct {
-	ID        string                    `json:"id"`
-	ImageKey  string                    `json:"image_key"`
-	FileName  string                    `json:"file_name"`
-	ImageUrl  string                    `json:"image_url"`
-	CreatedAt time.Time                 `json:"created_at"`
-	PicID     string                    `json:"pic_id"`
-	TopicID   string                    `json:"topic_id"`
-	Topic     *TopicResponse2Assign4Web `json:"topic"`
+	ID          string                    `json:"id"`
+	FileName    string                    `json:"file_name"`
+	ImageUrl    string                    `json:"image_url"` // endpoint /content của resource, cần token
+	Placeholder *imagevariant.Placeholder `json:"placeholder,omitempty"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	PicID       string                    `json:"pic_id"`
+	TopicID     string                    `json:"topic_id"`
+	Topic       *TopicResponse2Assign4Web `json:"topic"`
 }
 
 type GetTopicResourcesResponse4App struct {
-	ID        string               `json:"id"`
-	FileName  string               `json:"file_name"`
-	ImageUrl  string               `json:"image_url"`
-	CreatedAt time.Time            `json:"created_at"`
-	PicID     string               `json:"pic_id"`
-	Topic     GetTopicResponse4App `json:"topic"`
+	ID                string                    `json:"id"`
+	FileName          string                    `json:"file_name"`
+	ImageUrl          string                    `json:"image_url"`
+	ImageUrlExpiresAt *time.Time                `json:"image_url_expires_at,omitempty"`
+	Placeholder       *imagevariant.Placeholder `json:"placeholder,omitempty"`
+	CreatedAt         time.Time                 `json:"created_at"`
+	PicID             string                    `json:"pic_id"`
+	Topic             GetTopicResponse4App      `json:"topic"`
 }
 
 type GetTopicResourcesResponseByStudent4Web struct {
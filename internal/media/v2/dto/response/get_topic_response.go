@@ -1,10 +1,20 @@
 package response
 
+import (
+	"time"
+
+	"media-service/pkg/imagevariant"
+	"media-service/pkg/mediaprobe"
+)
+
 type TopicResponse4Web struct {
-	ID           string                 `json:"id"`
-	IsPublished  bool                   `json:"is_published"`
-	MainImageUrl string                 `json:"main_image_url"`
-	MessageLangs []MessageLanguageEntry `json:"message_languages"`
+	ID                    string                 `json:"id"`
+	IsPublished           bool                   `json:"is_published"`
+	MainImageUrl          string                 `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time             `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string      `json:"main_image_variants,omitempty"` // name -> url, xem image.variants
+	Incomplete            bool                   `json:"incomplete,omitempty"`          // có file bị mất, xem consistency check
+	MessageLangs          []MessageLanguageEntry `json:"message_languages"`
 }
 
 type MessageLanguageEntry struct {
@@ -23,66 +33,94 @@ type LanguageContents struct {
 }
 
 type MediaContent struct {
-	UploadedURL string `json:"uploaded_url"`
-	LinkURL     string `json:"link_url"`
-	StartTime   string `json:"start_time"`
-	EndTime     string `json:"end_time"`
+	UploadedURL string           `json:"uploaded_url"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"` // hạn của uploaded_url, nil = không hết hạn
+	LinkURL     string           `json:"link_url"`
+	StartTime   string           `json:"start_time"` // dạng chuẩn hh:mm:ss.mmm khi parse được, không thì giữ chuỗi cũ
+	EndTime     string           `json:"end_time"`
+	StartMs     *int64           `json:"start_ms,omitempty"`
+	EndMs       *int64           `json:"end_ms,omitempty"`
+	Media       *mediaprobe.Info `json:"media,omitempty"` // duration / kích thước đọc lúc upload, player dùng trước khi tải file
 }
 
 type ImgEntry struct {
-	UploadedURL *string `json:"uploaded_url"`
-	LinkURL     string  `json:"link_url"`
+	UploadedURL *string           `json:"uploaded_url"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`
+	LinkURL     string            `json:"link_url"`
+	Variants    map[string]string `json:"variants,omitempty"` // thumbnail / medium / large -> url
 }
 
 //// 4 App
 
 type GetTopic4StudentResponse4App struct {
-	ID           string                       `json:"id"`
-	IsPublished  bool                         `json:"is_published"`
-	Title        string                       `json:"title"`
-	MainImageUrl string                       `json:"main_image_url"`
-	Vocabularies []*GetVocabularyResponse4App `json:"vocabularies"`
+	ID                    string                       `json:"id"`
+	IsPublished           bool                         `json:"is_published"`
+	Title                 string                       `json:"title"`
+	MainImageUrl          string                       `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time                   `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string            `json:"main_image_variants,omitempty"`
+	MainImagePlaceholder  *imagevariant.Placeholder    `json:"main_image_placeholder,omitempty"` // blurhash + màu chủ đạo, app hiển thị trong lúc tải ảnh
+	Vocabularies          []*GetVocabularyResponse4App `json:"vocabularies"`
 }
 
 type GetTopicResponse4App struct {
-	ID           string                       `json:"id"`
-	IsPublished  bool                         `json:"is_published"`
-	Title        string                       `json:"title"`
-	MainImageUrl string                       `json:"main_image_url"`
-	Vocabularies []*GetVocabularyResponse4App `json:"vocabularies"`
+	ID                    string                       `json:"id"`
+	IsPublished           bool                         `json:"is_published"`
+	Title                 string                       `json:"title"`
+	MainImageUrl          string                       `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time                   `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string            `json:"main_image_variants,omitempty"`
+	MainImagePlaceholder  *imagevariant.Placeholder    `json:"main_image_placeholder,omitempty"`
+	Vocabularies          []*GetVocabularyResponse4App `json:"vocabularies"`
 }
 
 type GetTopic4StudentResponse4Web struct {
-	ID           string `json:"id"`
-	IsPublished  bool   `json:"is_published"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
+	ID                    string            `json:"id"`
+	IsPublished           bool              `json:"is_published"`
+	Title                 string            `json:"title"`
+	MainImageUrl          string            `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time        `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string `json:"main_image_variants,omitempty"`
 }
 
 type GetTopic4StudentResponse4Gw struct {
-	ID           string `json:"id"`
-	IsPublished  bool   `json:"is_published"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
+	ID                    string            `json:"id"`
+	IsPublished           bool              `json:"is_published"`
+	Title                 string            `json:"title"`
+	MainImageUrl          string            `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time        `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string `json:"main_image_variants,omitempty"`
 }
 
 type TopicResponse4GW struct {
-	ID           string `json:"id"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
-	VideoUrl     string `json:"video_url"`
+	ID                    string            `json:"id"`
+	Title                 string            `json:"title"`
+	MainImageUrl          string            `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time        `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string `json:"main_image_variants,omitempty"`
+	VideoUrl              string            `json:"video_url"`
+	VideoUrlExpiresAt     *time.Time        `json:"video_url_expires_at,omitempty"`
+	VideoMedia            *mediaprobe.Info  `json:"video_media,omitempty"` // video_media.duration_ms để hiển thị thời lượng
 }
 
 type TopicResponse2Assign4Web struct {
-	ID           string `json:"id"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
-	VideoUrl     string `json:"video_url"`
+	ID                    string            `json:"id"`
+	Title                 string            `json:"title"`
+	MainImageUrl          string            `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time        `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string `json:"main_image_variants,omitempty"`
+	VideoUrl              string            `json:"video_url"`
+	VideoUrlExpiresAt     *time.Time        `json:"video_url_expires_at,omitempty"`
+	VideoMedia            *mediaprobe.Info  `json:"video_media,omitempty"`
 }
 
 type TopicResponse struct {
-	ID           string `json:"id"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
-	VideoUrl     string `json:"video_url"`
+	ID                    string            `json:"id"`
+	Title                 string            `json:"title"`
+	MainImageUrl          string            `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time        `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string `json:"main_image_variants,omitempty"`
+	VideoUrl              string            `json:"video_url"`
+	VideoUrlExpiresAt     *time.Time        `json:"video_url_expires_at,omitempty"`
+	VideoMedia            *mediaprobe.Info  `json:"video_media,omitempty"`
 }
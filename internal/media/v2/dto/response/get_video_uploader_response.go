@@ -2,21 +2,28 @@ package response
 
 import (
 	"time"
+
+	"media-service/pkg/imagevariant"
+	"media-service/pkg/mediaprobe"
 )
 
 type GetVideoUploaderResponse4Web struct {
-	ID               string    `json:"id"`
-	LanguageID       uint      `json:"language_id"`
-	LanguageConfigID string    `json:"language_config_id"`
-	IsVisible        bool      `json:"is_visible"`
-	CreatedByName    string    `json:"created_by_name"`
-	Title            string    `json:"title"`
-	WikiCode         string    `json:"wiki_code"`
-	VideoUrl         string    `json:"video_url"`
-	ImagePreviewUrl  string    `json:"image_preview_url"`
-	Note             string    `json:"note"`
-	Transcript       string    `json:"transcript"`
-	CreatedAt        time.Time `json:"created_at"`
+	ID                       string                    `json:"id"`
+	LanguageID               uint                      `json:"language_id"`
+	LanguageConfigID         string                    `json:"language_config_id"`
+	IsVisible                bool                      `json:"is_visible"`
+	CreatedByName            string                    `json:"created_by_name"`
+	Title                    string                    `json:"title"`
+	WikiCode                 string                    `json:"wiki_code"`
+	VideoUrl                 string                    `json:"video_url"`
+	VideoUrlExpiresAt        *time.Time                `json:"video_url_expires_at,omitempty"`
+	VideoMedia               *mediaprobe.Info          `json:"video_media,omitempty"`
+	ImagePreviewUrl          string                    `json:"image_preview_url"`
+	ImagePreviewUrlExpiresAt *time.Time                `json:"image_preview_url_expires_at,omitempty"`
+	ImagePreviewPlaceholder  *imagevariant.Placeholder `json:"image_preview_placeholder,omitempty"`
+	Note                     string                    `json:"note"`
+	Transcript               string                    `json:"transcript"`
+	CreatedAt                time.Time                 `json:"created_at"`
 }
 
 type GetDetailVideo4WebResponse struct {
@@ -35,26 +42,47 @@ type DetailVideoMessageLanguageEntry struct {
 }
 
 type DetailVideoLanguageContents struct {
-	Note            string `json:"note"`
-	Transcript      string `json:"transcript"`
-	VideoUrl        string `json:"video_url"`
-	ImagePreviewUrl string `json:"image_preview_url"`
+	Note                     string                    `json:"note"`
+	Transcript               string                    `json:"transcript"`
+	VideoUrl                 string                    `json:"video_url"`
+	VideoUrlExpiresAt        *time.Time                `json:"video_url_expires_at,omitempty"`
+	VideoMedia               *mediaprobe.Info          `json:"video_media,omitempty"`
+	ImagePreviewUrl          string                    `json:"image_preview_url"`
+	ImagePreviewUrlExpiresAt *time.Time                `json:"image_preview_url_expires_at,omitempty"`
+	ImagePreviewPlaceholder  *imagevariant.Placeholder `json:"image_preview_placeholder,omitempty"`
 }
 
 type GetVideosByWikiCode4WebResponse struct {
-	ID              string    `json:"id"`
-	Title           string    `json:"title"`
-	WikiCode        string    `json:"wiki_code"`
-	VideoUrl        string    `json:"video_url"`
-	ImagePreviewUrl string    `json:"image_preview_url"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID                       string                    `json:"id"`
+	Title                    string                    `json:"title"`
+	WikiCode                 string                    `json:"wiki_code"`
+	VideoUrl                 string                    `json:"video_url"`
+	VideoUrlExpiresAt        *time.Time                `json:"video_url_expires_at,omitempty"`
+	VideoMedia               *mediaprobe.Info          `json:"video_media,omitempty"`
+	ImagePreviewUrl          string                    `json:"image_preview_url"`
+	ImagePreviewUrlExpiresAt *time.Time                `json:"image_preview_url_expires_at,omitempty"`
+	ImagePreviewPlaceholder  *imagevariant.Placeholder `json:"image_preview_placeholder,omitempty"`
+	CreatedAt                time.Time                 `json:"created_at"`
 }
 
 type GetVideo4GwResponse struct {
-	ID              string    `json:"id"`
-	Title           string    `json:"title"`
-	WikiCode        string    `json:"wiki_code"`
-	VideoUrl        string    `json:"video_url"`
-	ImagePreviewUrl string    `json:"image_preview_url"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID                       string                    `json:"id"`
+	Title                    string                    `json:"title"`
+	WikiCode                 string                    `json:"wiki_code"`
+	VideoUrl                 string                    `json:"video_url"`
+	VideoUrlExpiresAt        *time.Time                `json:"video_url_expires_at,omitempty"`
+	VideoMedia               *mediaprobe.Info          `json:"video_media,omitempty"`
+	ImagePreviewUrl          string                    `json:"image_preview_url"`
+	ImagePreviewUrlExpiresAt *time.Time                `json:"image_preview_url_expires_at,omitempty"`
+	ImagePreviewPlaceholder  *imagevariant.Placeholder `json:"image_preview_placeholder,omitempty"`
+	CreatedAt                time.Time                 `json:"created_at"`
+}
+
+// MigratePublicURLsResponse là kết quả chuyển video uploader sang public url không ký
+type MigratePublicURLsResponse struct {
+	DryRun         bool     `json:"dry_run"`
+	ScannedVideos  int      `json:"scanned_videos"`
+	MovedObjects   int      `json:"moved_objects"`
+	UnsetDocuments int64    `json:"unset_documents"`
+	Errors         []string `json:"errors,omitempty"`
 }
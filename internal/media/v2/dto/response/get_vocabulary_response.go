@@ -1,10 +1,19 @@
 package response
 
+import (
+	"time"
+
+	"media-service/pkg/imagevariant"
+	"media-service/pkg/mediaprobe"
+)
+
 type VocabularyResponse4Web struct {
-	ID           string                           `json:"id"`
-	IsPublished  bool                             `json:"is_published"`
-	MainImageUrl string                           `json:"main_image_url"`
-	MessageLangs []VocabularyMessageLanguageEntry `json:"message_languages"`
+	ID                    string                           `json:"id"`
+	IsPublished           bool                             `json:"is_published"`
+	MainImageUrl          string                           `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time                       `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string                `json:"main_image_variants,omitempty"` // variant của ảnh chính, name -> url
+	MessageLangs          []VocabularyMessageLanguageEntry `json:"message_languages"`
 }
 
 type VocabularyMessageLanguageEntry struct {
@@ -23,61 +32,82 @@ type VocabularyLanguageContents struct {
 }
 
 type VocabularyMediaContent struct {
-	UploadedURL string `json:"uploaded_url"`
-	LinkURL     string `json:"link_url"`
-	StartTime   string `json:"start_time"`
-	EndTime     string `json:"end_time"`
+	UploadedURL string           `json:"uploaded_url"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"`
+	LinkURL     string           `json:"link_url"`
+	StartTime   string           `json:"start_time"`
+	EndTime     string           `json:"end_time"`
+	StartMs     *int64           `json:"start_ms,omitempty"`
+	EndMs       *int64           `json:"end_ms,omitempty"`
+	Media       *mediaprobe.Info `json:"media,omitempty"`
 }
 
 type VocabularyImgEntry struct {
-	UploadedURL *string `json:"uploaded_url"`
-	LinkURL     string  `json:"link_url"`
+	UploadedURL *string           `json:"uploaded_url"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`
+	LinkURL     string            `json:"link_url"`
+	Variants    map[string]string `json:"variants,omitempty"`
 }
 
 //// 4 App
 
 type GetVocabulary4StudentResponse4App struct {
-	ID           string `json:"id"`
-	IsPublished  bool   `json:"is_published"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
+	ID                    string            `json:"id"`
+	IsPublished           bool              `json:"is_published"`
+	Title                 string            `json:"title"`
+	MainImageUrl          string            `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time        `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string `json:"main_image_variants,omitempty"`
 }
 
 type GetVocabularyResponse4App struct {
-	ID           string `json:"id"`
-	IsPublished  bool   `json:"is_published"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
+	ID                    string                    `json:"id"`
+	IsPublished           bool                      `json:"is_published"`
+	Title                 string                    `json:"title"`
+	MainImageUrl          string                    `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time                `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string         `json:"main_image_variants,omitempty"`
+	MainImagePlaceholder  *imagevariant.Placeholder `json:"main_image_placeholder,omitempty"` // hiển thị thay ảnh chính khi mạng chậm
 }
 
 type GetVocabulary4StudentResponse4Web struct {
-	ID           string `json:"id"`
-	IsPublished  bool   `json:"is_published"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
+	ID                    string            `json:"id"`
+	IsPublished           bool              `json:"is_published"`
+	Title                 string            `json:"title"`
+	MainImageUrl          string            `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time        `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string `json:"main_image_variants,omitempty"`
 }
 
 type GetVocabulary4StudentResponse4Gw struct {
-	ID           string `json:"id"`
-	IsPublished  bool   `json:"is_published"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
+	ID                    string            `json:"id"`
+	IsPublished           bool              `json:"is_published"`
+	Title                 string            `json:"title"`
+	MainImageUrl          string            `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time        `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string `json:"main_image_variants,omitempty"`
 }
 
 type VocabularyResponse4GW struct {
-	ID           string `json:"id"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
+	ID                    string            `json:"id"`
+	Title                 string            `json:"title"`
+	MainImageUrl          string            `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time        `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string `json:"main_image_variants,omitempty"`
 }
 
 type VocabularyResponse2Assign4Web struct {
-	ID           string `json:"id"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
+	ID                    string            `json:"id"`
+	Title                 string            `json:"title"`
+	MainImageUrl          string            `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time        `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string `json:"main_image_variants,omitempty"`
 }
 
 type VocabularyResponse4Gw struct {
-	ID           string `json:"id"`
-	Title        string `json:"title"`
-	MainImageUrl string `json:"main_image_url"`
+	ID                    string            `json:"id"`
+	Title                 string            `json:"title"`
+	MainImageUrl          string            `json:"main_image_url"`
+	MainImageUrlExpiresAt *time.Time        `json:"main_image_url_expires_at,omitempty"`
+	MainImageVariants     map[string]string `json:"main_image_variants,omitempty"`
 }
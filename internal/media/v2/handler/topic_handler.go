@@ -24,6 +24,10 @@ func (h *TopicHandler) UploadTopic(c *fiber.Ctx) error {
 	if err != nil {
 		return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
 	}
+	checksums, err := helper.FormChecksums(c)
+	if err != nil {
+		return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+	}
 
 	// Build request manually
 	req := request.UploadTopicRequest{
@@ -35,12 +39,14 @@ func (h *TopicHandler) UploadTopic(c *fiber.Ctx) error {
 
 		// Audio fields
 		AudioLinkUrl:   c.FormValue("audio_link_url"),
+		AudioUploadID:  c.FormValue("audio_upload_id"),
 		AudioStart:     c.FormValue("audio_start_time"),
 		AudioEnd:       c.FormValue("audio_end_time"),
 		IsDeletedAudio: c.FormValue("is_deleted_audio") == "true",
 
 		// Video fields
 		VideoLinkUrl:   c.FormValue("video_link_url"),
+		VideoUploadID:  c.FormValue("video_upload_id"),
 		VideoStart:     c.FormValue("video_start_time"),
 		VideoEnd:       c.FormValue("video_end_time"),
 		IsDeletedVideo: c.FormValue("is_deleted_video") == "true",
@@ -84,6 +90,7 @@ func (h *TopicHandler) UploadTopic(c *fiber.Ctx) error {
 	}
 
 	// Parse file fields
+	req.Checksums = checksums
 	if audioFile, err := c.FormFile("audio_file"); err == nil {
 		req.AudioFile = audioFile
 	}
@@ -120,7 +127,8 @@ func (h *TopicHandler) UploadTopic(c *fiber.Ctx) error {
 
 	err = h.service.UploadTopic(c.UserContext(), req)
 	if err != nil {
-		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		status, code := helper.MapError(err, http.StatusInternalServerError, helper.ErrInvalidOperation)
+		return helper.SendError(c, status, err, code)
 	}
 
 	return helper.SendSuccess(c, http.StatusOK, "upload topic success", nil)
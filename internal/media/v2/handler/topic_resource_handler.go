@@ -5,6 +5,7 @@ import (
 	"media-service/helper"
 	"media-service/internal/media/v2/dto/request"
 	"media-service/internal/media/v2/service"
+	"media-service/pkg/uploader"
 	"net/http"
 	"strconv"
 
@@ -48,7 +49,8 @@ func (h *TopicResourceHandler) CreateTopicResource(c *fiber.Ctx) error {
 
 	res, err := h.topicResourceService.CreateTopicResource(c.UserContext(), req)
 	if err != nil {
-		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		status, code := helper.MapError(err, http.StatusInternalServerError, helper.ErrInvalidOperation)
+		return helper.SendError(c, status, err, code)
 	}
 	return helper.SendSuccess(c, http.StatusOK, "create topic resource success", res)
 }
@@ -89,7 +91,8 @@ func (h *TopicResourceHandler) UpdateTopicResource(c *fiber.Ctx) error {
 	}
 	res, err := h.topicResourceService.UpdateTopicResource(c.UserContext(), topicResourceID, req)
 	if err != nil {
-		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		status, code := helper.MapError(err, http.StatusInternalServerError, helper.ErrInvalidOperation)
+		return helper.SendError(c, status, err, code)
 	}
 	return helper.SendSuccess(c, http.StatusOK, "update topic resource success", res)
 }
@@ -265,3 +268,15 @@ func (h *TopicResourceHandler) GetTopicResourcesByStudent4Web(c *fiber.Ctx) erro
 	}
 	return helper.SendSuccess(c, http.StatusOK, "get topic resources success", res)
 }
+
+func (h *TopicResourceHandler) GetTopicResourceContent(c *fiber.Ctx) error {
+	topicResourceID := c.Params("topic_resource_id")
+	if topicResourceID == "" {
+		return helper.SendError(c, http.StatusBadRequest, nil, helper.ErrInvalidRequest)
+	}
+	obj, err := h.topicResourceService.OpenTopicResourceContent(c.UserContext(), topicResourceID, uploader.GetObjectOptions{
+		Range:       c.Get(fiber.HeaderRange),
+		IfNoneMatch: c.Get(fiber.HeaderIfNoneMatch),
+	})
+	return helper.SendObject(c, obj, err)
+}
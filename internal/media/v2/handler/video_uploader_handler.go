@@ -26,6 +26,7 @@ func (h *VideoUploaderHandler) UploadVideoUploader(c *fiber.Ctx) error {
 		VideoFolderID:         c.FormValue("video_folder_id"),
 		Title:                 c.FormValue("title"),
 		WikiCode:              c.FormValue("wiki_code"),
+		VideoUploadID:         c.FormValue("video_upload_id"),
 		Note:                  c.FormValue("note"),
 		Transcript:            c.FormValue("transcript"),
 		IsVisible:             c.FormValue("is_visible") == "true",
@@ -50,7 +51,8 @@ func (h *VideoUploaderHandler) UploadVideoUploader(c *fiber.Ctx) error {
 
 	videoUploader, err := h.service.UploadVideoUploader(c.UserContext(), req)
 	if err != nil {
-		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		status, code := helper.MapError(err, http.StatusInternalServerError, helper.ErrInvalidOperation)
+		return helper.SendError(c, status, err, code)
 	}
 
 	return helper.SendSuccess(c, http.StatusOK, "upload video uploader success", videoUploader)
@@ -161,3 +163,13 @@ func (h *VideoUploaderHandler) GetVideo4Gw(c *fiber.Ctx) error {
 	}
 	return helper.SendSuccess(c, http.StatusOK, "get video success", res)
 }
+
+// MigratePublicURLs chuyển video / ảnh preview cũ sang url public không ký, mặc định dry_run=true
+func (h *VideoUploaderHandler) MigratePublicURLs(c *fiber.Ctx) error {
+	dryRun := c.Query("dry_run") != "false"
+	res, err := h.service.MigratePublicURLs(c.UserContext(), dryRun)
+	if err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "migrate public urls success", res)
+}
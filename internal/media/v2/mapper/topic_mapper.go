@@ -3,9 +3,12 @@ package mapper
 import (
 	"media-service/internal/media/model"
 	"media-service/internal/media/v2/dto/response"
+	"media-service/pkg/cliprange"
 	"media-service/pkg/constants"
+	"media-service/pkg/imagevariant"
 	"sort"
 	"strings"
+	"time"
 )
 
 func ToTopicResponses4Web(topics []model.Topic) []response.TopicResponse4Web {
@@ -26,6 +29,7 @@ func ToTopicResponses4Web(topics []model.Topic) []response.TopicResponse4Web {
 		resp := response.TopicResponse4Web{
 			ID:          t.ID.Hex(),
 			IsPublished: t.IsPublished,
+			Incomplete:  t.Incomplete,
 		}
 
 		var langs []response.MessageLanguageEntry
@@ -44,17 +48,25 @@ func ToTopicResponses4Web(topics []model.Topic) []response.TopicResponse4Web {
 			// map audio
 			entry.Contents.Audio = response.MediaContent{
 				UploadedURL: lc.Audio.UploadedUrl,
+				ExpiresAt:   lc.Audio.UploadedUrlExpiresAt,
 				LinkURL:     lc.Audio.LinkUrl,
-				StartTime:   strPtr(trimQuotes(lc.Audio.StartTime)),
-				EndTime:     strPtr(trimQuotes(lc.Audio.EndTime)),
+				StartTime:   clipTime(lc.Audio.StartTime, lc.Audio.StartMs),
+				EndTime:     clipTime(lc.Audio.EndTime, lc.Audio.EndMs),
+				StartMs:     lc.Audio.StartMs,
+				EndMs:       lc.Audio.EndMs,
+				Media:       lc.Audio.Media,
 			}
 
 			// map video
 			entry.Contents.Video = response.MediaContent{
 				UploadedURL: lc.Video.UploadedUrl,
+				ExpiresAt:   lc.Video.UploadedUrlExpiresAt,
 				LinkURL:     lc.Video.LinkUrl,
-				StartTime:   strPtr(trimQuotes(lc.Video.StartTime)),
-				EndTime:     strPtr(trimQuotes(lc.Video.EndTime)),
+				StartTime:   clipTime(lc.Video.StartTime, lc.Video.StartMs),
+				EndTime:     clipTime(lc.Video.EndTime, lc.Video.EndMs),
+				StartMs:     lc.Video.StartMs,
+				EndMs:       lc.Video.EndMs,
+				Media:       lc.Video.Media,
 			}
 
 			// map images slice → object
@@ -63,7 +75,9 @@ func ToTopicResponses4Web(topics []model.Topic) []response.TopicResponse4Web {
 				uploaded := img.UploadedUrl
 				imgMap[img.ImageType] = response.ImgEntry{
 					UploadedURL: &uploaded,
+					ExpiresAt:   img.UploadedUrlExpiresAt,
 					LinkURL:     img.LinkUrl,
+					Variants:    variantURLs(img.Variants),
 				}
 			}
 			entry.Contents.Images = imgMap
@@ -72,12 +86,15 @@ func ToTopicResponses4Web(topics []model.Topic) []response.TopicResponse4Web {
 		}
 
 		mainImageUrl := ""
+		var mainImageUrlExpiresAt *time.Time
+		var mainImageVariants map[string]string
 		if len(t.LanguageConfig) > 0 {
 			for _, lc := range t.LanguageConfig {
 				if lc.LanguageID == 1 {
 					for _, img := range lc.Images {
 						if img.ImageType == string(constants.TopicImageTypeBM) && img.UploadedUrl != "" {
-							mainImageUrl = img.UploadedUrl
+							mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+							mainImageVariants = variantURLs(img.Variants)
 							break
 						}
 					}
@@ -86,6 +103,8 @@ func ToTopicResponses4Web(topics []model.Topic) []response.TopicResponse4Web {
 			}
 		}
 		resp.MainImageUrl = mainImageUrl
+		resp.MainImageUrlExpiresAt = mainImageUrlExpiresAt
+		resp.MainImageVariants = mainImageVariants
 		resp.MessageLangs = langs
 		result = append(result, resp)
 	}
@@ -97,6 +116,28 @@ func trimQuotes(s string) string {
 	return strings.Trim(s, "\"")
 }
 
+// clipTime trả về start / end dạng chuẩn khi đã lưu ms, bản ghi cũ chưa có ms thì giữ chuỗi gốc
+func clipTime(legacy string, ms *int64) string {
+	if ms != nil {
+		return cliprange.Format(*ms)
+	}
+	return strPtr(trimQuotes(legacy))
+}
+
+// variantURLs trả về name -> url đã ký của các variant, nil khi ảnh không có variant
+func variantURLs(variants []model.ImageVariant) map[string]string {
+	if len(variants) == 0 {
+		return nil
+	}
+	urls := make(map[string]string, len(variants))
+	for _, v := range variants {
+		if v.UploadedUrl != "" {
+			urls[v.Name] = v.UploadedUrl
+		}
+	}
+	return urls
+}
+
 func strPtr(s string) string {
 	if s == "" {
 		return ""
@@ -112,6 +153,7 @@ func ToTopicResponse4Web(t *model.Topic) *response.TopicResponse4Web {
 	resp := &response.TopicResponse4Web{
 		ID:          t.ID.Hex(),
 		IsPublished: t.IsPublished,
+		Incomplete:  t.Incomplete,
 	}
 
 	var langs []response.MessageLanguageEntry
@@ -129,17 +171,25 @@ func ToTopicResponse4Web(t *model.Topic) *response.TopicResponse4Web {
 		// map audio
 		entry.Contents.Audio = response.MediaContent{
 			UploadedURL: lc.Audio.UploadedUrl,
+			ExpiresAt:   lc.Audio.UploadedUrlExpiresAt,
 			LinkURL:     lc.Audio.LinkUrl,
-			StartTime:   strPtr(trimQuotes(lc.Audio.StartTime)),
-			EndTime:     strPtr(trimQuotes(lc.Audio.EndTime)),
+			StartTime:   clipTime(lc.Audio.StartTime, lc.Audio.StartMs),
+			EndTime:     clipTime(lc.Audio.EndTime, lc.Audio.EndMs),
+			StartMs:     lc.Audio.StartMs,
+			EndMs:       lc.Audio.EndMs,
+			Media:       lc.Audio.Media,
 		}
 
 		// map video
 		entry.Contents.Video = response.MediaContent{
 			UploadedURL: lc.Video.UploadedUrl,
+			ExpiresAt:   lc.Video.UploadedUrlExpiresAt,
 			LinkURL:     lc.Video.LinkUrl,
-			StartTime:   strPtr(trimQuotes(lc.Video.StartTime)),
-			EndTime:     strPtr(trimQuotes(lc.Video.EndTime)),
+			StartTime:   clipTime(lc.Video.StartTime, lc.Video.StartMs),
+			EndTime:     clipTime(lc.Video.EndTime, lc.Video.EndMs),
+			StartMs:     lc.Video.StartMs,
+			EndMs:       lc.Video.EndMs,
+			Media:       lc.Video.Media,
 		}
 
 		// map images slice → object
@@ -148,7 +198,9 @@ func ToTopicResponse4Web(t *model.Topic) *response.TopicResponse4Web {
 			uploaded := img.UploadedUrl
 			imgMap[img.ImageType] = response.ImgEntry{
 				UploadedURL: &uploaded,
+				ExpiresAt:   img.UploadedUrlExpiresAt,
 				LinkURL:     img.LinkUrl,
+				Variants:    variantURLs(img.Variants),
 			}
 		}
 		entry.Contents.Images = imgMap
@@ -182,20 +234,28 @@ func ToTopic4StudentResponses4App(topics []model.Topic, appLanguage uint) []*res
 		}
 
 		mainImageUrl := ""
+		var mainImageUrlExpiresAt *time.Time
+		var mainImageVariants map[string]string
+		var mainImagePlaceholder *imagevariant.Placeholder
 		if len(langConfig.Images) > 0 {
 			for _, img := range langConfig.Images {
 				if img.ImageType == string(constants.TopicImageTypeBM) {
-					mainImageUrl = img.UploadedUrl
+					mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+					mainImageVariants = variantURLs(img.Variants)
+					mainImagePlaceholder = img.Placeholder
 					break
 				}
 			}
 		}
 
 		res = append(res, &response.GetTopic4StudentResponse4App{
-			ID:           t.ID.Hex(),
-			IsPublished:  t.IsPublished,
-			Title:        langConfig.Title,
-			MainImageUrl: mainImageUrl,
+			ID:                    t.ID.Hex(),
+			IsPublished:           t.IsPublished,
+			Title:                 langConfig.Title,
+			MainImageUrl:          mainImageUrl,
+			MainImageUrlExpiresAt: mainImageUrlExpiresAt,
+			MainImageVariants:     mainImageVariants,
+			MainImagePlaceholder:  mainImagePlaceholder,
 		})
 	}
 
@@ -223,20 +283,25 @@ func ToTopic4StudentResponses4Web(topics []model.Topic, appLanguage uint) []*res
 		}
 
 		mainImageUrl := ""
+		var mainImageUrlExpiresAt *time.Time
+		var mainImageVariants map[string]string
 		if len(langConfig.Images) > 0 {
 			for _, img := range langConfig.Images {
 				if img.ImageType == string(constants.TopicImageTypeBM) {
-					mainImageUrl = img.UploadedUrl
+					mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+					mainImageVariants = variantURLs(img.Variants)
 					break
 				}
 			}
 		}
 
 		res = append(res, &response.GetTopic4StudentResponse4Web{
-			ID:           t.ID.Hex(),
-			IsPublished:  t.IsPublished,
-			Title:        langConfig.Title,
-			MainImageUrl: mainImageUrl,
+			ID:                    t.ID.Hex(),
+			IsPublished:           t.IsPublished,
+			Title:                 langConfig.Title,
+			MainImageUrl:          mainImageUrl,
+			MainImageUrlExpiresAt: mainImageUrlExpiresAt,
+			MainImageVariants:     mainImageVariants,
 		})
 	}
 
@@ -264,20 +329,25 @@ func ToTopic4StudentResponses4Gw(topics []model.Topic, appLanguage uint) []*resp
 		}
 
 		mainImageUrl := ""
+		var mainImageUrlExpiresAt *time.Time
+		var mainImageVariants map[string]string
 		if len(langConfig.Images) > 0 {
 			for _, img := range langConfig.Images {
 				if img.ImageType == string(constants.TopicImageTypeBM) {
-					mainImageUrl = img.UploadedUrl
+					mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+					mainImageVariants = variantURLs(img.Variants)
 					break
 				}
 			}
 		}
 
 		res = append(res, &response.GetTopic4StudentResponse4Gw{
-			ID:           t.ID.Hex(),
-			IsPublished:  t.IsPublished,
-			Title:        langConfig.Title,
-			MainImageUrl: mainImageUrl,
+			ID:                    t.ID.Hex(),
+			IsPublished:           t.IsPublished,
+			Title:                 langConfig.Title,
+			MainImageUrl:          mainImageUrl,
+			MainImageUrlExpiresAt: mainImageUrlExpiresAt,
+			MainImageVariants:     mainImageVariants,
 		})
 	}
 
@@ -307,20 +377,27 @@ func ToTopicResponses4GW(topic *model.Topic, appLanguage uint) *response.TopicRe
 	}
 
 	mainImageUrl := ""
+	var mainImageUrlExpiresAt *time.Time
+	var mainImageVariants map[string]string
 	if len(langConfig.Images) > 0 {
 		for _, img := range langConfig.Images {
 			if img.ImageType == string(constants.TopicImageTypeBM) {
-				mainImageUrl = img.UploadedUrl
+				mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+				mainImageVariants = variantURLs(img.Variants)
 				break
 			}
 		}
 	}
 
 	return &response.TopicResponse4GW{
-		ID:           topic.ID.Hex(),
-		Title:        langConfig.Title,
-		MainImageUrl: mainImageUrl,
-		VideoUrl:     langConfig.Video.UploadedUrl,
+		ID:                    topic.ID.Hex(),
+		Title:                 langConfig.Title,
+		MainImageUrl:          mainImageUrl,
+		MainImageUrlExpiresAt: mainImageUrlExpiresAt,
+		MainImageVariants:     mainImageVariants,
+		VideoUrl:              langConfig.Video.UploadedUrl,
+		VideoUrlExpiresAt:     langConfig.Video.UploadedUrlExpiresAt,
+		VideoMedia:            langConfig.Video.Media,
 	}
 }
 
@@ -345,20 +422,27 @@ func ToTopic2Assign4Web(topics []model.Topic, appLanguage uint) []*response.Topi
 		}
 
 		mainImageUrl := ""
+		var mainImageUrlExpiresAt *time.Time
+		var mainImageVariants map[string]string
 		if len(langConfig.Images) > 0 {
 			for _, img := range langConfig.Images {
 				if img.ImageType == string(constants.TopicImageTypeBM) {
-					mainImageUrl = img.UploadedUrl
+					mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+					mainImageVariants = variantURLs(img.Variants)
 					break
 				}
 			}
 		}
 
 		res = append(res, &response.TopicResponse2Assign4Web{
-			ID:           t.ID.Hex(),
-			Title:        langConfig.Title,
-			MainImageUrl: mainImageUrl,
-			VideoUrl:     langConfig.Video.UploadedUrl,
+			ID:                    t.ID.Hex(),
+			Title:                 langConfig.Title,
+			MainImageUrl:          mainImageUrl,
+			MainImageUrlExpiresAt: mainImageUrlExpiresAt,
+			MainImageVariants:     mainImageVariants,
+			VideoUrl:              langConfig.Video.UploadedUrl,
+			VideoUrlExpiresAt:     langConfig.Video.UploadedUrlExpiresAt,
+			VideoMedia:            langConfig.Video.Media,
 		})
 	}
 
@@ -386,19 +470,27 @@ func ToTopicResponse4App(t *model.Topic, appLanguage uint) *response.GetTopicRes
 
 	// Lấy ảnh full_background (nếu có)
 	mainImageUrl := ""
+	var mainImageUrlExpiresAt *time.Time
+	var mainImageVariants map[string]string
+	var mainImagePlaceholder *imagevariant.Placeholder
 	for _, img := range langConfig.Images {
 		if img.ImageType == string(constants.TopicImageTypeBM) {
-			mainImageUrl = img.UploadedUrl
+			mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+			mainImageVariants = variantURLs(img.Variants)
+			mainImagePlaceholder = img.Placeholder
 			break
 		}
 	}
 
 	// Trả về response
 	return &response.GetTopicResponse4App{
-		ID:           t.ID.Hex(),
-		IsPublished:  t.IsPublished,
-		Title:        langConfig.Title,
-		MainImageUrl: mainImageUrl,
+		ID:                    t.ID.Hex(),
+		IsPublished:           t.IsPublished,
+		Title:                 langConfig.Title,
+		MainImageUrl:          mainImageUrl,
+		MainImageUrlExpiresAt: mainImageUrlExpiresAt,
+		MainImageVariants:     mainImageVariants,
+		MainImagePlaceholder:  mainImagePlaceholder,
 	}
 }
 
@@ -419,20 +511,27 @@ func ToTopicResponse2Assign4Web(t *model.Topic, languageID uint) *response.Topic
 	}
 
 	mainImageUrl := ""
+	var mainImageUrlExpiresAt *time.Time
+	var mainImageVariants map[string]string
 	if len(langConfig.Images) > 0 {
 		for _, img := range langConfig.Images {
 			if img.ImageType == string(constants.TopicImageTypeBM) {
-				mainImageUrl = img.UploadedUrl
+				mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+				mainImageVariants = variantURLs(img.Variants)
 				break
 			}
 		}
 	}
 
 	return &response.TopicResponse2Assign4Web{
-		ID:           t.ID.Hex(),
-		Title:        langConfig.Title,
-		MainImageUrl: mainImageUrl,
-		VideoUrl:     langConfig.Video.UploadedUrl,
+		ID:                    t.ID.Hex(),
+		Title:                 langConfig.Title,
+		MainImageUrl:          mainImageUrl,
+		MainImageUrlExpiresAt: mainImageUrlExpiresAt,
+		MainImageVariants:     mainImageVariants,
+		VideoUrl:              langConfig.Video.UploadedUrl,
+		VideoUrlExpiresAt:     langConfig.Video.UploadedUrlExpiresAt,
+		VideoMedia:            langConfig.Video.Media,
 	}
 }
 
@@ -453,19 +552,26 @@ func ToTopicResponse(t *model.Topic, languageID uint) *response.TopicResponse {
 	}
 
 	mainImageUrl := ""
+	var mainImageUrlExpiresAt *time.Time
+	var mainImageVariants map[string]string
 	if len(langConfig.Images) > 0 {
 		for _, img := range langConfig.Images {
 			if img.ImageType == string(constants.TopicImageTypeBM) {
-				mainImageUrl = img.UploadedUrl
+				mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+				mainImageVariants = variantURLs(img.Variants)
 				break
 			}
 		}
 	}
 
 	return &response.TopicResponse{
-		ID:           t.ID.Hex(),
-		Title:        langConfig.Title,
-		MainImageUrl: mainImageUrl,
-		VideoUrl:     langConfig.Video.UploadedUrl,
+		ID:                    t.ID.Hex(),
+		Title:                 langConfig.Title,
+		MainImageUrl:          mainImageUrl,
+		MainImageUrlExpiresAt: mainImageUrlExpiresAt,
+		MainImageVariants:     mainImageVariants,
+		VideoUrl:              langConfig.Video.UploadedUrl,
+		VideoUrlExpiresAt:     langConfig.Video.UploadedUrlExpiresAt,
+		VideoMedia:            langConfig.Video.Media,
 	}
 }
@@ -12,13 +12,20 @@ import (
 	"time"
 )
 
+// TopicResourceContentPath là endpoint stream ảnh (có kiểm tra quyền), trả cho client thay vì signed url
+func TopicResourceContentPath(tr *model.TopicResource) string {
+	if tr.ImageKey == "" {
+		return ""
+	}
+	return "/api/v2/topic-resources/" + tr.ID.Hex() + "/content"
+}
+
 func ToGetTopicResourceResponses(
 	ctx context.Context,
 	orgID string,
 	topicResources []*model.TopicResource,
 	topicRepository repository.TopicRepository,
 	userGw gateway.UserGateway,
-	s3Service s3.Service,
 ) []*response.GetTopicResourceResponse {
 	if len(topicResources) == 0 {
 		return []*response.GetTopicResourceResponse{}
@@ -31,17 +38,10 @@ func ToGetTopicResourceResponses(
 		}
 
 		// reset per item
-		var imageUrl string
 		var student *gw_response.StudentResponse
 		var createdBy *gw_response.TeacherResponse
 		var topicResp *response.TopicResponse2Assign4Web
 
-		if tr.ImageKey != "" {
-			if url, err := s3Service.Get(ctx, tr.ImageKey, nil); err == nil && url != nil {
-				imageUrl = *url
-			}
-		}
-
 		if tr.StudentID != "" {
 			if studentData, err := userGw.GetStudentInfo(ctx, tr.StudentID); err == nil {
 				student = studentData
@@ -58,32 +58,34 @@ func ToGetTopicResourceResponses(
 			if topic, err := topicRepository.GetByID(ctx, tr.TopicID); err == nil && topic != nil {
 				var title string
 				var mainImageUrl string
+				var mainImageUrlExpiresAt *time.Time
 				appLang := helper.GetAppLanguage(ctx, 1)
 				for _, lc := range topic.LanguageConfig {
 					if lc.LanguageID == appLang {
 						title = lc.Title
 						for _, img := range lc.Images {
 							if img.ImageType == "full_background" {
-								mainImageUrl = img.UploadedUrl
+								mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
 								break
 							}
 						}
 						break
 					}
 				}
-				topicResp = &response.TopicResponse2Assign4Web{ID: topic.ID.Hex(), Title: title, MainImageUrl: mainImageUrl}
+				topicResp = &response.TopicResponse2Assign4Web{ID: topic.ID.Hex(), Title: title, MainImageUrl: mainImageUrl, MainImageUrlExpiresAt: mainImageUrlExpiresAt}
 			}
 		}
 
 		res = append(res, &response.GetTopicResourceResponse{
-			ID:        tr.ID.Hex(),
-			Topic:     topicResp,
-			Student:   student,
-			ImageUrl:  imageUrl,
-			FileName:  tr.FileName,
-			CreatedBy: createdBy,
-			CreatedAt: tr.CreatedAt,
-			UpdatedAt: tr.UpdatedAt,
+			ID:          tr.ID.Hex(),
+			Topic:       topicResp,
+			Student:     student,
+			ImageUrl:    TopicResourceContentPath(tr),
+			Placeholder: tr.Placeholder,
+			FileName:    tr.FileName,
+			CreatedBy:   createdBy,
+			CreatedAt:   tr.CreatedAt,
+			UpdatedAt:   tr.UpdatedAt,
 		})
 	}
 
@@ -96,10 +98,8 @@ func ToGetTopicResourceResponse(
 	topicResource *model.TopicResource,
 	topicRepository repository.TopicRepository,
 	userGw gateway.UserGateway,
-	s3Service s3.Service,
 ) *response.GetTopicResourceResponse {
 
-	var imageUrl string
 	var student *gw_response.StudentResponse
 	var createdBy *gw_response.TeacherResponse
 	var topicResp *response.TopicResponse2Assign4Web
@@ -120,56 +120,52 @@ func ToGetTopicResourceResponse(
 		if topic, err := topicRepository.GetByID(ctx, topicResource.TopicID); err == nil && topic != nil {
 			var title string
 			var mainImageUrl string
+			var mainImageUrlExpiresAt *time.Time
 			appLang := helper.GetAppLanguage(ctx, 1)
 			for _, lc := range topic.LanguageConfig {
 				if lc.LanguageID == appLang {
 					title = lc.Title
 					for _, img := range lc.Images {
 						if img.ImageType == "full_background" {
-							mainImageUrl = img.UploadedUrl
+							mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
 							break
 						}
 					}
 					break
 				}
 			}
-			topicResp = &response.TopicResponse2Assign4Web{ID: topic.ID.Hex(), Title: title, MainImageUrl: mainImageUrl}
-		}
-	}
-
-	if topicResource.ImageKey != "" {
-		if url, err := s3Service.Get(ctx, topicResource.ImageKey, nil); err == nil && url != nil {
-			imageUrl = *url
+			topicResp = &response.TopicResponse2Assign4Web{ID: topic.ID.Hex(), Title: title, MainImageUrl: mainImageUrl, MainImageUrlExpiresAt: mainImageUrlExpiresAt}
 		}
 	}
 
 	return &response.GetTopicResourceResponse{
-		ID:        topicResource.ID.Hex(),
-		Topic:     topicResp,
-		Student:   student,
-		ImageUrl:  imageUrl,
-		FileName:  topicResource.FileName,
-		CreatedBy: createdBy,
-		CreatedAt: topicResource.CreatedAt,
-		UpdatedAt: topicResource.UpdatedAt,
+		ID:          topicResource.ID.Hex(),
+		Topic:       topicResp,
+		Student:     student,
+		ImageUrl:    TopicResourceContentPath(topicResource),
+		Placeholder: topicResource.Placeholder,
+		FileName:    topicResource.FileName,
+		CreatedBy:   createdBy,
+		CreatedAt:   topicResource.CreatedAt,
+		UpdatedAt:   topicResource.UpdatedAt,
 	}
 }
 
 func ToGetTopicResourcesResponse4Web(
 	ctx context.Context,
 	topicResources *model.TopicResource,
-	resourceImageUrl string,
 	topic *response.TopicResponse2Assign4Web,
 ) *response.GetTopicResourcesResponse4Web {
 
 	loc := time.FixedZone("GMT+7", 7*60*60)
 	return &response.GetTopicResourcesResponse4Web{
-		ID:        topicResources.ID.Hex(),
-		FileName:  topicResources.FileName,
-		ImageUrl:  resourceImageUrl,
-		CreatedAt: topicResources.CreatedAt,
-		PicID:     topicResources.CreatedAt.In(loc).Format("02 Jan 2006 15:04"),
-		Topic:     topic,
+		ID:          topicResources.ID.Hex(),
+		FileName:    topicResources.FileName,
+		ImageUrl:    TopicResourceContentPath(topicResources),
+		Placeholder: topicResources.Placeholder,
+		CreatedAt:   topicResources.CreatedAt,
+		PicID:       topicResources.CreatedAt.In(loc).Format("02 Jan 2006 15:04"),
+		Topic:       topic,
 	}
 }
 
@@ -187,12 +183,13 @@ func ToGetTopicResourcesResponse4WebV2(
 
 		dateKey := tr.CreatedAt.In(loc).Format("02 Jan 2006")
 		pic := &response.TopicResourceResponseV2{
-			ID:        tr.ID.Hex(),
-			TopicID:   tr.TopicID,
-			ImageKey:  tr.ImageKey,
-			FileName:  tr.FileName,
-			CreatedAt: tr.CreatedAt,
-			PicID:     tr.CreatedAt.In(loc).Format("02 Jan 2006 15:04"),
+			ID:          tr.ID.Hex(),
+			TopicID:     tr.TopicID,
+			ImageUrl:    TopicResourceContentPath(tr),
+			FileName:    tr.FileName,
+			Placeholder: tr.Placeholder,
+			CreatedAt:   tr.CreatedAt,
+			PicID:       tr.CreatedAt.In(loc).Format("02 Jan 2006 15:04"),
 		}
 
 		grouped[dateKey] = append(grouped[dateKey], pic)
@@ -226,12 +223,13 @@ func ToGetOutputTopicResourcesResponse4Web(
 
 		dateKey := tr.CreatedAt.In(loc).Format("02 Jan 2006")
 		pic := &response.TopicResourceResponseV2{
-			ID:        tr.ID.Hex(),
-			TopicID:   tr.TopicID,
-			ImageKey:  tr.ImageKey,
-			FileName:  tr.FileName,
-			CreatedAt: tr.CreatedAt,
-			PicID:     tr.CreatedAt.In(loc).Format("02 Jan 2006 15:04"),
+			ID:          tr.ID.Hex(),
+			TopicID:     tr.TopicID,
+			ImageUrl:    TopicResourceContentPath(tr),
+			FileName:    tr.FileName,
+			Placeholder: tr.Placeholder,
+			CreatedAt:   tr.CreatedAt,
+			PicID:       tr.CreatedAt.In(loc).Format("02 Jan 2006 15:04"),
 		}
 
 		grouped[dateKey] = append(grouped[dateKey], pic)
@@ -251,16 +249,18 @@ func ToGetOutputTopicResourcesResponse4Web(
 func ToGetTopicResourcesResponse4App(
 	ctx context.Context,
 	topicResources *model.TopicResource,
-	resourceImageUrl string,
+	resourceImage s3.SignedURL,
 	topic response.GetTopicResponse4App,
 ) *response.GetTopicResourcesResponse4App {
 
 	return &response.GetTopicResourcesResponse4App{
-		ID:        topicResources.ID.Hex(),
-		FileName:  topicResources.FileName,
-		ImageUrl:  resourceImageUrl,
-		CreatedAt: topicResources.CreatedAt,
-		PicID:     topicResources.CreatedAt.Format("02 Jan 2006 15:04"),
-		Topic:     topic,
+		ID:                topicResources.ID.Hex(),
+		FileName:          topicResources.FileName,
+		ImageUrl:          resourceImage.URL,
+		ImageUrlExpiresAt: resourceImage.ExpiresAt,
+		Placeholder:       topicResources.Placeholder,
+		CreatedAt:         topicResources.CreatedAt,
+		PicID:             topicResources.CreatedAt.Format("02 Jan 2006 15:04"),
+		Topic:             topic,
 	}
 }
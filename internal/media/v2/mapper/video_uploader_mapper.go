@@ -30,18 +30,22 @@ func ToGetVideosResponse4Web(videoUploaders []model.VideoUploader, createdByName
 		}
 
 		result = append(result, response.GetVideoUploaderResponse4Web{
-			ID:               videoUploader.ID.Hex(),
-			LanguageID:       cfg.LanguageID,
-			LanguageConfigID: cfg.ID.Hex(),
-			IsVisible:        videoUploader.IsVisible,
-			CreatedByName:    creatorName,
-			Title:            videoUploader.Title,
-			WikiCode:         videoUploader.WikiCode,
-			VideoUrl:         cfg.VideoPublicUrl,
-			ImagePreviewUrl:  cfg.ImagePreviewPublicUrl,
-			Note:             cfg.Note,
-			Transcript:       cfg.Transcript,
-			CreatedAt:        videoUploader.CreatedAt,
+			ID:                       videoUploader.ID.Hex(),
+			LanguageID:               cfg.LanguageID,
+			LanguageConfigID:         cfg.ID.Hex(),
+			IsVisible:                videoUploader.IsVisible,
+			CreatedByName:            creatorName,
+			Title:                    videoUploader.Title,
+			WikiCode:                 videoUploader.WikiCode,
+			VideoUrl:                 cfg.VideoPublicUrl,
+			VideoUrlExpiresAt:        cfg.VideoUrlExpiresAt,
+			VideoMedia:               cfg.VideoMedia,
+			ImagePreviewUrl:          cfg.ImagePreviewPublicUrl,
+			ImagePreviewUrlExpiresAt: cfg.ImagePreviewExpiresAt,
+			ImagePreviewPlaceholder:  cfg.ImagePreviewPlaceholder,
+			Note:                     cfg.Note,
+			Transcript:               cfg.Transcript,
+			CreatedAt:                videoUploader.CreatedAt,
 		})
 	}
 	return result
@@ -53,10 +57,14 @@ func ToGetDetailVideo4WebResponse(videoUploader *model.VideoUploader) *response.
 		result = append(result, response.DetailVideoMessageLanguageEntry{
 			LanguageID: int(cfg.LanguageID),
 			Contents: response.DetailVideoLanguageContents{
-				Note:            cfg.Note,
-				Transcript:      cfg.Transcript,
-				VideoUrl:        cfg.VideoPublicUrl,
-				ImagePreviewUrl: cfg.ImagePreviewPublicUrl,
+				Note:                     cfg.Note,
+				Transcript:               cfg.Transcript,
+				VideoUrl:                 cfg.VideoPublicUrl,
+				VideoUrlExpiresAt:        cfg.VideoUrlExpiresAt,
+				VideoMedia:               cfg.VideoMedia,
+				ImagePreviewUrl:          cfg.ImagePreviewPublicUrl,
+				ImagePreviewUrlExpiresAt: cfg.ImagePreviewExpiresAt,
+				ImagePreviewPlaceholder:  cfg.ImagePreviewPlaceholder,
 			},
 		})
 	}
@@ -72,22 +80,24 @@ func ToGetDetailVideo4WebResponse(videoUploader *model.VideoUploader) *response.
 }
 
 func ToVideo4GwResponse(videoUploader *model.VideoUploader, languageID uint) *response.GetVideo4GwResponse {
-	videoUrl := ""
-	imagePreviewUrl := ""
+	var cfg model.VideoUploaderLanguageConfig
 	if languageID != 0 {
-		for _, cfg := range videoUploader.LanguageConfig {
-			if cfg.LanguageID == languageID {
-				videoUrl = cfg.VideoPublicUrl
-				imagePreviewUrl = cfg.ImagePreviewPublicUrl
+		for _, lc := range videoUploader.LanguageConfig {
+			if lc.LanguageID == languageID {
+				cfg = lc
 			}
 		}
 	}
 	return &response.GetVideo4GwResponse{
-		ID:              videoUploader.ID.Hex(),
-		Title:           videoUploader.Title,
-		WikiCode:        videoUploader.WikiCode,
-		VideoUrl:        videoUrl,
-		ImagePreviewUrl: imagePreviewUrl,
-		CreatedAt:       videoUploader.CreatedAt,
+		ID:                       videoUploader.ID.Hex(),
+		Title:                    videoUploader.Title,
+		WikiCode:                 videoUploader.WikiCode,
+		VideoUrl:                 cfg.VideoPublicUrl,
+		VideoUrlExpiresAt:        cfg.VideoUrlExpiresAt,
+		VideoMedia:               cfg.VideoMedia,
+		ImagePreviewUrl:          cfg.ImagePreviewPublicUrl,
+		ImagePreviewUrlExpiresAt: cfg.ImagePreviewExpiresAt,
+		ImagePreviewPlaceholder:  cfg.ImagePreviewPlaceholder,
+		CreatedAt:                videoUploader.CreatedAt,
 	}
 }
@@ -4,6 +4,8 @@ import (
 	"media-service/internal/media/model"
 	"media-service/internal/media/v2/dto/response"
 	"media-service/pkg/constants"
+	"media-service/pkg/imagevariant"
+	"time"
 )
 
 func ToVocabulariesResponses4Web(vocabularies []model.Vocabulary) []*response.VocabularyResponse4Web {
@@ -31,17 +33,25 @@ func ToVocabulariesResponses4Web(vocabularies []model.Vocabulary) []*response.Vo
 			// map audio
 			entry.Contents.Audio = response.VocabularyMediaContent{
 				UploadedURL: lc.Audio.UploadedUrl,
+				ExpiresAt:   lc.Audio.UploadedUrlExpiresAt,
 				LinkURL:     lc.Audio.LinkUrl,
-				StartTime:   strPtr(trimQuotes(lc.Audio.StartTime)),
-				EndTime:     strPtr(trimQuotes(lc.Audio.EndTime)),
+				StartTime:   clipTime(lc.Audio.StartTime, lc.Audio.StartMs),
+				EndTime:     clipTime(lc.Audio.EndTime, lc.Audio.EndMs),
+				StartMs:     lc.Audio.StartMs,
+				EndMs:       lc.Audio.EndMs,
+				Media:       lc.Audio.Media,
 			}
 
 			// map video
 			entry.Contents.Video = response.VocabularyMediaContent{
 				UploadedURL: lc.Video.UploadedUrl,
+				ExpiresAt:   lc.Video.UploadedUrlExpiresAt,
 				LinkURL:     lc.Video.LinkUrl,
-				StartTime:   strPtr(trimQuotes(lc.Video.StartTime)),
-				EndTime:     strPtr(trimQuotes(lc.Video.EndTime)),
+				StartTime:   clipTime(lc.Video.StartTime, lc.Video.StartMs),
+				EndTime:     clipTime(lc.Video.EndTime, lc.Video.EndMs),
+				StartMs:     lc.Video.StartMs,
+				EndMs:       lc.Video.EndMs,
+				Media:       lc.Video.Media,
 			}
 
 			// map images slice → object
@@ -50,7 +60,9 @@ func ToVocabulariesResponses4Web(vocabularies []model.Vocabulary) []*response.Vo
 				uploaded := img.UploadedUrl
 				imgMap[img.ImageType] = response.VocabularyImgEntry{
 					UploadedURL: &uploaded,
+					ExpiresAt:   img.UploadedUrlExpiresAt,
 					LinkURL:     img.LinkUrl,
+					Variants:    variantURLs(img.Variants),
 				}
 			}
 			entry.Contents.Images = imgMap
@@ -59,12 +71,15 @@ func ToVocabulariesResponses4Web(vocabularies []model.Vocabulary) []*response.Vo
 		}
 
 		mainImageUrl := ""
+		var mainImageUrlExpiresAt *time.Time
+		var mainImageVariants map[string]string
 		if len(v.LanguageConfig) > 0 {
 			for _, lc := range v.LanguageConfig {
 				if lc.LanguageID == 1 {
 					for _, img := range lc.Images {
 						if img.ImageType == string(constants.TopicImageTypeBM) && img.UploadedUrl != "" {
-							mainImageUrl = img.UploadedUrl
+							mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+							mainImageVariants = variantURLs(img.Variants)
 							break
 						}
 					}
@@ -73,6 +88,8 @@ func ToVocabulariesResponses4Web(vocabularies []model.Vocabulary) []*response.Vo
 			}
 		}
 		resp.MainImageUrl = mainImageUrl
+		resp.MainImageUrlExpiresAt = mainImageUrlExpiresAt
+		resp.MainImageVariants = mainImageVariants
 		resp.MessageLangs = langs
 		result = append(result, resp)
 	}
@@ -102,20 +119,28 @@ func ToVocabulariesResponses4App(vocabularies []*model.Vocabulary, appLanguage u
 		}
 
 		mainImageUrl := ""
+		var mainImageUrlExpiresAt *time.Time
+		var mainImageVariants map[string]string
+		var mainImagePlaceholder *imagevariant.Placeholder
 		if len(langConfig.Images) > 0 {
 			for _, img := range langConfig.Images {
 				if img.ImageType == string(constants.TopicImageTypeBM) {
-					mainImageUrl = img.UploadedUrl
+					mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+					mainImageVariants = variantURLs(img.Variants)
+					mainImagePlaceholder = img.Placeholder
 					break
 				}
 			}
 		}
 
 		res = append(res, &response.GetVocabularyResponse4App{
-			ID:           v.ID.Hex(),
-			IsPublished:  v.IsPublished,
-			Title:        langConfig.Title,
-			MainImageUrl: mainImageUrl,
+			ID:                    v.ID.Hex(),
+			IsPublished:           v.IsPublished,
+			Title:                 langConfig.Title,
+			MainImageUrl:          mainImageUrl,
+			MainImageUrlExpiresAt: mainImageUrlExpiresAt,
+			MainImageVariants:     mainImageVariants,
+			MainImagePlaceholder:  mainImagePlaceholder,
 		})
 	}
 
@@ -144,19 +169,24 @@ func ToVocabulariesResponse4Gw(vocabularies []model.Vocabulary, appLanguage uint
 		}
 
 		mainImageUrl := ""
+		var mainImageUrlExpiresAt *time.Time
+		var mainImageVariants map[string]string
 		if len(langConfig.Images) > 0 {
 			for _, img := range langConfig.Images {
 				if img.ImageType == string(constants.TopicImageTypeBM) {
-					mainImageUrl = img.UploadedUrl
+					mainImageUrl, mainImageUrlExpiresAt = img.UploadedUrl, img.UploadedUrlExpiresAt
+					mainImageVariants = variantURLs(img.Variants)
 					break
 				}
 			}
 		}
 
 		res = append(res, &response.VocabularyResponse4Gw{
-			ID:           v.ID.Hex(),
-			Title:        langConfig.Title,
-			MainImageUrl: mainImageUrl,
+			ID:                    v.ID.Hex(),
+			Title:                 langConfig.Title,
+			MainImageUrl:          mainImageUrl,
+			MainImageUrlExpiresAt: mainImageUrlExpiresAt,
+			MainImageVariants:     mainImageVariants,
 		})
 	}
 
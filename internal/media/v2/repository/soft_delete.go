@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// notDeleted bỏ qua các document đã bị xoá mềm (đang nằm trong thùng rác)
+func notDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = bson.M{"$exists": false}
+	return filter
+}
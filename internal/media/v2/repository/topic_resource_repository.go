@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"media-service/internal/media/model"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,7 +16,8 @@ type TopicResourceRepository interface {
 	GetTopicResources(ctx context.Context, topicID, studentID string) ([]*model.TopicResource, error)
 	GetTopicResource(ctx context.Context, topicResourceID primitive.ObjectID) (*model.TopicResource, error)
 	UpdateTopicResource(ctx context.Context, topicResourceID primitive.ObjectID, topicResource *model.TopicResource) error
-	DeleteTopicResource(ctx context.Context, topicResourceID primitive.ObjectID) error
+	// SoftDeleteTopicResource trả về false nếu resource không tồn tại hoặc đã bị xoá trước đó
+	SoftDeleteTopicResource(ctx context.Context, topicResourceID primitive.ObjectID, deletedBy string) (bool, error)
 	GetTopicResouresByTopic(ctx context.Context, topicID string) ([]*model.TopicResource, error)
 	GetTopicResouresByStudentID(ctx context.Context, studentID string) ([]*model.TopicResource, error)
 	GetTopicResouresByTopicAndStudent(ctx context.Context, topicID, studentID string) ([]*model.TopicResource, error)
@@ -51,7 +53,7 @@ func (r *topicResourceRepository) GetTopicResources(ctx context.Context, topicID
 		filter["student_id"] = studentID
 	}
 
-	cursor, err := r.topicResourceCollection.Find(ctx, filter)
+	cursor, err := r.topicResourceCollection.Find(ctx, notDeleted(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +68,7 @@ func (r *topicResourceRepository) GetTopicResources(ctx context.Context, topicID
 
 func (r *topicResourceRepository) GetTopicResource(ctx context.Context, topicResourceID primitive.ObjectID) (*model.TopicResource, error) {
 	var result model.TopicResource
-	err := r.topicResourceCollection.FindOne(ctx, bson.M{"_id": topicResourceID}).Decode(&result)
+	err := r.topicResourceCollection.FindOne(ctx, notDeleted(bson.M{"_id": topicResourceID})).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
@@ -81,9 +83,15 @@ func (r *topicResourceRepository) UpdateTopicResource(ctx context.Context, topic
 	return err
 }
 
-func (r *topicResourceRepository) DeleteTopicResource(ctx context.Context, topicResourceID primitive.ObjectID) error {
-	_, err := r.topicResourceCollection.DeleteOne(ctx, bson.M{"_id": topicResourceID})
-	return err
+func (r *topicResourceRepository) SoftDeleteTopicResource(ctx context.Context, topicResourceID primitive.ObjectID, deletedBy string) (bool, error) {
+	res, err := r.topicResourceCollection.UpdateOne(ctx,
+		notDeleted(bson.M{"_id": topicResourceID}),
+		bson.M{"$set": bson.M{"deleted_at": time.Now(), "deleted_by": deletedBy}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
 }
 
 func (r *topicResourceRepository) GetTopicResouresByTopic(ctx context.Context, topicID string) ([]*model.TopicResource, error) {
@@ -91,7 +99,7 @@ func (r *topicResourceRepository) GetTopicResouresByTopic(ctx context.Context, t
 	filter := bson.M{
 		"topic_id": topicID,
 	}
-	cursor, err := r.topicResourceCollection.Find(ctx, filter)
+	cursor, err := r.topicResourceCollection.Find(ctx, notDeleted(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +115,7 @@ func (r *topicResourceRepository) GetTopicResouresByStudentID(ctx context.Contex
 	filter := bson.M{
 		"student_id": studentID,
 	}
-	cursor, err := r.topicResourceCollection.Find(ctx, filter)
+	cursor, err := r.topicResourceCollection.Find(ctx, notDeleted(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +132,7 @@ func (r *topicResourceRepository) GetTopicResouresByTopicAndStudent(ctx context.
 		"topic_id":   topicID,
 		"student_id": studentID,
 	}
-	cursor, err := r.topicResourceCollection.Find(ctx, filter)
+	cursor, err := r.topicResourceCollection.Find(ctx, notDeleted(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -149,7 +157,7 @@ func (r *topicResourceRepository) GetTopicResourcesByStudent(ctx context.Context
 	filter := bson.M{
 		"student_id": studentID,
 	}
-	cursor, err := r.topicResourceCollection.Find(ctx, filter)
+	cursor, err := r.topicResourceCollection.Find(ctx, notDeleted(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +174,7 @@ func (r *topicResourceRepository) GetTopicResouresByStudentIDAndTopicID(ctx cont
 		"student_id": studentID,
 		"topic_id":   topicID,
 	}
-	cursor, err := r.topicResourceCollection.Find(ctx, filter)
+	cursor, err := r.topicResourceCollection.Find(ctx, notDeleted(filter))
 	if err != nil {
 		return nil, err
 	}
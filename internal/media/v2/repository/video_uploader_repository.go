@@ -20,10 +20,15 @@ type VideoUploaderRepository interface {
 	GetAllVideos(ctx context.Context) ([]model.VideoUploader, error)
 	GetVideosIsVisible(ctx context.Context) ([]model.VideoUploader, error)
 	GetVideosByLanguageID(ctx context.Context, languageID uint) ([]model.VideoUploader, error)
-	DeleteVideoUploader(ctx context.Context, videoUploaderID string) error
+	// SoftDeleteVideoUploader trả về false nếu video uploader không tồn tại hoặc đã bị xoá trước đó
+	SoftDeleteVideoUploader(ctx context.Context, videoUploaderID string, deletedBy string) (bool, error)
 	DeleteVideoMetadata(ctx context.Context, videoUploaderID string, languageID uint) error
 	DeleteImagePreviewMetadata(ctx context.Context, videoUploaderID string, languageID uint) error
 	GetVideosByWikiCode(ctx context.Context, wikiCode string) ([]model.VideoUploader, error)
+	// SetLanguageConfig chỉ thay language_config, không đổi updated_at (dùng cho migration)
+	SetLanguageConfig(ctx context.Context, id primitive.ObjectID, languageConfig []model.VideoUploaderLanguageConfig) error
+	// UnsetPublicURLs xoá các *_public_url cũ đã lưu trong Mongo, kể cả document đã xoá mềm
+	UnsetPublicURLs(ctx context.Context) (int64, error)
 }
 
 type videoUploaderRepository struct {
@@ -111,7 +116,7 @@ func (r *videoUploaderRepository) GetVideoUploaderByID(ctx context.Context, vide
 	}
 
 	var videoUploader model.VideoUploader
-	err = r.videoUploaderCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&videoUploader)
+	err = r.videoUploaderCollection.FindOne(ctx, notDeleted(bson.M{"_id": objID})).Decode(&videoUploader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get video uploader: %w", err)
 	}
@@ -121,7 +126,7 @@ func (r *videoUploaderRepository) GetVideoUploaderByID(ctx context.Context, vide
 
 func (r *videoUploaderRepository) GetVideosByCreatedBy(ctx context.Context, createdBy string) ([]model.VideoUploader, error) {
 	var videoUploaders []model.VideoUploader
-	cursor, err := r.videoUploaderCollection.Find(ctx, bson.M{"created_by": createdBy})
+	cursor, err := r.videoUploaderCollection.Find(ctx, notDeleted(bson.M{"created_by": createdBy}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get videos by created by: %w", err)
 	}
@@ -134,7 +139,7 @@ func (r *videoUploaderRepository) GetVideosByCreatedBy(ctx context.Context, crea
 
 func (r *videoUploaderRepository) GetAllVideos(ctx context.Context) ([]model.VideoUploader, error) {
 	var videoUploaders []model.VideoUploader
-	cursor, err := r.videoUploaderCollection.Find(ctx, bson.M{})
+	cursor, err := r.videoUploaderCollection.Find(ctx, notDeleted(bson.M{}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all videos: %w", err)
 	}
@@ -147,7 +152,7 @@ func (r *videoUploaderRepository) GetAllVideos(ctx context.Context) ([]model.Vid
 
 func (r *videoUploaderRepository) GetVideosIsVisible(ctx context.Context) ([]model.VideoUploader, error) {
 	var videoUploaders []model.VideoUploader
-	cursor, err := r.videoUploaderCollection.Find(ctx, bson.M{"is_visible": true})
+	cursor, err := r.videoUploaderCollection.Find(ctx, notDeleted(bson.M{"is_visible": true}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get videos is visible: %w", err)
 	}
@@ -161,7 +166,7 @@ func (r *videoUploaderRepository) GetVideosIsVisible(ctx context.Context) ([]mod
 func (r *videoUploaderRepository) GetVideosByLanguageID(ctx context.Context, languageID uint) ([]model.VideoUploader, error) {
 	var videoUploaders []model.VideoUploader
 	// lọc theo language_config.language_id
-	cursor, err := r.videoUploaderCollection.Find(ctx, bson.M{"language_config.language_id": languageID})
+	cursor, err := r.videoUploaderCollection.Find(ctx, notDeleted(bson.M{"language_config.language_id": languageID}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get videos by language id: %w", err)
 	}
@@ -172,16 +177,19 @@ func (r *videoUploaderRepository) GetVideosByLanguageID(ctx context.Context, lan
 	return videoUploaders, nil
 }
 
-func (r *videoUploaderRepository) DeleteVideoUploader(ctx context.Context, videoUploaderID string) error {
+func (r *videoUploaderRepository) SoftDeleteVideoUploader(ctx context.Context, videoUploaderID string, deletedBy string) (bool, error) {
 	objID, err := primitive.ObjectIDFromHex(videoUploaderID)
 	if err != nil {
-		return fmt.Errorf("invalid videoUploaderID: %w", err)
+		return false, fmt.Errorf("invalid videoUploaderID: %w", err)
 	}
-	_, err = r.videoUploaderCollection.DeleteOne(ctx, bson.M{"_id": objID})
+	res, err := r.videoUploaderCollection.UpdateOne(ctx,
+		notDeleted(bson.M{"_id": objID}),
+		bson.M{"$set": bson.M{"deleted_at": time.Now(), "deleted_by": deletedBy}},
+	)
 	if err != nil {
-		return fmt.Errorf("failed to delete video uploader: %w", err)
+		return false, fmt.Errorf("failed to delete video uploader: %w", err)
 	}
-	return nil
+	return res.ModifiedCount > 0, nil
 }
 
 func (r *videoUploaderRepository) DeleteVideoMetadata(ctx context.Context, videoUploaderID string, languageID uint) error {
@@ -190,7 +198,7 @@ func (r *videoUploaderRepository) DeleteVideoMetadata(ctx context.Context, video
 		return fmt.Errorf("invalid videoUploaderID: %w", err)
 	}
 	filter := bson.M{"_id": objID, "language_config.language_id": languageID}
-	update := bson.M{"$set": bson.M{"language_config.$.video_key": "", "language_config.$.video_public_url": "", "updated_at": time.Now()}}
+	update := bson.M{"$set": bson.M{"language_config.$.video_key": "", "updated_at": time.Now()}}
 	_, err = r.videoUploaderCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return fmt.Errorf("failed to delete video metadata: %w", err)
@@ -205,7 +213,7 @@ func (r *videoUploaderRepository) DeleteImagePreviewMetadata(ctx context.Context
 		return fmt.Errorf("invalid videoUploaderID: %w", err)
 	}
 	filter := bson.M{"_id": objID, "language_config.language_id": languageID}
-	update := bson.M{"$set": bson.M{"language_config.$.image_preview_key": "", "language_config.$.image_preview_public_url": "", "updated_at": time.Now()}}
+	update := bson.M{"$set": bson.M{"language_config.$.image_preview_key": "", "updated_at": time.Now()}}
 	_, err = r.videoUploaderCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return fmt.Errorf("failed to delete image preview metadata: %w", err)
@@ -215,7 +223,7 @@ func (r *videoUploaderRepository) DeleteImagePreviewMetadata(ctx context.Context
 
 func (r *videoUploaderRepository) GetVideosByWikiCode(ctx context.Context, wikiCode string) ([]model.VideoUploader, error) {
 	var videoUploaders []model.VideoUploader
-	cursor, err := r.videoUploaderCollection.Find(ctx, bson.M{"wiki_code": wikiCode})
+	cursor, err := r.videoUploaderCollection.Find(ctx, notDeleted(bson.M{"wiki_code": wikiCode}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get videos by wiki code: %w", err)
 	}
@@ -225,3 +233,29 @@ func (r *videoUploaderRepository) GetVideosByWikiCode(ctx context.Context, wikiC
 	}
 	return videoUploaders, nil
 }
+
+func (r *videoUploaderRepository) SetLanguageConfig(ctx context.Context, id primitive.ObjectID, languageConfig []model.VideoUploaderLanguageConfig) error {
+	_, err := r.videoUploaderCollection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"language_config": languageConfig}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update language config: %w", err)
+	}
+	return nil
+}
+
+func (r *videoUploaderRepository) UnsetPublicURLs(ctx context.Context) (int64, error) {
+	// $[] lỗi nếu language_config không tồn tại
+	res, err := r.videoUploaderCollection.UpdateMany(ctx,
+		bson.M{"language_config.0": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{
+			"language_config.$[].video_public_url":         "",
+			"language_config.$[].image_preview_public_url": "",
+		}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unset public urls: %w", err)
+	}
+	return res.ModifiedCount, nil
+}
@@ -328,14 +328,27 @@ func (r *vocabularyRepository) SetAudio(ctx context.Context, vocabularyID string
 		"language_config.language_id": languageID,
 	}
 
+	audio := bson.M{
+		"audio_key":  aud.AudioKey,
+		"link_url":   aud.LinkUrl,
+		"start_time": aud.StartTime,
+		"end_time":   aud.EndTime,
+	}
+	if aud.Checksum != nil {
+		audio["checksum"] = aud.Checksum
+	}
+	if aud.StartMs != nil {
+		audio["start_ms"] = *aud.StartMs
+	}
+	if aud.EndMs != nil {
+		audio["end_ms"] = *aud.EndMs
+	}
+	if aud.Media != nil {
+		audio["media"] = aud.Media
+	}
 	update := bson.M{
 		"$set": bson.M{
-			"language_config.$.audio": bson.M{
-				"audio_key":  aud.AudioKey,
-				"link_url":   aud.LinkUrl,
-				"start_time": aud.StartTime,
-				"end_time":   aud.EndTime,
-			},
+			"language_config.$.audio": audio,
 		},
 	}
 
@@ -358,14 +371,27 @@ func (r *vocabularyRepository) SetVideo(ctx context.Context, vocabularyID string
 		"language_config.language_id": languageID,
 	}
 
+	video := bson.M{
+		"video_key":  vid.VideoKey,
+		"link_url":   vid.LinkUrl,
+		"start_time": vid.StartTime,
+		"end_time":   vid.EndTime,
+	}
+	if vid.Checksum != nil {
+		video["checksum"] = vid.Checksum
+	}
+	if vid.StartMs != nil {
+		video["start_ms"] = *vid.StartMs
+	}
+	if vid.EndMs != nil {
+		video["end_ms"] = *vid.EndMs
+	}
+	if vid.Media != nil {
+		video["media"] = vid.Media
+	}
 	update := bson.M{
 		"$set": bson.M{
-			"language_config.$.video": bson.M{
-				"video_key":  vid.VideoKey,
-				"link_url":   vid.LinkUrl,
-				"start_time": vid.StartTime,
-				"end_time":   vid.EndTime,
-			},
+			"language_config.$.video": video,
 		},
 	}
 
@@ -419,6 +445,10 @@ func (r *vocabularyRepository) DeleteImageKey(ctx context.Context, vocabularyID
 		"$set": bson.M{
 			"language_config.$[lang].images.$[img].image_key": "",
 		},
+		"$unset": bson.M{
+			"language_config.$[lang].images.$[img].variants":    "",
+			"language_config.$[lang].images.$[img].placeholder": "",
+		},
 	}
 	opts := options.Update().SetArrayFilters(options.ArrayFilters{
 		Filters: []interface{}{
@@ -469,13 +499,33 @@ func (r *vocabularyRepository) SetImage(ctx context.Context, vocabularyID string
 
 	// Cập nhật ảnh nếu image_type đã có
 	filter := bson.M{"_id": objID}
-	update := bson.M{
-		"$set": bson.M{
-			"language_config.$[lang].images.$[img].image_key":    img.ImageKey,
-			"language_config.$[lang].images.$[img].link_url":     img.LinkUrl,
-			"language_config.$[lang].images.$[img].uploaded_url": img.UploadedUrl,
-			"language_config.$[lang].images.$[img].image_type":   img.ImageType,
-		},
+	set := bson.M{
+		"language_config.$[lang].images.$[img].image_key":    img.ImageKey,
+		"language_config.$[lang].images.$[img].link_url":     img.LinkUrl,
+		"language_config.$[lang].images.$[img].uploaded_url": img.UploadedUrl,
+		"language_config.$[lang].images.$[img].image_type":   img.ImageType,
+	}
+	unset := bson.M{}
+	// không có checksum (xoá file, upload qua session...) thì bỏ checksum của file cũ
+	if img.Checksum != nil {
+		set["language_config.$[lang].images.$[img].checksum"] = img.Checksum
+	} else {
+		unset["language_config.$[lang].images.$[img].checksum"] = ""
+	}
+	// variant / placeholder luôn đi cùng key hiện tại, không giữ lại của ảnh cũ
+	if len(img.Variants) > 0 {
+		set["language_config.$[lang].images.$[img].variants"] = img.Variants
+	} else {
+		unset["language_config.$[lang].images.$[img].variants"] = ""
+	}
+	if img.Placeholder != nil {
+		set["language_config.$[lang].images.$[img].placeholder"] = img.Placeholder
+	} else {
+		unset["language_config.$[lang].images.$[img].placeholder"] = ""
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
 	}
 	opts := options.Update().SetArrayFilters(options.ArrayFilters{
 		Filters: []interface{}{
@@ -491,13 +541,23 @@ func (r *vocabularyRepository) SetImage(ctx context.Context, vocabularyID string
 
 	//  Nếu chưa có image_type → push mới
 	if res.MatchedCount == 0 {
+		newImage := bson.M{
+			"image_type": img.ImageType,
+			"image_key":  img.ImageKey,
+			"link_url":   img.LinkUrl,
+		}
+		if img.Checksum != nil {
+			newImage["checksum"] = img.Checksum
+		}
+		if len(img.Variants) > 0 {
+			newImage["variants"] = img.Variants
+		}
+		if img.Placeholder != nil {
+			newImage["placeholder"] = img.Placeholder
+		}
 		pushUpdate := bson.M{
 			"$push": bson.M{
-				"language_config.$[lang].images": bson.M{
-					"image_type": img.ImageType,
-					"image_key":  img.ImageKey,
-					"link_url":   img.LinkUrl,
-				},
+				"language_config.$[lang].images": newImage,
 			},
 		}
 		pushOpts := options.Update().SetArrayFilters(options.ArrayFilters{
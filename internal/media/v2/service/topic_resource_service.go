@@ -12,7 +12,14 @@ import (
 	"media-service/internal/media/v2/mapper"
 	"media-service/internal/media/v2/repository"
 	"media-service/internal/media/v2/usecase"
+	outboxService "media-service/internal/outbox/service"
+	quotaModel "media-service/internal/quota/model"
+	quotaService "media-service/internal/quota/service"
 	"media-service/internal/s3"
+	trashService "media-service/internal/trash/service"
+	"media-service/logger"
+	"media-service/pkg/imagevariant"
+	"media-service/pkg/objectkey"
 	"media-service/pkg/uploader"
 	"time"
 
@@ -32,6 +39,7 @@ type TopicResourceService interface {
 	GetOutputResources4App(ctx context.Context, studentID string, day, month, year int, topicID string) ([]*response.GetTopicResourcesResponse4App, error)
 	OffOutputTopicResource(ctx context.Context, topicResourceID string) error
 	GetTopicResourcesByStudent4Web(ctx context.Context, studentID string) ([]*response.GetTopicResourcesResponseByStudent4Web, error)
+	OpenTopicResourceContent(ctx context.Context, topicResourceID string, opts uploader.GetObjectOptions) (*uploader.ObjectReader, error)
 }
 
 type topicResourceService struct {
@@ -41,6 +49,9 @@ type topicResourceService struct {
 	userGw                      gateway.UserGateway
 	getTopicResourcesWebUseCase usecase.GetTopicResourcesWebUseCase
 	getTopicResourceAppUseCase  usecase.GetTopicResourceAppUseCase
+	trash                       trashService.TrashService
+	quotaService                quotaService.QuotaService
+	deletionOutbox              outboxService.DeletionService
 }
 
 func NewTopicResourceService(
@@ -50,6 +61,9 @@ func NewTopicResourceService(
 	userGw gateway.UserGateway,
 	getTopicResourcesWebUseCase usecase.GetTopicResourcesWebUseCase,
 	getTopicResourceAppUseCase usecase.GetTopicResourceAppUseCase,
+	trash trashService.TrashService,
+	quotaSvc quotaService.QuotaService,
+	deletionOutbox outboxService.DeletionService,
 ) TopicResourceService {
 	return &topicResourceService{
 		topicResourceRepository:     topicResourceRepository,
@@ -58,6 +72,9 @@ func NewTopicResourceService(
 		userGw:                      userGw,
 		getTopicResourcesWebUseCase: getTopicResourcesWebUseCase,
 		getTopicResourceAppUseCase:  getTopicResourceAppUseCase,
+		trash:                       trash,
+		quotaService:                quotaSvc,
+		deletionOutbox:              deletionOutbox,
 	}
 }
 
@@ -79,7 +96,27 @@ func (s *topicResourceService) CreateTopicResource(ctx context.Context, req requ
 		return "", fmt.Errorf("file is required")
 	}
 
-	key := helper.BuildObjectKeyS3("topic_resource", req.File.Filename, req.FileName)
+	orgID, err := usecase.ResolveOrganizationID(ctx, s.topicRepository, req.TopicID)
+	if err != nil {
+		return "", err
+	}
+	if err := s.quotaService.Check(ctx, orgID, req.File.Size); err != nil {
+		return "", err
+	}
+
+	ID := primitive.NewObjectID()
+	key, err := objectkey.Build(objectkey.Object{
+		OrganizationID: orgID,
+		Entity:         objectkey.EntityTopicResource,
+		EntityID:       ID.Hex(),
+		Slot:           "image",
+		FileName:       req.File.Filename,
+		Title:          req.FileName,
+		Folder:         "topic_resource",
+	})
+	if err != nil {
+		return "", err
+	}
 	file, err := req.File.Open()
 	if err != nil {
 		return "", err
@@ -89,22 +126,29 @@ func (s *topicResourceService) CreateTopicResource(ctx context.Context, req requ
 	if err != nil {
 		return "", err
 	}
-	_, err = s.s3Service.Save(ctx, bytes, key, uploader.UploadPrivate)
+	// ảnh chụp từ điện thoại: xoay đúng chiều và bỏ EXIF (GPS, thiết bị...) trước khi lưu
+	bytes, _, err = imagevariant.Sanitize(bytes)
 	if err != nil {
 		return "", err
 	}
-
-	ID := primitive.NewObjectID()
+	storage := s.s3Service.Route(string(quotaModel.CategoryStudentResource), orgID)
+	_, err = storage.Save(ctx, bytes, key, uploader.UploadPrivate)
+	if err != nil {
+		return "", err
+	}
+	s.quotaService.Record(ctx, storage.Bucket(), orgID, quotaModel.CategoryStudentResource, key, int64(len(bytes)))
 
 	topicResource := &model.TopicResource{
-		ID:        ID,
-		TopicID:   req.TopicID,
-		StudentID: req.StudentID,
-		FileName:  req.FileName,
-		ImageKey:  key,
-		CreatedBy: helper.GetUserID(ctx),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:          ID,
+		TopicID:     req.TopicID,
+		StudentID:   req.StudentID,
+		FileName:    req.FileName,
+		ImageKey:    key,
+		Bucket:      storage.Bucket(),
+		Placeholder: imagePlaceholder(bytes),
+		CreatedBy:   helper.GetUserID(ctx),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 
 	err = s.topicResourceRepository.CreateTopicResource(ctx, topicResource)
@@ -126,7 +170,7 @@ func (s *topicResourceService) GetTopicResources(ctx context.Context, topicID, s
 		return nil, err
 	}
 
-	result := mapper.ToGetTopicResourceResponses(ctx, orgID, topicResources, s.topicRepository, s.userGw, s.s3Service)
+	result := mapper.ToGetTopicResourceResponses(ctx, orgID, topicResources, s.topicRepository, s.userGw)
 
 	return result, nil
 }
@@ -151,7 +195,7 @@ func (s *topicResourceService) GetTopicResource(ctx context.Context, topicResour
 		return nil, fmt.Errorf("topic resource not found")
 	}
 
-	return mapper.ToGetTopicResourceResponse(ctx, orgID, topicResource, s.topicRepository, s.userGw, s.s3Service), nil
+	return mapper.ToGetTopicResourceResponse(ctx, orgID, topicResource, s.topicRepository, s.userGw), nil
 }
 
 func (s *topicResourceService) UpdateTopicResource(ctx context.Context, topicResourceID string, req request.UpdateTopicResourceRequest) (string, error) {
@@ -178,14 +222,31 @@ func (s *topicResourceService) UpdateTopicResource(ctx context.Context, topicRes
 		topicResource.TopicID = req.TopicID
 	}
 
+	// ảnh cũ chỉ bị xoá sau khi document đã trỏ sang ảnh mới
+	oldImageKey := topicResource.ImageKey
 	if req.File != nil {
-		if topicResource.ImageKey != "" {
-			err = s.s3Service.Delete(ctx, topicResource.ImageKey)
-			if err != nil {
-				return "", err
-			}
+		orgID, err := usecase.ResolveOrganizationID(ctx, s.topicRepository, topicResource.TopicID)
+		if err != nil {
+			return "", err
+		}
+		if err := s.quotaService.Check(ctx, orgID, req.File.Size); err != nil {
+			return "", err
+		}
+
+		// ảnh mới nằm cùng bucket với ảnh cũ
+		storage := s.s3Service.For(topicResource.Bucket)
+		key, err := objectkey.Build(objectkey.Object{
+			OrganizationID: orgID,
+			Entity:         objectkey.EntityTopicResource,
+			EntityID:       topicResource.ID.Hex(),
+			Slot:           "image",
+			FileName:       req.File.Filename,
+			Title:          topicResource.FileName,
+			Folder:         "topic_resource",
+		})
+		if err != nil {
+			return "", err
 		}
-		key := helper.BuildObjectKeyS3("topic_resource", req.File.Filename, req.FileName)
 		f, err := req.File.Open()
 		if err != nil {
 			return "", err
@@ -195,20 +256,37 @@ func (s *topicResourceService) UpdateTopicResource(ctx context.Context, topicRes
 		if err != nil {
 			return "", err
 		}
-		_, err = s.s3Service.Save(ctx, bs, key, uploader.UploadPrivate)
+		bs, _, err = imagevariant.Sanitize(bs)
 		if err != nil {
 			return "", err
 		}
+		_, err = storage.Save(ctx, bs, key, uploader.UploadPrivate)
+		if err != nil {
+			return "", err
+		}
+		s.quotaService.Record(ctx, storage.Bucket(), orgID, quotaModel.CategoryStudentResource, key, int64(len(bs)))
 		topicResource.ImageKey = key
+		topicResource.Placeholder = imagePlaceholder(bs)
+		// ảnh mới luôn ở STANDARD, tiering sẽ xét lại theo created_at
+		topicResource.StorageClass = string(uploader.StorageClassStandard)
 	}
 
 	topicResource.UpdatedAt = time.Now()
 
 	err = s.topicResourceRepository.UpdateTopicResource(ctx, objectID, topicResource)
 	if err != nil {
+		// document vẫn trỏ ảnh cũ, ảnh mới vừa lưu thành rác
+		if topicResource.ImageKey != oldImageKey {
+			_ = s.deletionOutbox.Enqueue(ctx, "update_topic_resource_rollback", topicResource.Bucket, topicResource.ImageKey)
+		}
 		return "", err
 	}
 
+	if oldImageKey != "" && oldImageKey != topicResource.ImageKey {
+		// object được worker xoá sau, lỗi ghi outbox đã được log
+		_ = s.deletionOutbox.Enqueue(ctx, "update_topic_resource", topicResource.Bucket, oldImageKey)
+	}
+
 	return topicResource.ID.Hex(), nil
 }
 
@@ -227,17 +305,16 @@ func (s *topicResourceService) DeleteTopicResource(ctx context.Context, topicRes
 		return fmt.Errorf("topic resource not found")
 	}
 
-	if topicResource.ImageKey != "" {
-		err = s.s3Service.Delete(ctx, topicResource.ImageKey)
-		if err != nil {
-			return err
-		}
-	}
-
-	err = s.topicResourceRepository.DeleteTopicResource(ctx, objectID)
+	deleted, err := s.topicResourceRepository.SoftDeleteTopicResource(ctx, objectID, helper.GetUserID(ctx))
 	if err != nil {
 		return err
 	}
+	if !deleted {
+		return fmt.Errorf("topic resource not found")
+	}
+
+	// ảnh được giữ trong thùng rác, có thể khôi phục cho tới khi bị purge
+	s.trash.MoveObjects(ctx, topicResource.Bucket, topicResource.ImageKey)
 	return nil
 
 }
@@ -319,3 +396,44 @@ func (s *topicResourceService) OffOutputTopicResource(ctx context.Context, topic
 func (s *topicResourceService) GetTopicResourcesByStudent4Web(ctx context.Context, studentID string) ([]*response.GetTopicResourcesResponseByStudent4Web, error) {
 	return s.getTopicResourcesWebUseCase.GetTopicResourcesByStudent4Web(ctx, studentID)
 }
+
+// OpenTopicResourceContent stream ảnh của học sinh qua service thay vì trả signed url có thể chia sẻ
+func (s *topicResourceService) OpenTopicResourceContent(ctx context.Context, topicResourceID string, opts uploader.GetObjectOptions) (*uploader.ObjectReader, error) {
+	objectID, err := primitive.ObjectIDFromHex(topicResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	topicResource, err := s.topicResourceRepository.GetTopicResource(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if topicResource == nil || topicResource.ImageKey == "" {
+		return nil, uploader.ErrObjectNotFound
+	}
+
+	currentUser := helper.GetCurrentUser(ctx)
+	if currentUser == nil {
+		return nil, fmt.Errorf("access denied")
+	}
+	if !currentUser.IsSuperAdmin && topicResource.CreatedBy != currentUser.ID {
+		topic, err := s.topicRepository.GetByID(ctx, topicResource.TopicID)
+		if err != nil {
+			return nil, err
+		}
+		if topic == nil || !helper.IsOrganizationMember(currentUser, topic.OrganizationID) {
+			return nil, fmt.Errorf("access denied")
+		}
+	}
+
+	return s.s3Service.For(topicResource.Bucket).Open(ctx, topicResource.ImageKey, opts)
+}
+
+// imagePlaceholder chỉ log lỗi: thiếu placeholder không chặn việc lưu ảnh
+func imagePlaceholder(data []byte) *imagevariant.Placeholder {
+	placeholder, err := imagevariant.NewPlaceholder(data)
+	if err != nil {
+		logger.WriteLogEx("warn", "compute image placeholder failed", err)
+	}
+	return placeholder
+}
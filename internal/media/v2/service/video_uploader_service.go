@@ -1,8 +1,10 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"media-service/helper"
 	"media-service/internal/gateway"
 	gw_response "media-service/internal/gateway/dto/response"
@@ -11,9 +13,21 @@ import (
 	"media-service/internal/media/v2/dto/response"
 	"media-service/internal/media/v2/mapper"
 	"media-service/internal/media/v2/repository"
+	outboxService "media-service/internal/outbox/service"
+	quotaModel "media-service/internal/quota/model"
+	quotaService "media-service/internal/quota/service"
 	"media-service/internal/s3"
+	trashService "media-service/internal/trash/service"
+	uploadsessionModel "media-service/internal/uploadsession/model"
+	uploadsessionService "media-service/internal/uploadsession/service"
+	"media-service/logger"
 	"media-service/pkg/constants"
+	"media-service/pkg/imagevariant"
+	"media-service/pkg/mediaprobe"
+	"media-service/pkg/objectkey"
 	"media-service/pkg/uploader"
+	"mime/multipart"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -28,16 +42,23 @@ type VideoUploaderService interface {
 	GetVideo4Web(ctx context.Context, videoUploaderID string) (*response.GetDetailVideo4WebResponse, error)
 	GetVideosByWikiCode4Web(ctx context.Context, wikiCode string, languageID uint) ([]response.GetVideosByWikiCode4WebResponse, error)
 	GetVideo4Gw(ctx context.Context, videoUploaderID string, languageID uint) (*response.GetVideo4GwResponse, error)
+	// MigratePublicURLs chuyển object cũ (signed url 100 năm) vào uploader.PublicPrefix và xoá các url đã lưu
+	MigratePublicURLs(ctx context.Context, dryRun bool) (*response.MigratePublicURLsResponse, error)
 }
 
 type videoUploaderService struct {
 	videoUploaderRepository repository.VideoUploaderRepository
 	s3Service               s3.Service
 	userGateway             gateway.UserGateway
+	uploadSessionService    uploadsessionService.UploadSessionService
+	trash                   trashService.TrashService
+	quotaService            quotaService.QuotaService
+	prober                  mediaprobe.Prober
+	deletionOutbox          outboxService.DeletionService
 }
 
-func NewVideoUploaderService(videoUploaderRepository repository.VideoUploaderRepository, s3Service s3.Service, userGateway gateway.UserGateway) VideoUploaderService {
-	return &videoUploaderService{videoUploaderRepository: videoUploaderRepository, s3Service: s3Service, userGateway: userGateway}
+func NewVideoUploaderService(videoUploaderRepository repository.VideoUploaderRepository, s3Service s3.Service, userGateway gateway.UserGateway, uploadSessionService uploadsessionService.UploadSessionService, trash trashService.TrashService, quotaSvc quotaService.QuotaService, prober mediaprobe.Prober, deletionOutbox outboxService.DeletionService) VideoUploaderService {
+	return &videoUploaderService{videoUploaderRepository: videoUploaderRepository, s3Service: s3Service, userGateway: userGateway, uploadSessionService: uploadSessionService, trash: trash, quotaService: quotaSvc, prober: prober, deletionOutbox: deletionOutbox}
 }
 
 // ======================================================
@@ -49,6 +70,11 @@ func (s *videoUploaderService) UploadVideoUploader(ctx context.Context, req requ
 		return nil, fmt.Errorf("access denied")
 	}
 
+	orgID := helper.GetCurrentOrganizationID(ctx)
+	if err := s.quotaService.Check(ctx, orgID, helper.TotalFileSize(req.VideoFile, req.ImagePreviewFile)); err != nil {
+		return nil, err
+	}
+
 	var videoUploader *model.VideoUploader
 
 	// Step 1: tạo / lấy record trong MongoDB (insert hoặc update, chưa xử lý file)
@@ -64,16 +90,19 @@ func (s *videoUploaderService) UploadVideoUploader(ctx context.Context, req requ
 		videoUploader = existing
 	} else {
 		newVideo := &model.VideoUploader{
+			ID:             primitive.NewObjectID(), // key của file chứa id nên cần có trước khi lưu
 			CreatedBy:      currentUser.ID,
 			IsVisible:      req.IsVisible,
 			Title:          req.Title,
 			WikiCode:       req.WikiCode,
 			LanguageConfig: make([]model.VideoUploaderLanguageConfig, 0),
+			Bucket:         s.s3Service.Route(string(quotaModel.CategoryVideo), orgID).Bucket(),
 			CreatedAt:      time.Now(),
 			UpdatedAt:      time.Now(),
 		}
 		videoUploader = newVideo
 	}
+	storage := s.s3Service.For(videoUploader.Bucket)
 
 	// Step 2: xử lý language config tương ứng với LanguageID
 	langCfgIdx := -1
@@ -96,53 +125,79 @@ func (s *videoUploaderService) UploadVideoUploader(ctx context.Context, req requ
 	cfg.Note = req.Note
 	cfg.Transcript = req.Transcript
 
+	// object bị bỏ (xoá hoặc bị file mới thay thế) chỉ được đưa vào outbox sau khi document đã lưu,
+	// lưu document lỗi thì document vẫn trỏ object cũ và object mới vừa lưu thành rác
+	var staleKeys, newKeys []string
+	prevVideoKey, prevImagePreviewKey := cfg.VideoKey, cfg.ImagePreviewKey
+	rollback := func() {
+		_ = s.deletionOutbox.Enqueue(ctx, "update_video_uploader_rollback", videoUploader.Bucket, unreferencedKeys(newKeys, prevVideoKey, prevImagePreviewKey)...)
+	}
+
 	// Xử lý xoá trước khi upload mới
 	if req.IsDeletedVideo {
-		if cfg.VideoKey != "" {
-			_ = s.s3Service.Delete(ctx, cfg.VideoKey)
-		}
+		staleKeys = append(staleKeys, cfg.VideoKey)
 		cfg.VideoKey = ""
-		cfg.VideoPublicUrl = ""
+		cfg.VideoMedia = nil
 	}
 	if req.IsDeletedImagePreview {
-		if cfg.ImagePreviewKey != "" {
-			_ = s.s3Service.Delete(ctx, cfg.ImagePreviewKey)
-		}
+		staleKeys = append(staleKeys, cfg.ImagePreviewKey)
 		cfg.ImagePreviewKey = ""
-		cfg.ImagePreviewPublicUrl = ""
+		cfg.ImagePreviewPlaceholder = nil
 	}
 
 	// Step 3: Upload đồng bộ video & image cho language config này
 	// Upload video nếu có
 	if helper.IsValidFile(req.VideoFile) {
-		if cfg.VideoKey != "" {
-			_ = s.s3Service.Delete(ctx, cfg.VideoKey)
+		key, err := videoKey(orgID, videoUploader, req, "video", req.VideoFile)
+		if err != nil {
+			return nil, err
 		}
-		videoKey, videoUrl, err := s.processVideoUpload(ctx, req)
+		videoKey, err := s.processVideoUpload(ctx, storage, key, req)
 		if err != nil {
 			return nil, fmt.Errorf("video upload failed: %w", err)
 		}
+		s.quotaService.Record(ctx, videoUploader.Bucket, orgID, quotaModel.CategoryVideo, videoKey, req.VideoFile.Size)
+		staleKeys, newKeys = append(staleKeys, cfg.VideoKey), append(newKeys, videoKey)
 		cfg.VideoKey = videoKey
-		cfg.VideoPublicUrl = videoUrl
+		cfg.VideoMedia = s.probeVideo(ctx, storage, req.VideoFile, videoKey)
+	} else if req.VideoUploadID != "" {
+		// video lớn đã được upload qua resumable upload session
+		videoKey, err := s.processVideoUploadSession(ctx, storage, req)
+		if err != nil {
+			return nil, fmt.Errorf("video upload failed: %w", err)
+		}
+		s.quotaService.Record(ctx, videoUploader.Bucket, orgID, quotaModel.CategoryVideo, videoKey, -1)
+		staleKeys, newKeys = append(staleKeys, cfg.VideoKey), append(newKeys, videoKey)
+		cfg.VideoKey = videoKey
+		cfg.VideoMedia = s.probeVideo(ctx, storage, nil, videoKey)
 	}
 	// Upload ảnh preview nếu có
 	if helper.IsValidFile(req.ImagePreviewFile) {
-		if cfg.ImagePreviewKey != "" {
-			_ = s.s3Service.Delete(ctx, cfg.ImagePreviewKey)
+		key, err := videoKey(orgID, videoUploader, req, "image_preview", req.ImagePreviewFile)
+		if err != nil {
+			rollback()
+			return nil, err
 		}
-		imageKey, imageUrl, err := s.processImagePreviewUpload(ctx, req)
+		imageKey, placeholder, err := s.processImagePreviewUpload(ctx, storage, key, req)
 		if err != nil {
+			rollback()
 			return nil, fmt.Errorf("image upload failed: %w", err)
 		}
+		s.quotaService.Record(ctx, videoUploader.Bucket, orgID, quotaModel.CategoryVideo, imageKey, req.ImagePreviewFile.Size)
+		staleKeys, newKeys = append(staleKeys, cfg.ImagePreviewKey), append(newKeys, imageKey)
 		cfg.ImagePreviewKey = imageKey
-		cfg.ImagePreviewPublicUrl = imageUrl
+		cfg.ImagePreviewPlaceholder = placeholder
 	}
 
 	// Step 4: Lưu toàn bộ document (bao gồm language_config) vào MongoDB
 	if err := s.videoUploaderRepository.SetVideoUploader(ctx, videoUploader); err != nil {
+		rollback()
 		return nil, fmt.Errorf("save video uploader failed: %w", err)
 	}
+	// object được worker xoá sau, lỗi ghi outbox đã được log; key trùng key mới (file bị ghi đè) vẫn đang được dùng
+	_ = s.deletionOutbox.Enqueue(ctx, "update_video_uploader", videoUploader.Bucket, unreferencedKeys(staleKeys, cfg.VideoKey, cfg.ImagePreviewKey)...)
 
+	s.populateUrls(ctx, videoUploader)
 	return videoUploader, nil
 }
 
@@ -150,51 +205,130 @@ func (s *videoUploaderService) UploadVideoUploader(ctx context.Context, req requ
 // =============== PRIVATE HELPERS ======================
 // ======================================================
 
-// xử lý upload video và trả về key + public URL
-func (s *videoUploaderService) processVideoUpload(ctx context.Context, req request.UploadVideoUploaderRequest) (string, string, error) {
+// unreferencedKeys bỏ khỏi keys các key document vẫn còn tham chiếu
+func unreferencedKeys(keys []string, inUse ...string) []string {
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key == "" || slices.Contains(inUse, key) {
+			continue
+		}
+		out = append(out, key)
+	}
+	return out
+}
+
+// videoKey sinh key (vùng public) cho file của video uploader theo storage.key_policy
+func videoKey(orgID string, videoUploader *model.VideoUploader, req request.UploadVideoUploaderRequest, slot string, file *multipart.FileHeader) (string, error) {
+	if file == nil {
+		return "", nil
+	}
+	key, err := objectkey.Build(objectkey.Object{
+		OrganizationID: orgID,
+		Entity:         objectkey.EntityVideo,
+		EntityID:       videoUploader.ID.Hex(),
+		LanguageID:     req.LanguageID,
+		Slot:           slot,
+		FileName:       file.Filename,
+		Title:          slot + "_" + req.Title,
+		Folder:         "media_video_uploader",
+	})
+	if err != nil {
+		return "", err
+	}
+	return uploader.PublicKey(key), nil
+}
+
+// xử lý upload video vào vùng public và trả về key
+func (s *videoUploaderService) processVideoUpload(ctx context.Context, storage s3.Service, key string, req request.UploadVideoUploaderRequest) (string, error) {
 	if req.VideoFile == nil {
-		return "", "", fmt.Errorf("video file is required")
+		return "", fmt.Errorf("video file is required")
 	}
 
-	key := helper.BuildObjectKeyS3("media_video_uploader", req.VideoFile.Filename, "video_"+req.Title)
 	f, openErr := req.VideoFile.Open()
 	if openErr != nil {
-		return "", "", openErr
+		return "", openErr
 	}
 	defer f.Close()
 	ct := req.VideoFile.Header.Get("Content-Type")
-	url, err := s.s3Service.SaveReader(ctx, f, key, ct, uploader.UploadPublic)
+	if _, err := storage.SaveReader(ctx, f, key, ct, uploader.UploadPublic); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// lấy key từ upload session đã hoàn tất (session video uploader đã nằm trong vùng public)
+func (s *videoUploaderService) processVideoUploadSession(ctx context.Context, storage s3.Service, req request.UploadVideoUploaderRequest) (string, error) {
+	return s.uploadSessionService.Consume(ctx, req.VideoUploadID, uploadsessionModel.PurposeVideoUploader, storage.Bucket())
+}
+
+// probeVideo đọc duration / kích thước / codec của video vừa lưu; video session không có file multipart
+// nên được đọc lại từ storage. Probe lỗi không chặn upload.
+func (s *videoUploaderService) probeVideo(ctx context.Context, storage s3.Service, file *multipart.FileHeader, key string) *mediaprobe.Info {
+	var info *mediaprobe.Info
+	var err error
+	if file != nil {
+		info, err = mediaprobe.ProbeFile(ctx, s.prober, file)
+	} else if r, size, openErr := s3.NewReaderAt(ctx, storage, key); openErr != nil {
+		err = openErr
+	} else {
+		info, err = s.prober.Probe(ctx, r, size)
+	}
 	if err != nil {
-		return "", "", err
+		logger.WriteLogEx("warn", "probe video failed", err)
+		return nil
 	}
-	return key, deref(url), nil
+	return info
 }
 
-// xử lý upload ảnh preview và trả về key + public URL
-func (s *videoUploaderService) processImagePreviewUpload(ctx context.Context, req request.UploadVideoUploaderRequest) (string, string, error) {
+// xử lý upload ảnh preview vào vùng public, trả về key và placeholder (nil nếu không tính được)
+func (s *videoUploaderService) processImagePreviewUpload(ctx context.Context, storage s3.Service, key string, req request.UploadVideoUploaderRequest) (string, *imagevariant.Placeholder, error) {
 	if req.ImagePreviewFile == nil {
-		return "", "", fmt.Errorf("image preview file is required")
+		return "", nil, fmt.Errorf("image preview file is required")
 	}
 
-	key := helper.BuildObjectKeyS3("media_video_uploader", req.ImagePreviewFile.Filename, "image_preview_"+req.Title)
 	f, openErr := req.ImagePreviewFile.Open()
 	if openErr != nil {
-		return "", "", openErr
+		return "", nil, openErr
 	}
 	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", nil, err
+	}
 	ct := req.ImagePreviewFile.Header.Get("Content-Type")
-	url, err := s.s3Service.SaveReader(ctx, f, key, ct, uploader.UploadPublic)
+	if _, err := storage.SaveReader(ctx, bytes.NewReader(data), key, ct, uploader.UploadPublic); err != nil {
+		return "", nil, err
+	}
+
+	placeholder, err := imagevariant.NewPlaceholder(data)
 	if err != nil {
-		return "", "", err
+		logger.WriteLogEx("warn", "compute image preview placeholder failed", err)
+	}
+	return key, placeholder, nil
+}
+
+// populateUrls tính url từ key lúc đọc: key trong vùng public → url không ký,
+// key cũ chưa migrate → signed url mặc định
+func (s *videoUploaderService) populateUrls(ctx context.Context, videoUploader *model.VideoUploader) {
+	storage := s.s3Service.For(videoUploader.Bucket)
+	for i := range videoUploader.LanguageConfig {
+		cfg := &videoUploader.LanguageConfig[i]
+		video := objectURL(ctx, storage, cfg.VideoKey)
+		cfg.VideoPublicUrl, cfg.VideoUrlExpiresAt = video.URL, video.ExpiresAt
+		image := objectURL(ctx, storage, cfg.ImagePreviewKey)
+		cfg.ImagePreviewPublicUrl, cfg.ImagePreviewExpiresAt = image.URL, image.ExpiresAt
 	}
-	return key, deref(url), nil
 }
 
-func deref(s *string) string {
-	if s == nil {
-		return ""
+func objectURL(ctx context.Context, storage s3.Service, key string) s3.SignedURL {
+	if key == "" {
+		return s3.SignedURL{}
+	}
+	signed, err := storage.Sign(ctx, key, nil)
+	if err != nil {
+		return s3.SignedURL{}
 	}
-	return *s
+	return *signed
 }
 
 func (s *videoUploaderService) GetVideosUploader4Web(ctx context.Context, languageID, title string, sortBy []request.GetVideoUploaderSortBy) ([]response.GetVideoUploaderResponse4Web, error) {
@@ -219,6 +353,9 @@ func (s *videoUploaderService) GetVideosUploader4Web(ctx context.Context, langua
 			}
 			videoUploaders = filterVideosByTitleAndNote(videoUploaders, strings.TrimSpace(title), langID)
 			videoUploaders = sortVideos(videoUploaders, sortBy)
+			for i := range videoUploaders {
+				s.populateUrls(ctx, &videoUploaders[i])
+			}
 			createdByName := ""
 			if len(videoUploaders) > 0 {
 				userInfo, _ := s.userGateway.GetUserInfo(ctx, videoUploaders[0].CreatedBy)
@@ -237,6 +374,9 @@ func (s *videoUploaderService) GetVideosUploader4Web(ctx context.Context, langua
 	}
 	videoUploaders = filterVideosByTitleAndNote(videoUploaders, strings.TrimSpace(title), 0)
 	videoUploaders = sortVideos(videoUploaders, sortBy)
+	for i := range videoUploaders {
+		s.populateUrls(ctx, &videoUploaders[i])
+	}
 	return mapper.ToGetVideosResponse4Web(videoUploaders, currentUser.Nickname, 0), nil
 
 }
@@ -255,21 +395,22 @@ func (s *videoUploaderService) DeleteVideoUploader(ctx context.Context, videoUpl
 		return fmt.Errorf("video uploader not found")
 	}
 
-	// Xoá toàn bộ file video & image preview của tất cả language config
+	deleted, err := s.videoUploaderRepository.SoftDeleteVideoUploader(ctx, videoUploaderID, currentUser.ID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("video uploader not found")
+	}
+
+	// Chuyển toàn bộ file video & image preview của tất cả language config vào thùng rác
+	keys := make([]string, 0, len(videoUploader.LanguageConfig)*2)
 	for _, cfg := range videoUploader.LanguageConfig {
-		if cfg.VideoKey != "" {
-			if err := s.s3Service.Delete(ctx, cfg.VideoKey); err != nil {
-				return err
-			}
-		}
-		if cfg.ImagePreviewKey != "" {
-			if err := s.s3Service.Delete(ctx, cfg.ImagePreviewKey); err != nil {
-				return err
-			}
-		}
+		keys = append(keys, cfg.VideoKey, cfg.ImagePreviewKey)
 	}
+	s.trash.MoveObjects(ctx, videoUploader.Bucket, keys...)
 
-	return s.videoUploaderRepository.DeleteVideoUploader(ctx, videoUploaderID)
+	return nil
 }
 
 func filterVideosByTitleAndNote(videoUploaders []model.VideoUploader, searchString string, languageID uint) []model.VideoUploader {
@@ -377,6 +518,7 @@ func (s *videoUploaderService) GetVideo4Web(ctx context.Context, videoUploaderID
 	if err != nil {
 		return nil, err
 	}
+	s.populateUrls(ctx, videoUploader)
 	return mapper.ToGetDetailVideo4WebResponse(videoUploader), nil
 }
 
@@ -394,23 +536,31 @@ func (s *videoUploaderService) GetVideosByWikiCode4Web(ctx context.Context, wiki
 			continue
 		}
 
-		videoUrl := ""
-		imagePreviewUrl := ""
+		var video, imagePreview s3.SignedURL
+		var placeholder *imagevariant.Placeholder
+		var media *mediaprobe.Info
 		if languageID != 0 {
 			for _, cfg := range videoUploader.LanguageConfig {
 				if cfg.LanguageID == languageID {
-					videoUrl = cfg.VideoPublicUrl
-					imagePreviewUrl = cfg.ImagePreviewPublicUrl
+					storage := s.s3Service.For(videoUploader.Bucket)
+					video = objectURL(ctx, storage, cfg.VideoKey)
+					imagePreview = objectURL(ctx, storage, cfg.ImagePreviewKey)
+					placeholder = cfg.ImagePreviewPlaceholder
+					media = cfg.VideoMedia
 				}
 			}
 		}
 		result = append(result, response.GetVideosByWikiCode4WebResponse{
-			ID:              videoUploader.ID.Hex(),
-			Title:           videoUploader.Title,
-			WikiCode:        videoUploader.WikiCode,
-			VideoUrl:        videoUrl,
-			ImagePreviewUrl: imagePreviewUrl,
-			CreatedAt:       videoUploader.CreatedAt,
+			ID:                       videoUploader.ID.Hex(),
+			Title:                    videoUploader.Title,
+			WikiCode:                 videoUploader.WikiCode,
+			VideoUrl:                 video.URL,
+			VideoUrlExpiresAt:        video.ExpiresAt,
+			VideoMedia:               media,
+			ImagePreviewUrl:          imagePreview.URL,
+			ImagePreviewUrlExpiresAt: imagePreview.ExpiresAt,
+			ImagePreviewPlaceholder:  placeholder,
+			CreatedAt:                videoUploader.CreatedAt,
 		})
 	}
 	return result, nil
@@ -421,5 +571,65 @@ func (s *videoUploaderService) GetVideo4Gw(ctx context.Context, videoUploaderID
 	if err != nil {
 		return nil, err
 	}
+	s.populateUrls(ctx, videoUploader)
 	return mapper.ToVideo4GwResponse(videoUploader, languageID), nil
 }
+
+func (s *videoUploaderService) MigratePublicURLs(ctx context.Context, dryRun bool) (*response.MigratePublicURLsResponse, error) {
+	// document đã xoá mềm giữ nguyên key: object của chúng đang nằm trong thùng rác theo key gốc
+	videoUploaders, err := s.videoUploaderRepository.GetAllVideos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &response.MigratePublicURLsResponse{DryRun: dryRun, ScannedVideos: len(videoUploaders)}
+	for _, videoUploader := range videoUploaders {
+		storage := s.s3Service.For(videoUploader.Bucket)
+		var oldKeys []string
+		changed := false
+		for i := range videoUploader.LanguageConfig {
+			cfg := &videoUploader.LanguageConfig[i]
+			for _, key := range []*string{&cfg.VideoKey, &cfg.ImagePreviewKey} {
+				if *key == "" || uploader.IsPublicKey(*key) {
+					continue
+				}
+				res.MovedObjects++
+				if dryRun {
+					continue
+				}
+				newKey := uploader.PublicKey(*key)
+				if err := storage.Copy(ctx, *key, newKey); err != nil {
+					res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", *key, err))
+					res.MovedObjects--
+					continue
+				}
+				oldKeys = append(oldKeys, *key)
+				*key = newKey
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		if err := s.videoUploaderRepository.SetLanguageConfig(ctx, videoUploader.ID, videoUploader.LanguageConfig); err != nil {
+			// bản sao mới chưa được tham chiếu, GC sẽ dọn sau grace period
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", videoUploader.ID.Hex(), err))
+			res.MovedObjects -= len(oldKeys)
+			continue
+		}
+		for _, key := range oldKeys {
+			if err := storage.Delete(ctx, key); err != nil {
+				res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", key, err))
+			}
+		}
+	}
+
+	if !dryRun {
+		n, err := s.videoUploaderRepository.UnsetPublicURLs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res.UnsetDocuments = n
+	}
+	return res, nil
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"media-service/helper"
 	"media-service/internal/media/v2/repository"
-	"media-service/internal/s3"
+	outboxService "media-service/internal/outbox/service"
 )
 
 type DeleteTopicFileUseCase interface {
@@ -15,12 +15,12 @@ type DeleteTopicFileUseCase interface {
 }
 
 type deleteTopicFileUseCase struct {
-	topicRepo repository.TopicRepository
-	s3Service s3.Service
+	topicRepo      repository.TopicRepository
+	deletionOutbox outboxService.DeletionService
 }
 
-func NewDeleteTopicFileUseCase(topicRepo repository.TopicRepository, s3Service s3.Service) DeleteTopicFileUseCase {
-	return &deleteTopicFileUseCase{topicRepo: topicRepo, s3Service: s3Service}
+func NewDeleteTopicFileUseCase(topicRepo repository.TopicRepository, deletionOutbox outboxService.DeletionService) DeleteTopicFileUseCase {
+	return &deleteTopicFileUseCase{topicRepo: topicRepo, deletionOutbox: deletionOutbox}
 }
 
 func (uc *deleteTopicFileUseCase) DeleteTopicAudioKey(ctx context.Context, topicID string, languageID uint) error {
@@ -38,17 +38,15 @@ func (uc *deleteTopicFileUseCase) DeleteTopicAudioKey(ctx context.Context, topic
 		return fmt.Errorf("audio key not found")
 	}
 
-	err = uc.s3Service.Delete(ctx, audioKey)
-	if err != nil {
-		return err
-	}
-
 	// goi repo xoa audio key
 	err = uc.topicRepo.DeleteAudioKey(ctx, topicID, languageID)
 	if err != nil {
 		return err
 	}
 
+	// object được worker xoá sau, lỗi ghi outbox đã được log
+	_ = uc.deletionOutbox.Enqueue(ctx, "delete_topic_audio", topic.Bucket, audioKey)
+
 	return nil
 }
 
@@ -67,17 +65,15 @@ func (uc *deleteTopicFileUseCase) DeleteTopicVideoKey(ctx context.Context, topic
 		return fmt.Errorf("video key not found")
 	}
 
-	err = uc.s3Service.Delete(ctx, videoKey)
-	if err != nil {
-		return err
-	}
-
 	// goi repo xoa video key
 	err = uc.topicRepo.DeleteVideoKey(ctx, topicID, languageID)
 	if err != nil {
 		return err
 	}
 
+	// object được worker xoá sau, lỗi ghi outbox đã được log
+	_ = uc.deletionOutbox.Enqueue(ctx, "delete_topic_video", topic.Bucket, videoKey)
+
 	return nil
 }
 
@@ -96,16 +92,15 @@ func (uc *deleteTopicFileUseCase) DeleteTopicImageKey(ctx context.Context, topic
 		return fmt.Errorf("image key not found")
 	}
 
-	err = uc.s3Service.Delete(ctx, imageKey)
-	if err != nil {
-		return err
-	}
-
 	// goi repo xoa image key
 	err = uc.topicRepo.DeleteImageKey(ctx, topicID, languageID, imageType)
 	if err != nil {
 		return err
 	}
 
+	keys := imageObjectKeys(imageKey, helper.GetImageVariantsByLanguageAndType(topic, languageID, imageType))
+	// object được worker xoá sau, lỗi ghi outbox đã được log
+	_ = uc.deletionOutbox.Enqueue(ctx, "delete_topic_image", topic.Bucket, keys...)
+
 	return nil
 }
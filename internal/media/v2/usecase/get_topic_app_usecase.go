@@ -63,9 +63,10 @@ func (uc *getTopicAppUseCase) GetTopics4App(ctx context.Context, organizationID
 			for ii := range langCfg.Images {
 				img := &langCfg.Images[ii]
 				if img.ImageKey != "" {
-					url, err := uc.s3Service.Get(ctx, img.ImageKey, nil)
-					if err == nil && url != nil {
-						img.UploadedUrl = *url
+					signImageVariants(ctx, uc.s3Service.For(topics[ti].Bucket), img.Variants)
+					signed, err := uc.s3Service.For(topics[ti].Bucket).Sign(ctx, img.ImageKey, nil)
+					if err == nil {
+						img.UploadedUrl, img.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 					}
 				}
 			}
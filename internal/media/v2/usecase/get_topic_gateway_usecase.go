@@ -49,9 +49,10 @@ func (uc *getTopicGatewayUseCase) GetTopics4Student4Gw(ctx context.Context, stud
 			for ii := range langCfg.Images {
 				img := &langCfg.Images[ii]
 				if img.ImageKey != "" {
-					url, err := uc.s3Service.Get(ctx, img.ImageKey, nil)
-					if err == nil && url != nil {
-						img.UploadedUrl = *url
+					signImageVariants(ctx, uc.s3Service.For(topics[ti].Bucket), img.Variants)
+					signed, err := uc.s3Service.For(topics[ti].Bucket).Sign(ctx, img.ImageKey, nil)
+					if err == nil {
+						img.UploadedUrl, img.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 					}
 				}
 			}
@@ -97,6 +98,7 @@ func (uc *getTopicGatewayUseCase) GetAllTopicsByOrganization4Gw(ctx context.Cont
 }
 
 func (uc *getTopicGatewayUseCase) populateMediaUrlsForTopic(ctx context.Context, topic *model.Topic) {
+	storage := uc.s3Service.For(topic.Bucket)
 	for li := range topic.LanguageConfig {
 		langCfg := &topic.LanguageConfig[li]
 
@@ -104,9 +106,10 @@ func (uc *getTopicGatewayUseCase) populateMediaUrlsForTopic(ctx context.Context,
 		for ii := range langCfg.Images {
 			img := &langCfg.Images[ii]
 			if img.ImageKey != "" {
-				url, err := uc.s3Service.Get(ctx, img.ImageKey, nil)
-				if err == nil && url != nil {
-					img.UploadedUrl = *url
+				signImageVariants(ctx, storage, img.Variants)
+				signed, err := storage.Sign(ctx, img.ImageKey, nil)
+				if err == nil {
+					img.UploadedUrl, img.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 				} else {
 					logger.WriteLogEx("get_topic_web_usecase", "populateMediaUrlsForTopic_images", fmt.Sprintf("error getting image url: %v", err))
 				}
@@ -115,9 +118,9 @@ func (uc *getTopicGatewayUseCase) populateMediaUrlsForTopic(ctx context.Context,
 
 		// video
 		if langCfg.Video.VideoKey != "" {
-			url, err := uc.s3Service.Get(ctx, langCfg.Video.VideoKey, nil)
-			if err == nil && url != nil {
-				langCfg.Video.UploadedUrl = *url
+			signed, err := storage.Sign(ctx, langCfg.Video.VideoKey, nil)
+			if err == nil {
+				langCfg.Video.UploadedUrl, langCfg.Video.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 			} else {
 				logger.WriteLogEx("get_topic_web_usecase", "populateMediaUrlsForTopic_video", fmt.Sprintf("error getting image url: %v", err))
 			}
@@ -125,9 +128,9 @@ func (uc *getTopicGatewayUseCase) populateMediaUrlsForTopic(ctx context.Context,
 
 		// audio
 		if langCfg.Audio.AudioKey != "" {
-			url, err := uc.s3Service.Get(ctx, langCfg.Audio.AudioKey, nil)
-			if err == nil && url != nil {
-				langCfg.Audio.UploadedUrl = *url
+			signed, err := storage.Sign(ctx, langCfg.Audio.AudioKey, nil)
+			if err == nil {
+				langCfg.Audio.UploadedUrl, langCfg.Audio.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 			} else {
 				logger.WriteLogEx("get_topic_web_usecase", "populateMediaUrlsForTopic_audio", fmt.Sprintf("error getting image url: %v", err))
 			}
@@ -48,11 +48,10 @@ func (uc *getTopicResourceAppUseCase) GetOutputResources4App(ctx context.Context
 		if tr == nil {
 			continue
 		}
-		resourceImageUrl := ""
+		var resourceImage s3.SignedURL
 		if tr.ImageKey != "" {
-			imageUrl, _ := uc.s3Service.Get(ctx, tr.ImageKey, nil)
-			if imageUrl != nil {
-				resourceImageUrl = *imageUrl
+			if signed, err := uc.s3Service.For(tr.Bucket).Sign(ctx, tr.ImageKey, nil); err == nil {
+				resourceImage = *signed
 			}
 		}
 		if tr.IsOutput {
@@ -77,16 +76,17 @@ func (uc *getTopicResourceAppUseCase) GetOutputResources4App(ctx context.Context
 				for i := range langCfg.Images {
 					img := &langCfg.Images[i]
 					if img.ImageKey != "" {
-						url, err := uc.s3Service.Get(ctx, img.ImageKey, nil)
-						if err == nil && url != nil {
-							img.UploadedUrl = *url
+						signImageVariants(ctx, uc.s3Service.For(topic.Bucket), img.Variants)
+						signed, err := uc.s3Service.For(topic.Bucket).Sign(ctx, img.ImageKey, nil)
+						if err == nil {
+							img.UploadedUrl, img.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 						}
 					}
 				}
 			}
 
 			topicResp := mapper.ToTopicResponse4App(topic, appLanguage)
-			result = append(result, mapper.ToGetTopicResourcesResponse4App(ctx, tr, resourceImageUrl, *topicResp))
+			result = append(result, mapper.ToGetTopicResourcesResponse4App(ctx, tr, resourceImage, *topicResp))
 		}
 	}
 	return result, nil
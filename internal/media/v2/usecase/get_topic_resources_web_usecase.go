@@ -10,6 +10,7 @@ import (
 	"media-service/internal/media/v2/mapper"
 	"media-service/internal/media/v2/repository"
 	"media-service/internal/s3"
+	tieringService "media-service/internal/tiering/service"
 	"media-service/logger"
 	"media-service/pkg/constants"
 )
@@ -25,17 +26,20 @@ type getTopicResourcesWebUseCase struct {
 	topicResourceRepository repository.TopicResourceRepository
 	topicRepository         repository.TopicRepository
 	s3Service               s3.Service
+	tiering                 tieringService.TieringService
 }
 
 func NewGetTopicResourcesWebUseCase(
 	topicResourceRepository repository.TopicResourceRepository,
 	topicRepository repository.TopicRepository,
 	s3Service s3.Service,
+	tiering tieringService.TieringService,
 ) GetTopicResourcesWebUseCase {
 	return &getTopicResourcesWebUseCase{
 		topicResourceRepository: topicResourceRepository,
 		topicRepository:         topicRepository,
 		s3Service:               s3Service,
+		tiering:                 tiering,
 	}
 }
 
@@ -70,21 +74,7 @@ func (uc *getTopicResourcesWebUseCase) GetTopicResourcesByTopicAndStudent4Web(ct
 		topicResources = resources
 	}
 
-	res := mapper.ToGetTopicResourcesResponse4WebV2(topicResources)
-
-	// loop res de lay topic va image url
-	for _, res := range res {
-		for _, pic := range res.Pictures {
-			var imageUrl string
-			if pic.ImageUrl == "" {
-				if url, err := uc.s3Service.Get(ctx, pic.ImageKey, nil); err == nil && url != nil {
-					imageUrl = *url
-				}
-			}
-			pic.ImageUrl = imageUrl
-		}
-	}
-	return res, nil
+	return mapper.ToGetTopicResourcesResponse4WebV2(topicResources), nil
 }
 
 func (uc *getTopicResourcesWebUseCase) GetTopicResourcesByTopic4Web(ctx context.Context, topicID string) ([]*response.GetTopicResourcesResponse4Web, error) {
@@ -103,13 +93,7 @@ func (uc *getTopicResourcesWebUseCase) GetTopicResourcesByTopic4Web(ctx context.
 			continue
 		}
 		if tr.IsOutput {
-			var imageUrl string
-			if tr.ImageKey != "" {
-				if url, err := uc.s3Service.Get(ctx, tr.ImageKey, nil); err == nil && url != nil {
-					imageUrl = *url
-				}
-			}
-			result = append(result, mapper.ToGetTopicResourcesResponse4Web(ctx, tr, imageUrl, nil))
+			result = append(result, mapper.ToGetTopicResourcesResponse4Web(ctx, tr, nil))
 		}
 	}
 	return result, nil
@@ -146,21 +130,7 @@ func (uc *getTopicResourcesWebUseCase) GetOutputResources4Web(ctx context.Contex
 		topicResources = resources
 	}
 
-	res := mapper.ToGetOutputTopicResourcesResponse4Web(topicResources)
-
-	// loop res de lay topic va image url
-	for _, res := range res {
-		for _, pic := range res.Pictures {
-			var imageUrl string
-			if pic.ImageUrl == "" {
-				if url, err := uc.s3Service.Get(ctx, pic.ImageKey, nil); err == nil && url != nil {
-					imageUrl = *url
-				}
-			}
-			pic.ImageUrl = imageUrl
-		}
-	}
-	return res, nil
+	return mapper.ToGetOutputTopicResourcesResponse4Web(topicResources), nil
 }
 
 func (uc *getTopicResourcesWebUseCase) GetTopicResourcesByStudent4Web(ctx context.Context, studentID string) ([]*response.GetTopicResourcesResponseByStudent4Web, error) {
@@ -192,19 +162,23 @@ func (uc *getTopicResourcesWebUseCase) GetTopicResourcesByStudent4Web(ctx contex
 		topicResources := uc.filterByTopicID(topicResources, topicID)
 		topicResourceResponses := make([]*response.TopicResourceResponse, 0, len(topicResources))
 		for _, tr := range topicResources {
-			// get resource image url
+			// ảnh đã lưu trữ (GLACIER) được yêu cầu khôi phục, trả về restoring cho tới khi đọc được
+			readable, err := uc.tiering.EnsureReadable(ctx, tr.Bucket, tr.ImageKey, tr.StorageClass)
+			if err != nil {
+				logger.WriteLogEx("get_topic_resources_web_usecase", "GetTopicResourcesByStudent4Web_restore", fmt.Sprintf("error restoring image: %v", err))
+			}
 			var imageUrl string
-			if tr.ImageKey != "" {
-				if url, err := uc.s3Service.Get(ctx, tr.ImageKey, nil); err == nil && url != nil {
-					imageUrl = *url
-				}
+			if readable {
+				imageUrl = mapper.TopicResourceContentPath(tr)
 			}
 			topicResourceResponses = append(topicResourceResponses, &response.TopicResourceResponse{
-				ID:        tr.ID.Hex(),
-				FileName:  tr.FileName,
-				ImageUrl:  imageUrl,
-				CreatedAt: tr.CreatedAt,
-				PicID:     tr.CreatedBy,
+				ID:          tr.ID.Hex(),
+				FileName:    tr.FileName,
+				ImageUrl:    imageUrl,
+				Placeholder: tr.Placeholder,
+				Restoring:   tr.ImageKey != "" && !readable,
+				CreatedAt:   tr.CreatedAt,
+				PicID:       tr.CreatedBy,
 			})
 		}
 		result = append(result, &response.GetTopicResourcesResponseByStudent4Web{
@@ -217,6 +191,7 @@ func (uc *getTopicResourcesWebUseCase) GetTopicResourcesByStudent4Web(ctx contex
 }
 
 func (uc *getTopicResourcesWebUseCase) populateMediaUrlsForTopic(ctx context.Context, topic *model.Topic) {
+	storage := uc.s3Service.For(topic.Bucket)
 	for li := range topic.LanguageConfig {
 		langCfg := &topic.LanguageConfig[li]
 
@@ -224,9 +199,10 @@ func (uc *getTopicResourcesWebUseCase) populateMediaUrlsForTopic(ctx context.Con
 		for ii := range langCfg.Images {
 			img := &langCfg.Images[ii]
 			if img.ImageKey != "" {
-				url, err := uc.s3Service.Get(ctx, img.ImageKey, nil)
-				if err == nil && url != nil {
-					img.UploadedUrl = *url
+				signImageVariants(ctx, storage, img.Variants)
+				signed, err := storage.Sign(ctx, img.ImageKey, nil)
+				if err == nil {
+					img.UploadedUrl, img.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 				} else {
 					logger.WriteLogEx("get_topic_web_usecase", "populateMediaUrlsForTopic_images", fmt.Sprintf("error getting image url: %v", err))
 				}
@@ -235,9 +211,9 @@ func (uc *getTopicResourcesWebUseCase) populateMediaUrlsForTopic(ctx context.Con
 
 		// video
 		if langCfg.Video.VideoKey != "" {
-			url, err := uc.s3Service.Get(ctx, langCfg.Video.VideoKey, nil)
-			if err == nil && url != nil {
-				langCfg.Video.UploadedUrl = *url
+			signed, err := storage.Sign(ctx, langCfg.Video.VideoKey, nil)
+			if err == nil {
+				langCfg.Video.UploadedUrl, langCfg.Video.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 			} else {
 				logger.WriteLogEx("get_topic_web_usecase", "populateMediaUrlsForTopic_video", fmt.Sprintf("error getting image url: %v", err))
 			}
@@ -245,9 +221,9 @@ func (uc *getTopicResourcesWebUseCase) populateMediaUrlsForTopic(ctx context.Con
 
 		// audio
 		if langCfg.Audio.AudioKey != "" {
-			url, err := uc.s3Service.Get(ctx, langCfg.Audio.AudioKey, nil)
-			if err == nil && url != nil {
-				langCfg.Audio.UploadedUrl = *url
+			signed, err := storage.Sign(ctx, langCfg.Audio.AudioKey, nil)
+			if err == nil {
+				langCfg.Audio.UploadedUrl, langCfg.Audio.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 			} else {
 				logger.WriteLogEx("get_topic_web_usecase", "populateMediaUrlsForTopic_audio", fmt.Sprintf("error getting image url: %v", err))
 			}
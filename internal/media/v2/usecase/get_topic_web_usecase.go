@@ -48,9 +48,10 @@ func (uc *getTopicWebUseCase) GetTopics4Student4Web(ctx context.Context, student
 			for ii := range langCfg.Images {
 				img := &langCfg.Images[ii]
 				if img.ImageKey != "" {
-					url, err := uc.s3Service.Get(ctx, img.ImageKey, nil)
-					if err == nil && url != nil {
-						img.UploadedUrl = *url
+					signImageVariants(ctx, uc.s3Service.For(topics[ti].Bucket), img.Variants)
+					signed, err := uc.s3Service.For(topics[ti].Bucket).Sign(ctx, img.ImageKey, nil)
+					if err == nil {
+						img.UploadedUrl, img.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 					}
 				}
 			}
@@ -126,6 +127,7 @@ func (uc *getTopicWebUseCase) GetTopics2Assign4Web(ctx context.Context) ([]*resp
 
 // populateMediaUrlsForTopic enriches a topic's language configs with signed media URLs when keys exist
 func (uc *getTopicWebUseCase) populateMediaUrlsForTopic(ctx context.Context, topic *model.Topic) {
+	storage := uc.s3Service.For(topic.Bucket)
 	for li := range topic.LanguageConfig {
 		langCfg := &topic.LanguageConfig[li]
 
@@ -133,9 +135,10 @@ func (uc *getTopicWebUseCase) populateMediaUrlsForTopic(ctx context.Context, top
 		for ii := range langCfg.Images {
 			img := &langCfg.Images[ii]
 			if img.ImageKey != "" {
-				url, err := uc.s3Service.Get(ctx, img.ImageKey, nil)
-				if err == nil && url != nil {
-					img.UploadedUrl = *url
+				signImageVariants(ctx, storage, img.Variants)
+				signed, err := storage.Sign(ctx, img.ImageKey, nil)
+				if err == nil {
+					img.UploadedUrl, img.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 				} else {
 					logger.WriteLogEx("get_topic_web_usecase", "populateMediaUrlsForTopic_images", fmt.Sprintf("error getting image url: %v", err))
 				}
@@ -144,9 +147,9 @@ func (uc *getTopicWebUseCase) populateMediaUrlsForTopic(ctx context.Context, top
 
 		// video
 		if langCfg.Video.VideoKey != "" {
-			url, err := uc.s3Service.Get(ctx, langCfg.Video.VideoKey, nil)
-			if err == nil && url != nil {
-				langCfg.Video.UploadedUrl = *url
+			signed, err := storage.Sign(ctx, langCfg.Video.VideoKey, nil)
+			if err == nil {
+				langCfg.Video.UploadedUrl, langCfg.Video.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 			} else {
 				logger.WriteLogEx("get_topic_web_usecase", "populateMediaUrlsForTopic_video", fmt.Sprintf("error getting image url: %v", err))
 			}
@@ -154,9 +157,9 @@ func (uc *getTopicWebUseCase) populateMediaUrlsForTopic(ctx context.Context, top
 
 		// audio
 		if langCfg.Audio.AudioKey != "" {
-			url, err := uc.s3Service.Get(ctx, langCfg.Audio.AudioKey, nil)
-			if err == nil && url != nil {
-				langCfg.Audio.UploadedUrl = *url
+			signed, err := storage.Sign(ctx, langCfg.Audio.AudioKey, nil)
+			if err == nil {
+				langCfg.Audio.UploadedUrl, langCfg.Audio.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 			} else {
 				logger.WriteLogEx("get_topic_web_usecase", "populateMediaUrlsForTopic_audio", fmt.Sprintf("error getting image url: %v", err))
 			}
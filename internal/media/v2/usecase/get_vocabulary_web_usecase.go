@@ -66,6 +66,7 @@ func (uc *getVocabularyWebUseCase) GetVocabularies4Web(ctx context.Context, topi
 
 // populateMediaUrlsForTopic enriches a topic's language configs with signed media URLs when keys exist
 func (uc *getVocabularyWebUseCase) populateMediaUrlsForVocabulary(ctx context.Context, vocabulary *model.Vocabulary) {
+	storage := uc.s3Service.For(vocabulary.Bucket)
 	for li := range vocabulary.LanguageConfig {
 		langCfg := &vocabulary.LanguageConfig[li]
 
@@ -73,9 +74,10 @@ func (uc *getVocabularyWebUseCase) populateMediaUrlsForVocabulary(ctx context.Co
 		for ii := range langCfg.Images {
 			img := &langCfg.Images[ii]
 			if img.ImageKey != "" {
-				url, err := uc.s3Service.Get(ctx, img.ImageKey, nil)
-				if err == nil && url != nil {
-					img.UploadedUrl = *url
+				signImageVariants(ctx, storage, img.Variants)
+				signed, err := storage.Sign(ctx, img.ImageKey, nil)
+				if err == nil {
+					img.UploadedUrl, img.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 				} else {
 					logger.WriteLogEx("get_vocabulary_web_usecase", "populateMediaUrlsForVocabulary_images", fmt.Sprintf("error getting image url: %v", err))
 				}
@@ -84,9 +86,9 @@ func (uc *getVocabularyWebUseCase) populateMediaUrlsForVocabulary(ctx context.Co
 
 		// video
 		if langCfg.Video.VideoKey != "" {
-			url, err := uc.s3Service.Get(ctx, langCfg.Video.VideoKey, nil)
-			if err == nil && url != nil {
-				langCfg.Video.UploadedUrl = *url
+			signed, err := storage.Sign(ctx, langCfg.Video.VideoKey, nil)
+			if err == nil {
+				langCfg.Video.UploadedUrl, langCfg.Video.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 			} else {
 				logger.WriteLogEx("get_vocabulary_web_usecase", "populateMediaUrlsForVocabulary_video", fmt.Sprintf("error getting image url: %v", err))
 			}
@@ -94,9 +96,9 @@ func (uc *getVocabularyWebUseCase) populateMediaUrlsForVocabulary(ctx context.Co
 
 		// audio
 		if langCfg.Audio.AudioKey != "" {
-			url, err := uc.s3Service.Get(ctx, langCfg.Audio.AudioKey, nil)
-			if err == nil && url != nil {
-				langCfg.Audio.UploadedUrl = *url
+			signed, err := storage.Sign(ctx, langCfg.Audio.AudioKey, nil)
+			if err == nil {
+				langCfg.Audio.UploadedUrl, langCfg.Audio.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 			} else {
 				logger.WriteLogEx("get_vocabulary_web_usecase", "populateMediaUrlsForVocabulary_audio", fmt.Sprintf("error getting image url: %v", err))
 			}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+
+	"media-service/internal/media/model"
+	quotaModel "media-service/internal/quota/model"
+	quotaService "media-service/internal/quota/service"
+	"media-service/internal/s3"
+	"media-service/logger"
+	"media-service/pkg/imagevariant"
+	"media-service/pkg/uploader"
+)
+
+// processUploadedImage tạo các bản resize của ảnh vừa upload (lưu cạnh key gốc) và placeholder của ảnh.
+// Lỗi chỉ được log: ảnh gốc đã lưu xong và client vẫn dùng được.
+func processUploadedImage(ctx context.Context, storage s3.Service, quota quotaService.QuotaService, orgID string, category quotaModel.Category, file *multipart.FileHeader, key string) ([]model.ImageVariant, *imagevariant.Placeholder) {
+	f, err := file.Open()
+	if err != nil {
+		logger.WriteLogEx("warn", "open uploaded image failed", err)
+		return nil, nil
+	}
+	data, err := io.ReadAll(f)
+	_ = f.Close()
+	if err != nil {
+		logger.WriteLogEx("warn", "read uploaded image failed", err)
+		return nil, nil
+	}
+
+	// giải nén một lần, placeholder và các bản resize cùng lấy từ ảnh này
+	img, err := imagevariant.Decode(data)
+	if err != nil {
+		logger.WriteLogEx("warn", "decode uploaded image failed", err)
+		return nil, nil
+	}
+	if img == nil {
+		return nil, nil
+	}
+	placeholder := img.Placeholder()
+
+	generated, err := img.Variants()
+	if err != nil {
+		logger.WriteLogEx("warn", "generate image variants failed", err)
+		return nil, placeholder
+	}
+
+	variants := make([]model.ImageVariant, 0, len(generated))
+	for _, v := range generated {
+		variantKey := imagevariant.Key(key, v.Name)
+		if _, err := storage.SaveReader(ctx, bytes.NewReader(v.Data), variantKey, v.ContentType, uploader.UploadPrivate); err != nil {
+			logger.WriteLogEx("warn", "save image variant failed", err)
+			continue
+		}
+		quota.Record(ctx, storage.Bucket(), orgID, category, variantKey, int64(len(v.Data)))
+		variants = append(variants, model.ImageVariant{
+			Name:     v.Name,
+			ImageKey: variantKey,
+			Width:    v.Width,
+			Height:   v.Height,
+		})
+	}
+	return variants, placeholder
+}
+
+// signImageVariants ký url cho từng variant, variant ký lỗi thì bỏ trống url
+func signImageVariants(ctx context.Context, storage s3.Service, variants []model.ImageVariant) {
+	for i := range variants {
+		if variants[i].ImageKey == "" {
+			continue
+		}
+		if signed, err := storage.Sign(ctx, variants[i].ImageKey, nil); err == nil {
+			variants[i].UploadedUrl, variants[i].UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"mime/multipart"
+
+	"media-service/internal/s3"
+	"media-service/logger"
+	"media-service/pkg/cliprange"
+	"media-service/pkg/mediaprobe"
+)
+
+// probeUploadedMedia đọc duration / kích thước / codec của audio, video vừa lưu: từ file multipart nếu có,
+// không thì đọc lại object (upload session). Lỗi chỉ được log, file vẫn dùng được khi không probe được.
+func probeUploadedMedia(ctx context.Context, prober mediaprobe.Prober, storage s3.Service, file *multipart.FileHeader, key string) *mediaprobe.Info {
+	var info *mediaprobe.Info
+	var err error
+	if file != nil {
+		info, err = mediaprobe.ProbeFile(ctx, prober, file)
+	} else if r, size, openErr := s3.NewReaderAt(ctx, storage, key); openErr != nil {
+		err = openErr
+	} else {
+		info, err = prober.Probe(ctx, r, size)
+	}
+	if err != nil {
+		logger.WriteLogEx("warn", "probe uploaded media failed", err)
+		return nil
+	}
+	return info
+}
+
+// checkClipRange kiểm tra clip nằm trong duration đã probe; media nil (probe lỗi, bản ghi cũ) thì bỏ qua
+func checkClipRange(clip cliprange.Range, media *mediaprobe.Info) error {
+	if media == nil {
+		return nil
+	}
+	return clip.Validate(media.DurationMs)
+}
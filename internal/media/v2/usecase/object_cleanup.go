@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"media-service/internal/media/model"
+	outboxService "media-service/internal/outbox/service"
+)
+
+// enqueueReplaced đưa object cũ vào outbox sau khi document đã trỏ sang object mới (newKey rỗng = đã xoá),
+// object được worker xoá sau, lỗi ghi outbox đã được log
+func enqueueReplaced(ctx context.Context, outbox outboxService.DeletionService, reason, bucket, oldKey, newKey string) {
+	if oldKey == "" || oldKey == newKey {
+		return
+	}
+	_ = outbox.Enqueue(ctx, reason, bucket, oldKey)
+}
+
+// imageObjectKeys trả về key ảnh gốc kèm key các variant, dùng khi đưa ảnh vào outbox
+func imageObjectKeys(key string, variants []model.ImageVariant) []string {
+	keys := []string{key}
+	for _, v := range variants {
+		keys = append(keys, v.ImageKey)
+	}
+	return keys
+}
+
+// enqueueReplacedImage đưa ảnh gốc + variant cũ của slot vào outbox sau khi slot đã trỏ sang ảnh mới,
+// bỏ qua key trùng với ảnh mới (object đã bị ghi đè)
+func enqueueReplacedImage(ctx context.Context, outbox outboxService.DeletionService, reason, bucket, oldKey string, oldVariants []model.ImageVariant, newKey string, newVariants []model.ImageVariant) {
+	if oldKey == "" {
+		return
+	}
+	inUse := imageObjectKeys(newKey, newVariants)
+	stale := make([]string, 0, len(oldVariants)+1)
+	for _, key := range imageObjectKeys(oldKey, oldVariants) {
+		if !slices.Contains(inUse, key) {
+			stale = append(stale, key)
+		}
+	}
+	_ = outbox.Enqueue(ctx, reason, bucket, stale...)
+}
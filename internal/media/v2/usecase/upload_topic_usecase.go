@@ -10,9 +10,17 @@ import (
 	"media-service/internal/media/model"
 	"media-service/internal/media/v2/dto/request"
 	"media-service/internal/media/v2/repository"
+	outboxService "media-service/internal/outbox/service"
+	quotaModel "media-service/internal/quota/model"
+	quotaService "media-service/internal/quota/service"
 	"media-service/internal/s3"
+	uploadsessionModel "media-service/internal/uploadsession/model"
+	uploadsessionService "media-service/internal/uploadsession/service"
 	"media-service/logger"
+	"media-service/pkg/cliprange"
 	"media-service/pkg/constants"
+	"media-service/pkg/mediaprobe"
+	"media-service/pkg/objectkey"
 	"media-service/pkg/uploader"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -23,14 +31,22 @@ type UploadTopicUseCase interface {
 }
 
 type uploadTopicUseCase struct {
-	topicRepo repository.TopicRepository
-	s3Service s3.Service
+	topicRepo            repository.TopicRepository
+	s3Service            s3.Service
+	uploadSessionService uploadsessionService.UploadSessionService
+	quotaService         quotaService.QuotaService
+	prober               mediaprobe.Prober
+	deletionOutbox       outboxService.DeletionService
 }
 
-func NewUploadTopicUseCase(topicRepo repository.TopicRepository, s3Svc s3.Service) UploadTopicUseCase {
+func NewUploadTopicUseCase(topicRepo repository.TopicRepository, s3Svc s3.Service, uploadSessionSvc uploadsessionService.UploadSessionService, quotaSvc quotaService.QuotaService, prober mediaprobe.Prober, deletionOutbox outboxService.DeletionService) UploadTopicUseCase {
 	return &uploadTopicUseCase{
-		topicRepo: topicRepo,
-		s3Service: s3Svc,
+		topicRepo:            topicRepo,
+		s3Service:            s3Svc,
+		uploadSessionService: uploadSessionSvc,
+		quotaService:         quotaSvc,
+		prober:               prober,
+		deletionOutbox:       deletionOutbox,
 	}
 }
 
@@ -40,6 +56,28 @@ func (uc *uploadTopicUseCase) UploadTopic(ctx context.Context, req request.Uploa
 	var topic *model.Topic
 	var err error
 
+	// start / end sai định dạng (không phải giây, mm:ss, hh:mm:ss.mmm) → trả lỗi ngay, chưa upload gì
+	audioClip, err := cliprange.Parse(req.AudioStart, req.AudioEnd)
+	if err != nil {
+		return err
+	}
+	videoClip, err := cliprange.Parse(req.VideoStart, req.VideoEnd)
+	if err != nil {
+		return err
+	}
+
+	// kiểm tra quota trên tổng dung lượng file trước khi ghi bất cứ thứ gì
+	orgID, err := ResolveOrganizationID(ctx, uc.topicRepo, req.TopicID)
+	if err != nil {
+		return err
+	}
+	requestSize := helper.TotalFileSize(req.AudioFile, req.VideoFile,
+		req.FullBackgroundFile, req.ClearBackgroundFile, req.ClipPartFile, req.DrawingFile,
+		req.IconFile, req.BMFile, req.SignLangFile, req.GifFile, req.OrderFile)
+	if err := uc.quotaService.Check(ctx, orgID, requestSize); err != nil {
+		return err
+	}
+
 	if req.TopicID != "" {
 		// Case update existing topic
 		topic, err = uc.updateTopicLanguage(ctx, req)
@@ -48,24 +86,24 @@ func (uc *uploadTopicUseCase) UploadTopic(ctx context.Context, req request.Uploa
 		}
 	} else {
 		// Case create new topic
-		topic, err = uc.createTopicLanguage(ctx, req)
+		topic, err = uc.createTopicLanguage(ctx, orgID, req)
 		if err != nil {
 			return err
 		}
 	}
 
 	// Thực thi upload đồng bộ, không dùng Redis
-	if err := uc.uploadAndSaveAudio(ctx, topic, req); err != nil {
+	if err := uc.uploadAndSaveAudio(ctx, topic, orgID, req, audioClip); err != nil {
 		logger.WriteLogMsg("error", "Failed to upload and save audio")
 		logger.WriteLogEx("error", "Failed to upload and save audio", err)
 		return err
 	}
-	if err := uc.uploadAndSaveVideo(ctx, topic, req); err != nil {
+	if err := uc.uploadAndSaveVideo(ctx, topic, orgID, req, videoClip); err != nil {
 		logger.WriteLogMsg("error", "Failed to upload and save video")
 		logger.WriteLogEx("error", "Failed to upload and save video", err)
 		return err
 	}
-	if err := uc.uploadAndSaveImages(ctx, topic, req); err != nil {
+	if err := uc.uploadAndSaveImages(ctx, topic, orgID, req); err != nil {
 		logger.WriteLogMsg("error", "Failed to upload and save images")
 		logger.WriteLogEx("error", "Failed to upload and save images", err)
 		return err
@@ -74,60 +112,120 @@ func (uc *uploadTopicUseCase) UploadTopic(ctx context.Context, req request.Uploa
 }
 
 // ------------------- Upload handlers -------------------
-func (uc *uploadTopicUseCase) uploadAndSaveAudio(ctx context.Context, topic *model.Topic, req request.UploadTopicRequest) error {
+func (uc *uploadTopicUseCase) uploadAndSaveAudio(ctx context.Context, topic *model.Topic, orgID string, req request.UploadTopicRequest, clip cliprange.Range) error {
 	topicID := topic.ID.Hex()
 
+	oldAudioKey := helper.GetAudioKeyByLanguage(topic, req.LanguageID)
 	if req.IsDeletedAudio {
-		audioKey := helper.GetAudioKeyByLanguage(topic, req.LanguageID)
-		if audioKey != "" {
-			_ = uc.s3Service.Delete(ctx, audioKey)
-		}
 		// goi repo xoa audio key
 		if err := uc.topicRepo.DeleteAudioKey(ctx, topicID, req.LanguageID); err != nil {
 			logger.WriteLogData("[Time: "+time.Now().Format("2006-01-02 15:04:05")+"] [uploadAndSaveAudio] Failed to delete audio key", err)
 		}
 		// Nếu không có file mới, giữ trạng thái xóa (key rỗng) và chỉ cập nhật metadata
-		if !helper.IsValidFile(req.AudioFile) {
-			return uc.topicRepo.SetAudio(ctx, topicID, req.LanguageID, model.TopicAudioConfig{
+		if !helper.IsValidFile(req.AudioFile) && req.AudioUploadID == "" {
+			if err := uc.topicRepo.SetAudio(ctx, topicID, req.LanguageID, model.TopicAudioConfig{
 				AudioKey:  "",
 				LinkUrl:   req.AudioLinkUrl,
 				StartTime: req.AudioStart,
 				EndTime:   req.AudioEnd,
-			})
+				StartMs:   clip.StartMs,
+				EndMs:     clip.EndMs,
+			}); err != nil {
+				return err
+			}
+			enqueueReplaced(ctx, uc.deletionOutbox, "delete_topic_audio", topic.Bucket, oldAudioKey, "")
+			return nil
 		}
 	}
 
-	oldAudioKey := helper.GetAudioKeyByLanguage(topic, req.LanguageID)
 	if helper.IsValidFile(req.AudioFile) {
 
-		key := helper.BuildObjectKeyS3("topic_media/audio", req.AudioFile.Filename, fmt.Sprintf("%s_audio", req.Title))
+		expected := req.Checksums["audio_file"]
+		key, err := objectkey.Build(objectkey.Object{
+			OrganizationID: orgID,
+			Entity:         objectkey.EntityTopic,
+			EntityID:       topicID,
+			LanguageID:     req.LanguageID,
+			Slot:           "audio",
+			FileName:       req.AudioFile.Filename,
+			Title:          req.Title + "_audio",
+			Folder:         "topic_media/audio",
+		})
+		if err != nil {
+			return err
+		}
+		// probe trước khi lưu: clip vượt quá duration bị từ chối mà không để lại object
+		media := probeUploadedMedia(ctx, uc.prober, uc.s3Service.For(topic.Bucket), req.AudioFile, key)
+		if err := checkClipRange(clip, media); err != nil {
+			return err
+		}
 		f, openErr := req.AudioFile.Open()
 		if openErr != nil {
 			return openErr
 		}
 		defer f.Close()
 		ct := req.AudioFile.Header.Get("Content-Type")
-		_, err := uc.s3Service.SaveReader(ctx, f, key, ct, uploader.UploadPrivate)
+		_, checksum, err := uc.s3Service.For(topic.Bucket).SaveReaderChecked(ctx, f, key, ct, uploader.UploadPrivate, expected)
 		if err != nil {
 			return err
 		}
+		uc.quotaService.Record(ctx, topic.Bucket, orgID, quotaModel.CategoryTopic, key, req.AudioFile.Size)
 		// cập nhật metadata + key (mới hoặc cũ)
 		err = uc.topicRepo.SetAudio(ctx, topicID, req.LanguageID, model.TopicAudioConfig{
 			AudioKey:  key,
 			LinkUrl:   req.AudioLinkUrl,
 			StartTime: req.AudioStart,
 			EndTime:   req.AudioEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Checksum:  checksum,
+			Media:     media,
+		})
+		if err != nil {
+			return err
+		}
+		enqueueReplaced(ctx, uc.deletionOutbox, "update_topic_audio", topic.Bucket, oldAudioKey, key)
+	} else if req.AudioUploadID != "" {
+		// file lớn đã được upload qua resumable upload session
+		key, err := uc.uploadSessionService.Consume(ctx, req.AudioUploadID, uploadsessionModel.PurposeTopicAudio, topic.Bucket)
+		if err != nil {
+			return err
+		}
+		media := probeUploadedMedia(ctx, uc.prober, uc.s3Service.For(topic.Bucket), nil, key)
+		if err := checkClipRange(clip, media); err != nil {
+			// session đã consume, object mới không còn ai tham chiếu
+			_ = uc.deletionOutbox.Enqueue(ctx, "upload_session_rollback", topic.Bucket, key)
+			return err
+		}
+		uc.quotaService.Record(ctx, topic.Bucket, orgID, quotaModel.CategoryTopic, key, -1)
+		err = uc.topicRepo.SetAudio(ctx, topicID, req.LanguageID, model.TopicAudioConfig{
+			AudioKey:  key,
+			LinkUrl:   req.AudioLinkUrl,
+			StartTime: req.AudioStart,
+			EndTime:   req.AudioEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Media:     media,
 		})
 		if err != nil {
 			return err
 		}
+		enqueueReplaced(ctx, uc.deletionOutbox, "update_topic_audio", topic.Bucket, oldAudioKey, key)
 	} else {
 		// cập nhật metadata + key (mới hoặc cũ)
+		media := helper.GetAudioMediaByLanguage(topic, req.LanguageID)
+		if err := checkClipRange(clip, media); err != nil {
+			return err
+		}
 		err := uc.topicRepo.SetAudio(ctx, topicID, req.LanguageID, model.TopicAudioConfig{
 			AudioKey:  oldAudioKey,
 			LinkUrl:   req.AudioLinkUrl,
 			StartTime: req.AudioStart,
 			EndTime:   req.AudioEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Checksum:  helper.GetAudioChecksumByLanguage(topic, req.LanguageID),
+			Media:     media,
 		})
 		if err != nil {
 			return err
@@ -136,61 +234,123 @@ func (uc *uploadTopicUseCase) uploadAndSaveAudio(ctx context.Context, topic *mod
 	return nil
 }
 
-func (uc *uploadTopicUseCase) uploadAndSaveVideo(ctx context.Context, topic *model.Topic, req request.UploadTopicRequest) error {
+func (uc *uploadTopicUseCase) uploadAndSaveVideo(ctx context.Context, topic *model.Topic, orgID string, req request.UploadTopicRequest, clip cliprange.Range) error {
 	topicID := topic.ID.Hex()
 
+	oldVideoKey := helper.GetVideoKeyByLanguage(topic, req.LanguageID)
 	if req.IsDeletedVideo {
-		videoKey := helper.GetVideoKeyByLanguage(topic, req.LanguageID)
-		if videoKey == "" {
+		if oldVideoKey == "" {
 			return fmt.Errorf("video key not found")
 		}
-		_ = uc.s3Service.Delete(ctx, videoKey)
 
 		// goi repo xoa video key (ignore error -> chi ra log)
 		if err := uc.topicRepo.DeleteVideoKey(ctx, topicID, req.LanguageID); err != nil {
 			logger.WriteLogData("[Time: "+time.Now().Format("2006-01-02 15:04:05")+"] [uploadAndSaveVideo] Failed to delete video key", err)
 		}
 		// Nếu không có file mới, giữ trạng thái xóa (key rỗng) và chỉ cập nhật metadata
-		if !helper.IsValidFile(req.VideoFile) {
-			return uc.topicRepo.SetVideo(ctx, topicID, req.LanguageID, model.TopicVideoConfig{
+		if !helper.IsValidFile(req.VideoFile) && req.VideoUploadID == "" {
+			if err := uc.topicRepo.SetVideo(ctx, topicID, req.LanguageID, model.TopicVideoConfig{
 				VideoKey:  "",
 				LinkUrl:   req.VideoLinkUrl,
 				StartTime: req.VideoStart,
 				EndTime:   req.VideoEnd,
-			})
+				StartMs:   clip.StartMs,
+				EndMs:     clip.EndMs,
+			}); err != nil {
+				return err
+			}
+			enqueueReplaced(ctx, uc.deletionOutbox, "delete_topic_video", topic.Bucket, oldVideoKey, "")
+			return nil
 		}
 	}
 
-	oldVideoKey := helper.GetVideoKeyByLanguage(topic, req.LanguageID)
 	if helper.IsValidFile(req.VideoFile) {
 
-		key := helper.BuildObjectKeyS3("topic_media/video", req.VideoFile.Filename, fmt.Sprintf("%s_video", req.Title))
+		expected := req.Checksums["video_file"]
+		key, err := objectkey.Build(objectkey.Object{
+			OrganizationID: orgID,
+			Entity:         objectkey.EntityTopic,
+			EntityID:       topicID,
+			LanguageID:     req.LanguageID,
+			Slot:           "video",
+			FileName:       req.VideoFile.Filename,
+			Title:          req.Title + "_video",
+			Folder:         "topic_media/video",
+		})
+		if err != nil {
+			return err
+		}
+		// probe trước khi lưu: clip vượt quá duration bị từ chối mà không để lại object
+		media := probeUploadedMedia(ctx, uc.prober, uc.s3Service.For(topic.Bucket), req.VideoFile, key)
+		if err := checkClipRange(clip, media); err != nil {
+			return err
+		}
 		f, openErr := req.VideoFile.Open()
 		if openErr != nil {
 			return openErr
 		}
 		defer f.Close()
 		ct := req.VideoFile.Header.Get("Content-Type")
-		_, err := uc.s3Service.SaveReader(ctx, f, key, ct, uploader.UploadPrivate)
+		_, checksum, err := uc.s3Service.For(topic.Bucket).SaveReaderChecked(ctx, f, key, ct, uploader.UploadPrivate, expected)
+		if err != nil {
+			return err
+		}
+		uc.quotaService.Record(ctx, topic.Bucket, orgID, quotaModel.CategoryTopic, key, req.VideoFile.Size)
+		err = uc.topicRepo.SetVideo(ctx, topicID, req.LanguageID, model.TopicVideoConfig{
+			VideoKey:  key,
+			LinkUrl:   req.VideoLinkUrl,
+			StartTime: req.VideoStart,
+			EndTime:   req.VideoEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Checksum:  checksum,
+			Media:     media,
+		})
 		if err != nil {
 			return err
 		}
+		enqueueReplaced(ctx, uc.deletionOutbox, "update_topic_video", topic.Bucket, oldVideoKey, key)
+	} else if req.VideoUploadID != "" {
+		// file lớn đã được upload qua resumable upload session
+		key, err := uc.uploadSessionService.Consume(ctx, req.VideoUploadID, uploadsessionModel.PurposeTopicVideo, topic.Bucket)
+		if err != nil {
+			return err
+		}
+		media := probeUploadedMedia(ctx, uc.prober, uc.s3Service.For(topic.Bucket), nil, key)
+		if err := checkClipRange(clip, media); err != nil {
+			// session đã consume, object mới không còn ai tham chiếu
+			_ = uc.deletionOutbox.Enqueue(ctx, "upload_session_rollback", topic.Bucket, key)
+			return err
+		}
+		uc.quotaService.Record(ctx, topic.Bucket, orgID, quotaModel.CategoryTopic, key, -1)
 		err = uc.topicRepo.SetVideo(ctx, topicID, req.LanguageID, model.TopicVideoConfig{
 			VideoKey:  key,
 			LinkUrl:   req.VideoLinkUrl,
 			StartTime: req.VideoStart,
 			EndTime:   req.VideoEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Media:     media,
 		})
 		if err != nil {
 			return err
 		}
+		enqueueReplaced(ctx, uc.deletionOutbox, "update_topic_video", topic.Bucket, oldVideoKey, key)
 	} else {
 		// cập nhật metadata + key (mới hoặc cũ)
+		media := helper.GetVideoMediaByLanguage(topic, req.LanguageID)
+		if err := checkClipRange(clip, media); err != nil {
+			return err
+		}
 		err := uc.topicRepo.SetVideo(ctx, topicID, req.LanguageID, model.TopicVideoConfig{
 			VideoKey:  oldVideoKey,
 			LinkUrl:   req.VideoLinkUrl,
 			StartTime: req.VideoStart,
 			EndTime:   req.VideoEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Checksum:  helper.GetVideoChecksumByLanguage(topic, req.LanguageID),
+			Media:     media,
 		})
 		if err != nil {
 			return err
@@ -199,7 +359,7 @@ func (uc *uploadTopicUseCase) uploadAndSaveVideo(ctx context.Context, topic *mod
 	return nil
 }
 
-func (uc *uploadTopicUseCase) uploadAndSaveImages(ctx context.Context, topic *model.Topic, req request.UploadTopicRequest) error {
+func (uc *uploadTopicUseCase) uploadAndSaveImages(ctx context.Context, topic *model.Topic, orgID string, req request.UploadTopicRequest) error {
 	topicID := topic.ID.Hex()
 	imageFiles := []struct {
 		file      *multipart.FileHeader
@@ -284,34 +444,61 @@ func (uc *uploadTopicUseCase) uploadAndSaveImages(ctx context.Context, topic *mo
 
 		if helper.IsValidFile(img.file) {
 
-			key := helper.BuildObjectKeyS3("topic_media/image", img.file.Filename, fmt.Sprintf("%s_%s_image", req.Title, img.typ))
+			// form field của ảnh là "<loại ảnh>_file", vd full_background_file
+			expected := req.Checksums[img.typ+"_file"]
+			key, err := objectkey.Build(objectkey.Object{
+				OrganizationID: orgID,
+				Entity:         objectkey.EntityTopic,
+				EntityID:       topicID,
+				LanguageID:     req.LanguageID,
+				Slot:           "image_" + img.typ,
+				FileName:       img.file.Filename,
+				Title:          fmt.Sprintf("%s_%s_image", req.Title, img.typ),
+				Folder:         "topic_media/image",
+			})
+			if err != nil {
+				return err
+			}
 			f, openErr := img.file.Open()
 			if openErr != nil {
 				return openErr
 			}
 			ct := img.file.Header.Get("Content-Type")
-			if _, err := uc.s3Service.SaveReader(ctx, f, key, ct, uploader.UploadPrivate); err != nil {
-				_ = f.Close()
+			_, checksum, err := uc.s3Service.For(topic.Bucket).SaveReaderChecked(ctx, f, key, ct, uploader.UploadPrivate, expected)
+			_ = f.Close()
+			if err != nil {
 				return err
 			}
-			_ = f.Close()
+			uc.quotaService.Record(ctx, topic.Bucket, orgID, quotaModel.CategoryTopic, key, img.file.Size)
+			variants, placeholder := processUploadedImage(ctx, uc.s3Service.For(topic.Bucket), uc.quotaService, orgID, quotaModel.CategoryTopic, img.file, key)
 
 			// Lưu key + metadata mới
 			if err := uc.topicRepo.SetImage(ctx, topicID, req.LanguageID, model.TopicImageConfig{
-				ImageKey:  key,
-				ImageType: img.typ,
-				LinkUrl:   img.link,
+				ImageKey:    key,
+				ImageType:   img.typ,
+				LinkUrl:     img.link,
+				Checksum:    checksum,
+				Variants:    variants,
+				Placeholder: placeholder,
 			}); err != nil {
 				return err
 			}
+			// ảnh cũ của slot (đã xoá ở trên nếu có cờ xóa) chỉ vào outbox sau khi slot trỏ sang ảnh mới
+			if !img.isDeleted {
+				enqueueReplacedImage(ctx, uc.deletionOutbox, "update_topic_image", topic.Bucket,
+					helper.GetImageKeyByLanguageAndType(topic, req.LanguageID, img.typ), helper.GetImageVariantsByLanguageAndType(topic, req.LanguageID, img.typ), key, variants)
+			}
 
 			continue
 		} else {
 			oldKey := helper.GetImageKeyByLanguageAndType(topic, req.LanguageID, img.typ)
 			if err := uc.topicRepo.SetImage(ctx, topicID, req.LanguageID, model.TopicImageConfig{
-				ImageKey:  oldKey,
-				ImageType: img.typ,
-				LinkUrl:   img.link,
+				ImageKey:    oldKey,
+				ImageType:   img.typ,
+				LinkUrl:     img.link,
+				Checksum:    helper.GetImageChecksumByLanguageAndType(topic, req.LanguageID, img.typ),
+				Variants:    helper.GetImageVariantsByLanguageAndType(topic, req.LanguageID, img.typ),
+				Placeholder: helper.GetImagePlaceholderByLanguageAndType(topic, req.LanguageID, img.typ),
 			}); err != nil {
 				// chỉ log warning, không ghi Redis error
 				logger.WriteLogData("[uploadAndSaveImages] Failed to update metadata case2", err)
@@ -323,6 +510,20 @@ func (uc *uploadTopicUseCase) uploadAndSaveImages(ctx context.Context, topic *mo
 }
 
 // ------------------- Topic helpers -------------------
+
+// ResolveOrganizationID trả về organization được tính dung lượng: của topic nếu có, không thì của user hiện tại
+func ResolveOrganizationID(ctx context.Context, topicRepo repository.TopicRepository, topicID string) (string, error) {
+	if topicID != "" {
+		topic, err := topicRepo.GetByID(ctx, topicID)
+		if err != nil {
+			return "", fmt.Errorf("get topic failed: %w", err)
+		}
+		if topic != nil && topic.OrganizationID != "" {
+			return topic.OrganizationID, nil
+		}
+	}
+	return helper.GetCurrentOrganizationID(ctx), nil
+}
 func (uc *uploadTopicUseCase) updateTopicLanguage(ctx context.Context, req request.UploadTopicRequest) (*model.Topic, error) {
 	oldTopic, err := uc.topicRepo.GetByID(ctx, req.TopicID)
 	if err != nil {
@@ -372,11 +573,13 @@ func (uc *uploadTopicUseCase) updateTopicLanguage(ctx context.Context, req reque
 	return uc.topicRepo.UpdateTopic(ctx, oldTopic)
 }
 
-func (uc *uploadTopicUseCase) createTopicLanguage(ctx context.Context, req request.UploadTopicRequest) (*model.Topic, error) {
+func (uc *uploadTopicUseCase) createTopicLanguage(ctx context.Context, orgID string, req request.UploadTopicRequest) (*model.Topic, error) {
 	topic := &model.Topic{
 		ID:             primitive.NewObjectID(),
+		OrganizationID: orgID,
 		IsPublished:    req.IsPublished,
 		LanguageConfig: []model.TopicLanguageConfig{},
+		Bucket:         uc.s3Service.Route(string(quotaModel.CategoryTopic), orgID).Bucket(),
 	}
 
 	newTopic, err := uc.topicRepo.CreateTopic(ctx, topic)
@@ -412,18 +615,14 @@ func (uc *uploadTopicUseCase) deleteImageKeyByLanguageAndType(ctx context.Contex
 	if err != nil {
 		return err
 	}
-	oldKey := helper.GetImageKeyByLanguageAndType(topic, languageID, imageType)
-	if oldKey != "" {
-		err = uc.s3Service.Delete(ctx, oldKey)
-		if err != nil {
-			logger.WriteLogEx("error", "Failed to delete s3 service image", err)
-		}
-	}
 	// goi repo xoa image key
 	err = uc.topicRepo.DeleteImageKey(ctx, topicID, languageID, imageType)
 	if err != nil {
 		return err
 	}
+	keys := imageObjectKeys(helper.GetImageKeyByLanguageAndType(topic, languageID, imageType), helper.GetImageVariantsByLanguageAndType(topic, languageID, imageType))
+	// object được worker xoá sau khi document không còn trỏ tới, lỗi ghi outbox đã được log
+	_ = uc.deletionOutbox.Enqueue(ctx, "delete_topic_image", topic.Bucket, keys...)
 	return nil
 
 }
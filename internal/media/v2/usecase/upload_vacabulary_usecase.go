@@ -10,9 +10,17 @@ import (
 	"media-service/internal/media/model"
 	"media-service/internal/media/v2/dto/request"
 	"media-service/internal/media/v2/repository"
+	outboxService "media-service/internal/outbox/service"
+	quotaModel "media-service/internal/quota/model"
+	quotaService "media-service/internal/quota/service"
 	"media-service/internal/s3"
+	uploadsessionModel "media-service/internal/uploadsession/model"
+	uploadsessionService "media-service/internal/uploadsession/service"
 	"media-service/logger"
+	"media-service/pkg/cliprange"
 	"media-service/pkg/constants"
+	"media-service/pkg/mediaprobe"
+	"media-service/pkg/objectkey"
 	"media-service/pkg/uploader"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -23,16 +31,24 @@ type UploadVocabularyUseCase interface {
 }
 
 type uploadVocabularyUseCase struct {
-	topicRepo      repository.TopicRepository
-	vocabularyRepo repository.VocabularyRepository
-	s3Service      s3.Service
+	topicRepo            repository.TopicRepository
+	vocabularyRepo       repository.VocabularyRepository
+	s3Service            s3.Service
+	uploadSessionService uploadsessionService.UploadSessionService
+	quotaService         quotaService.QuotaService
+	prober               mediaprobe.Prober
+	deletionOutbox       outboxService.DeletionService
 }
 
-func NewUploadVocabularyUseCase(topicRepo repository.TopicRepository, vocabularyRepo repository.VocabularyRepository, s3Svc s3.Service) UploadVocabularyUseCase {
+func NewUploadVocabularyUseCase(topicRepo repository.TopicRepository, vocabularyRepo repository.VocabularyRepository, s3Svc s3.Service, uploadSessionSvc uploadsessionService.UploadSessionService, quotaSvc quotaService.QuotaService, prober mediaprobe.Prober, deletionOutbox outboxService.DeletionService) UploadVocabularyUseCase {
 	return &uploadVocabularyUseCase{
-		topicRepo:      topicRepo,
-		vocabularyRepo: vocabularyRepo,
-		s3Service:      s3Svc,
+		topicRepo:            topicRepo,
+		vocabularyRepo:       vocabularyRepo,
+		s3Service:            s3Svc,
+		uploadSessionService: uploadSessionSvc,
+		quotaService:         quotaSvc,
+		prober:               prober,
+		deletionOutbox:       deletionOutbox,
 	}
 }
 
@@ -42,6 +58,28 @@ func (uc *uploadVocabularyUseCase) UploadVocabulary(ctx context.Context, req req
 	var vocabulary *model.Vocabulary
 	var err error
 
+	// start / end sai định dạng (không phải giây, mm:ss, hh:mm:ss.mmm) → trả lỗi ngay, chưa upload gì
+	audioClip, err := cliprange.Parse(req.AudioStart, req.AudioEnd)
+	if err != nil {
+		return err
+	}
+	videoClip, err := cliprange.Parse(req.VideoStart, req.VideoEnd)
+	if err != nil {
+		return err
+	}
+
+	// vocabulary dùng chung quota với topic chứa nó
+	orgID, err := ResolveOrganizationID(ctx, uc.topicRepo, req.TopicID)
+	if err != nil {
+		return err
+	}
+	requestSize := helper.TotalFileSize(req.AudioFile, req.VideoFile,
+		req.FullBackgroundFile, req.ClearBackgroundFile, req.ClipPartFile, req.DrawingFile,
+		req.IconFile, req.BMFile, req.SignLangFile, req.GifFile, req.OrderFile)
+	if err := uc.quotaService.Check(ctx, orgID, requestSize); err != nil {
+		return err
+	}
+
 	if req.VocabularyID != "" {
 		// Case update existing vocabulary
 		if req.TopicID == "" {
@@ -60,24 +98,24 @@ func (uc *uploadVocabularyUseCase) UploadVocabulary(ctx context.Context, req req
 		}
 	} else {
 		// Case create new vocabulary
-		vocabulary, err = uc.createVocabulary(ctx, req)
+		vocabulary, err = uc.createVocabulary(ctx, orgID, req)
 		if err != nil {
 			return err
 		}
 	}
 
 	// Thực thi upload đồng bộ, không dùng Redis
-	if err := uc.uploadAndSaveAudio(ctx, vocabulary, req); err != nil {
+	if err := uc.uploadAndSaveAudio(ctx, vocabulary, orgID, req, audioClip); err != nil {
 		logger.WriteLogMsg("error", "Failed to upload and save audio")
 		logger.WriteLogEx("error", "Failed to upload and save audio", err)
 		return err
 	}
-	if err := uc.uploadAndSaveVideo(ctx, vocabulary, req); err != nil {
+	if err := uc.uploadAndSaveVideo(ctx, vocabulary, orgID, req, videoClip); err != nil {
 		logger.WriteLogMsg("error", "Failed to upload and save video")
 		logger.WriteLogEx("error", "Failed to upload and save video", err)
 		return err
 	}
-	if err := uc.uploadAndSaveImages(ctx, vocabulary, req); err != nil {
+	if err := uc.uploadAndSaveImages(ctx, vocabulary, orgID, req); err != nil {
 		logger.WriteLogMsg("error", "Failed to upload and save images")
 		logger.WriteLogEx("error", "Failed to upload and save images", err)
 		return err
@@ -86,60 +124,120 @@ func (uc *uploadVocabularyUseCase) UploadVocabulary(ctx context.Context, req req
 }
 
 // ------------------- Upload handlers -------------------
-func (uc *uploadVocabularyUseCase) uploadAndSaveAudio(ctx context.Context, vocabulary *model.Vocabulary, req request.UploadVocabularyRequest) error {
+func (uc *uploadVocabularyUseCase) uploadAndSaveAudio(ctx context.Context, vocabulary *model.Vocabulary, orgID string, req request.UploadVocabularyRequest, clip cliprange.Range) error {
 	vocabularyID := vocabulary.ID.Hex()
 
+	oldAudioKey := helper.GetVocabularyAudioKeyByLanguage(vocabulary, req.LanguageID)
 	if req.IsDeletedAudio {
-		audioKey := helper.GetVocabularyAudioKeyByLanguage(vocabulary, req.LanguageID)
-		if audioKey != "" {
-			_ = uc.s3Service.Delete(ctx, audioKey)
-		}
 		// goi repo xoa audio key
 		if err := uc.vocabularyRepo.DeleteAudioKey(ctx, vocabularyID, req.LanguageID); err != nil {
 			logger.WriteLogData("[Time: "+time.Now().Format("2006-01-02 15:04:05")+"] [uploadAndSaveAudio] Failed to delete audio key", err)
 		}
 		// Nếu không có file mới, giữ trạng thái xóa (key rỗng) và chỉ cập nhật metadata
-		if !helper.IsValidFile(req.AudioFile) {
-			return uc.vocabularyRepo.SetAudio(ctx, vocabularyID, req.LanguageID, model.VocabularyAudioConfig{
+		if !helper.IsValidFile(req.AudioFile) && req.AudioUploadID == "" {
+			if err := uc.vocabularyRepo.SetAudio(ctx, vocabularyID, req.LanguageID, model.VocabularyAudioConfig{
 				AudioKey:  "",
 				LinkUrl:   req.AudioLinkUrl,
 				StartTime: req.AudioStart,
 				EndTime:   req.AudioEnd,
-			})
+				StartMs:   clip.StartMs,
+				EndMs:     clip.EndMs,
+			}); err != nil {
+				return err
+			}
+			enqueueReplaced(ctx, uc.deletionOutbox, "delete_vocabulary_audio", vocabulary.Bucket, oldAudioKey, "")
+			return nil
 		}
 	}
 
-	oldAudioKey := helper.GetVocabularyAudioKeyByLanguage(vocabulary, req.LanguageID)
 	if helper.IsValidFile(req.AudioFile) {
 
-		key := helper.BuildObjectKeyS3("vocabulary_media/audio", req.AudioFile.Filename, fmt.Sprintf("%s_audio", req.Title))
+		expected := req.Checksums["audio_file"]
+		key, err := objectkey.Build(objectkey.Object{
+			OrganizationID: orgID,
+			Entity:         objectkey.EntityVocabulary,
+			EntityID:       vocabularyID,
+			LanguageID:     req.LanguageID,
+			Slot:           "audio",
+			FileName:       req.AudioFile.Filename,
+			Title:          req.Title + "_audio",
+			Folder:         "vocabulary_media/audio",
+		})
+		if err != nil {
+			return err
+		}
+		// probe trước khi lưu: clip vượt quá duration bị từ chối mà không để lại object
+		media := probeUploadedMedia(ctx, uc.prober, uc.s3Service.For(vocabulary.Bucket), req.AudioFile, key)
+		if err := checkClipRange(clip, media); err != nil {
+			return err
+		}
 		f, openErr := req.AudioFile.Open()
 		if openErr != nil {
 			return openErr
 		}
 		defer f.Close()
 		ct := req.AudioFile.Header.Get("Content-Type")
-		_, err := uc.s3Service.SaveReader(ctx, f, key, ct, uploader.UploadPrivate)
+		_, checksum, err := uc.s3Service.For(vocabulary.Bucket).SaveReaderChecked(ctx, f, key, ct, uploader.UploadPrivate, expected)
 		if err != nil {
 			return err
 		}
+		uc.quotaService.Record(ctx, vocabulary.Bucket, orgID, quotaModel.CategoryVocabulary, key, req.AudioFile.Size)
 		// cập nhật metadata + key (mới hoặc cũ)
 		err = uc.vocabularyRepo.SetAudio(ctx, vocabularyID, req.LanguageID, model.VocabularyAudioConfig{
 			AudioKey:  key,
 			LinkUrl:   req.AudioLinkUrl,
 			StartTime: req.AudioStart,
 			EndTime:   req.AudioEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Checksum:  checksum,
+			Media:     media,
 		})
 		if err != nil {
 			return err
 		}
+		enqueueReplaced(ctx, uc.deletionOutbox, "update_vocabulary_audio", vocabulary.Bucket, oldAudioKey, key)
+	} else if req.AudioUploadID != "" {
+		// file lớn đã được upload qua resumable upload session
+		key, err := uc.uploadSessionService.Consume(ctx, req.AudioUploadID, uploadsessionModel.PurposeVocabularyAudio, vocabulary.Bucket)
+		if err != nil {
+			return err
+		}
+		media := probeUploadedMedia(ctx, uc.prober, uc.s3Service.For(vocabulary.Bucket), nil, key)
+		if err := checkClipRange(clip, media); err != nil {
+			// session đã consume, object mới không còn ai tham chiếu
+			_ = uc.deletionOutbox.Enqueue(ctx, "upload_session_rollback", vocabulary.Bucket, key)
+			return err
+		}
+		uc.quotaService.Record(ctx, vocabulary.Bucket, orgID, quotaModel.CategoryVocabulary, key, -1)
+		err = uc.vocabularyRepo.SetAudio(ctx, vocabularyID, req.LanguageID, model.VocabularyAudioConfig{
+			AudioKey:  key,
+			LinkUrl:   req.AudioLinkUrl,
+			StartTime: req.AudioStart,
+			EndTime:   req.AudioEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Media:     media,
+		})
+		if err != nil {
+			return err
+		}
+		enqueueReplaced(ctx, uc.deletionOutbox, "update_vocabulary_audio", vocabulary.Bucket, oldAudioKey, key)
 	} else {
 		// cập nhật metadata + key (mới hoặc cũ)
+		media := helper.GetVocabularyAudioMediaByLanguage(vocabulary, req.LanguageID)
+		if err := checkClipRange(clip, media); err != nil {
+			return err
+		}
 		err := uc.vocabularyRepo.SetAudio(ctx, vocabularyID, req.LanguageID, model.VocabularyAudioConfig{
 			AudioKey:  oldAudioKey,
 			LinkUrl:   req.AudioLinkUrl,
 			StartTime: req.AudioStart,
 			EndTime:   req.AudioEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Checksum:  helper.GetVocabularyAudioChecksumByLanguage(vocabulary, req.LanguageID),
+			Media:     media,
 		})
 		if err != nil {
 			return err
@@ -148,61 +246,123 @@ func (uc *uploadVocabularyUseCase) uploadAndSaveAudio(ctx context.Context, vocab
 	return nil
 }
 
-func (uc *uploadVocabularyUseCase) uploadAndSaveVideo(ctx context.Context, vocabulary *model.Vocabulary, req request.UploadVocabularyRequest) error {
+func (uc *uploadVocabularyUseCase) uploadAndSaveVideo(ctx context.Context, vocabulary *model.Vocabulary, orgID string, req request.UploadVocabularyRequest, clip cliprange.Range) error {
 	vocabularyID := vocabulary.ID.Hex()
 
+	oldVideoKey := helper.GetVocabularyVideoKeyByLanguage(vocabulary, req.LanguageID)
 	if req.IsDeletedVideo {
-		videoKey := helper.GetVocabularyVideoKeyByLanguage(vocabulary, req.LanguageID)
-		if videoKey == "" {
+		if oldVideoKey == "" {
 			return fmt.Errorf("video key not found")
 		}
-		_ = uc.s3Service.Delete(ctx, videoKey)
 
 		// goi repo xoa video key (ignore error -> chi ra log)
 		if err := uc.vocabularyRepo.DeleteVideoKey(ctx, vocabularyID, req.LanguageID); err != nil {
 			logger.WriteLogData("[Time: "+time.Now().Format("2006-01-02 15:04:05")+"] [uploadAndSaveVideo] Failed to delete video key", err)
 		}
 		// Nếu không có file mới, giữ trạng thái xóa (key rỗng) và chỉ cập nhật metadata
-		if !helper.IsValidFile(req.VideoFile) {
-			return uc.vocabularyRepo.SetVideo(ctx, vocabularyID, req.LanguageID, model.VocabularyVideoConfig{
+		if !helper.IsValidFile(req.VideoFile) && req.VideoUploadID == "" {
+			if err := uc.vocabularyRepo.SetVideo(ctx, vocabularyID, req.LanguageID, model.VocabularyVideoConfig{
 				VideoKey:  "",
 				LinkUrl:   req.VideoLinkUrl,
 				StartTime: req.VideoStart,
 				EndTime:   req.VideoEnd,
-			})
+				StartMs:   clip.StartMs,
+				EndMs:     clip.EndMs,
+			}); err != nil {
+				return err
+			}
+			enqueueReplaced(ctx, uc.deletionOutbox, "delete_vocabulary_video", vocabulary.Bucket, oldVideoKey, "")
+			return nil
 		}
 	}
 
-	oldVideoKey := helper.GetVocabularyVideoKeyByLanguage(vocabulary, req.LanguageID)
 	if helper.IsValidFile(req.VideoFile) {
 
-		key := helper.BuildObjectKeyS3("vocabulary_media/video", req.VideoFile.Filename, fmt.Sprintf("%s_video", req.Title))
+		expected := req.Checksums["video_file"]
+		key, err := objectkey.Build(objectkey.Object{
+			OrganizationID: orgID,
+			Entity:         objectkey.EntityVocabulary,
+			EntityID:       vocabularyID,
+			LanguageID:     req.LanguageID,
+			Slot:           "video",
+			FileName:       req.VideoFile.Filename,
+			Title:          req.Title + "_video",
+			Folder:         "vocabulary_media/video",
+		})
+		if err != nil {
+			return err
+		}
+		// probe trước khi lưu: clip vượt quá duration bị từ chối mà không để lại object
+		media := probeUploadedMedia(ctx, uc.prober, uc.s3Service.For(vocabulary.Bucket), req.VideoFile, key)
+		if err := checkClipRange(clip, media); err != nil {
+			return err
+		}
 		f, openErr := req.VideoFile.Open()
 		if openErr != nil {
 			return openErr
 		}
 		defer f.Close()
 		ct := req.VideoFile.Header.Get("Content-Type")
-		_, err := uc.s3Service.SaveReader(ctx, f, key, ct, uploader.UploadPrivate)
+		_, checksum, err := uc.s3Service.For(vocabulary.Bucket).SaveReaderChecked(ctx, f, key, ct, uploader.UploadPrivate, expected)
 		if err != nil {
 			return err
 		}
+		uc.quotaService.Record(ctx, vocabulary.Bucket, orgID, quotaModel.CategoryVocabulary, key, req.VideoFile.Size)
 		err = uc.vocabularyRepo.SetVideo(ctx, vocabularyID, req.LanguageID, model.VocabularyVideoConfig{
 			VideoKey:  key,
 			LinkUrl:   req.VideoLinkUrl,
 			StartTime: req.VideoStart,
 			EndTime:   req.VideoEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Checksum:  checksum,
+			Media:     media,
 		})
 		if err != nil {
 			return err
 		}
+		enqueueReplaced(ctx, uc.deletionOutbox, "update_vocabulary_video", vocabulary.Bucket, oldVideoKey, key)
+	} else if req.VideoUploadID != "" {
+		// file lớn đã được upload qua resumable upload session
+		key, err := uc.uploadSessionService.Consume(ctx, req.VideoUploadID, uploadsessionModel.PurposeVocabularyVideo, vocabulary.Bucket)
+		if err != nil {
+			return err
+		}
+		media := probeUploadedMedia(ctx, uc.prober, uc.s3Service.For(vocabulary.Bucket), nil, key)
+		if err := checkClipRange(clip, media); err != nil {
+			// session đã consume, object mới không còn ai tham chiếu
+			_ = uc.deletionOutbox.Enqueue(ctx, "upload_session_rollback", vocabulary.Bucket, key)
+			return err
+		}
+		uc.quotaService.Record(ctx, vocabulary.Bucket, orgID, quotaModel.CategoryVocabulary, key, -1)
+		err = uc.vocabularyRepo.SetVideo(ctx, vocabularyID, req.LanguageID, model.VocabularyVideoConfig{
+			VideoKey:  key,
+			LinkUrl:   req.VideoLinkUrl,
+			StartTime: req.VideoStart,
+			EndTime:   req.VideoEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Media:     media,
+		})
+		if err != nil {
+			return err
+		}
+		enqueueReplaced(ctx, uc.deletionOutbox, "update_vocabulary_video", vocabulary.Bucket, oldVideoKey, key)
 	} else {
 		// cập nhật metadata + key (mới hoặc cũ)
+		media := helper.GetVocabularyVideoMediaByLanguage(vocabulary, req.LanguageID)
+		if err := checkClipRange(clip, media); err != nil {
+			return err
+		}
 		err := uc.vocabularyRepo.SetVideo(ctx, vocabularyID, req.LanguageID, model.VocabularyVideoConfig{
 			VideoKey:  oldVideoKey,
 			LinkUrl:   req.VideoLinkUrl,
 			StartTime: req.VideoStart,
 			EndTime:   req.VideoEnd,
+			StartMs:   clip.StartMs,
+			EndMs:     clip.EndMs,
+			Checksum:  helper.GetVocabularyVideoChecksumByLanguage(vocabulary, req.LanguageID),
+			Media:     media,
 		})
 		if err != nil {
 			return err
@@ -211,7 +371,7 @@ func (uc *uploadVocabularyUseCase) uploadAndSaveVideo(ctx context.Context, vocab
 	return nil
 }
 
-func (uc *uploadVocabularyUseCase) uploadAndSaveImages(ctx context.Context, vocabulary *model.Vocabulary, req request.UploadVocabularyRequest) error {
+func (uc *uploadVocabularyUseCase) uploadAndSaveImages(ctx context.Context, vocabulary *model.Vocabulary, orgID string, req request.UploadVocabularyRequest) error {
 	vocabularyID := vocabulary.ID.Hex()
 	imageFiles := []struct {
 		file      *multipart.FileHeader
@@ -296,34 +456,61 @@ func (uc *uploadVocabularyUseCase) uploadAndSaveImages(ctx context.Context, voca
 
 		if helper.IsValidFile(img.file) {
 
-			key := helper.BuildObjectKeyS3("vocabulary_media/image", img.file.Filename, fmt.Sprintf("%s_%s_image", req.Title, img.typ))
+			// form field của ảnh là "<loại ảnh>_file", vd full_background_file
+			expected := req.Checksums[img.typ+"_file"]
+			key, err := objectkey.Build(objectkey.Object{
+				OrganizationID: orgID,
+				Entity:         objectkey.EntityVocabulary,
+				EntityID:       vocabularyID,
+				LanguageID:     req.LanguageID,
+				Slot:           "image_" + img.typ,
+				FileName:       img.file.Filename,
+				Title:          fmt.Sprintf("%s_%s_image", req.Title, img.typ),
+				Folder:         "vocabulary_media/image",
+			})
+			if err != nil {
+				return err
+			}
 			f, openErr := img.file.Open()
 			if openErr != nil {
 				return openErr
 			}
 			ct := img.file.Header.Get("Content-Type")
-			if _, err := uc.s3Service.SaveReader(ctx, f, key, ct, uploader.UploadPrivate); err != nil {
-				_ = f.Close()
+			_, checksum, err := uc.s3Service.For(vocabulary.Bucket).SaveReaderChecked(ctx, f, key, ct, uploader.UploadPrivate, expected)
+			_ = f.Close()
+			if err != nil {
 				return err
 			}
-			_ = f.Close()
+			uc.quotaService.Record(ctx, vocabulary.Bucket, orgID, quotaModel.CategoryVocabulary, key, img.file.Size)
+			variants, placeholder := processUploadedImage(ctx, uc.s3Service.For(vocabulary.Bucket), uc.quotaService, orgID, quotaModel.CategoryVocabulary, img.file, key)
 
 			// Lưu key + metadata mới
 			if err := uc.vocabularyRepo.SetImage(ctx, vocabularyID, req.LanguageID, model.VocabularyImageConfig{
-				ImageKey:  key,
-				ImageType: img.typ,
-				LinkUrl:   img.link,
+				ImageKey:    key,
+				ImageType:   img.typ,
+				LinkUrl:     img.link,
+				Checksum:    checksum,
+				Variants:    variants,
+				Placeholder: placeholder,
 			}); err != nil {
 				return err
 			}
+			// ảnh cũ của slot (đã xoá ở trên nếu có cờ xóa) chỉ vào outbox sau khi slot trỏ sang ảnh mới
+			if !img.isDeleted {
+				enqueueReplacedImage(ctx, uc.deletionOutbox, "update_vocabulary_image", vocabulary.Bucket,
+					helper.GetVocabularyImageKeyByLanguageAndType(vocabulary, req.LanguageID, img.typ), helper.GetVocabularyImageVariantsByLanguageAndType(vocabulary, req.LanguageID, img.typ), key, variants)
+			}
 
 			continue
 		} else {
 			oldKey := helper.GetVocabularyImageKeyByLanguageAndType(vocabulary, req.LanguageID, img.typ)
 			if err := uc.vocabularyRepo.SetImage(ctx, vocabularyID, req.LanguageID, model.VocabularyImageConfig{
-				ImageKey:  oldKey,
-				ImageType: img.typ,
-				LinkUrl:   img.link,
+				ImageKey:    oldKey,
+				ImageType:   img.typ,
+				LinkUrl:     img.link,
+				Checksum:    helper.GetVocabularyImageChecksumByLanguageAndType(vocabulary, req.LanguageID, img.typ),
+				Variants:    helper.GetVocabularyImageVariantsByLanguageAndType(vocabulary, req.LanguageID, img.typ),
+				Placeholder: helper.GetVocabularyImagePlaceholderByLanguageAndType(vocabulary, req.LanguageID, img.typ),
 			}); err != nil {
 				// chỉ log warning, không ghi Redis error
 				logger.WriteLogData("[uploadAndSaveImages] Failed to update metadata case2", err)
@@ -378,12 +565,13 @@ func (uc *uploadVocabularyUseCase) updateVocabulary(ctx context.Context, req req
 	return uc.vocabularyRepo.UpdateVocabulary(ctx, oldVocabulary)
 }
 
-func (uc *uploadVocabularyUseCase) createVocabulary(ctx context.Context, req request.UploadVocabularyRequest) (*model.Vocabulary, error) {
+func (uc *uploadVocabularyUseCase) createVocabulary(ctx context.Context, orgID string, req request.UploadVocabularyRequest) (*model.Vocabulary, error) {
 	vocabulary := &model.Vocabulary{
 		ID:             primitive.NewObjectID(),
 		TopicID:        req.TopicID,
 		IsPublished:    req.IsPublished,
 		LanguageConfig: []model.VocabularyLanguageConfig{},
+		Bucket:         uc.s3Service.Route(string(quotaModel.CategoryVocabulary), orgID).Bucket(),
 	}
 
 	newVocabulary, err := uc.vocabularyRepo.CreateVocabulary(ctx, vocabulary)
@@ -418,18 +606,14 @@ func (uc *uploadVocabularyUseCase) deleteImageKeyByLanguageAndType(ctx context.C
 	if err != nil {
 		return err
 	}
-	oldKey := helper.GetVocabularyImageKeyByLanguageAndType(vocabulary, languageID, imageType)
-	if oldKey != "" {
-		err = uc.s3Service.Delete(ctx, oldKey)
-		if err != nil {
-			logger.WriteLogEx("error", "Failed to delete s3 service image", err)
-		}
-	}
 	// goi repo xoa image key
 	err = uc.vocabularyRepo.DeleteImageKey(ctx, vocabularyID, languageID, imageType)
 	if err != nil {
 		return err
 	}
+	keys := imageObjectKeys(helper.GetVocabularyImageKeyByLanguageAndType(vocabulary, languageID, imageType), helper.GetVocabularyImageVariantsByLanguageAndType(vocabulary, languageID, imageType))
+	// object được worker xoá sau khi document không còn trỏ tới, lỗi ghi outbox đã được log
+	_ = uc.deletionOutbox.Enqueue(ctx, "delete_vocabulary_image", vocabulary.Bucket, keys...)
 	return nil
 
 }
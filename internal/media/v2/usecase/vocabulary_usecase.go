@@ -40,9 +40,10 @@ func (uc *vocabularyUseCase) GetVocabulariesByTopicID4App(ctx context.Context, t
 			for ii := range langCfg.Images {
 				img := &langCfg.Images[ii]
 				if img.ImageKey != "" {
-					url, err := uc.s3Service.Get(ctx, img.ImageKey, nil)
-					if err == nil && url != nil {
-						img.UploadedUrl = *url
+					signImageVariants(ctx, uc.s3Service.For(vocabularies[vi].Bucket), img.Variants)
+					signed, err := uc.s3Service.For(vocabularies[vi].Bucket).Sign(ctx, img.ImageKey, nil)
+					if err == nil {
+						img.UploadedUrl, img.UploadedUrlExpiresAt = signed.URL, signed.ExpiresAt
 					}
 				}
 			}
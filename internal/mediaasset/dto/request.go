@@ -1,8 +1,16 @@
 package dto
 
+// UploadRequest: organization (scope dedup) luôn lấy từ user đã xác thực, không nhận từ form
 type UploadRequest struct {
 	Folder    string  `form:"folder"`
 	Mode      string  `form:"mode"`       // private | public
 	MediaType *string `form:"media_type"` // optional override: image|audio|video|pdf
 	File      any     `form:"file" binding:"required"`
 }
+
+// SignURLsRequest ký lại nhiều url trong một lần gọi, có thể trộn key và asset id
+type SignURLsRequest struct {
+	Keys       []string `json:"keys"` // key của media asset, key không thuộc asset nào bị từ chối
+	IDs        []string `json:"ids"`
+	TTLSeconds int      `json:"ttl_seconds"` // 0 = mặc định, bị giới hạn bởi storage.signed_url_max_ttl_hours
+}
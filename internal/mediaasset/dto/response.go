@@ -1,7 +1,23 @@
 package dto
 
+import "time"
+
 type UploadResponse struct {
-	ID  string  `json:"id"`
-	Key string  `json:"key"`
-	URL *string `json:"url,omitempty"`
+	ID        string     `json:"id"`
+	Key       string     `json:"key"`
+	URL       *string    `json:"url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // nil = url không hết hạn
+}
+
+// SignedURLItem là kết quả ký lại của một key / asset id, Error khác rỗng khi item đó thất bại
+type SignedURLItem struct {
+	ID        string     `json:"id,omitempty"`
+	Key       string     `json:"key,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+type SignURLsResponse struct {
+	Items []SignedURLItem `json:"items"`
 }
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"media-service/helper"
 	"media-service/internal/mediaasset/dto"
+	"media-service/internal/mediaasset/repository"
 	"media-service/internal/mediaasset/service"
+	"media-service/pkg/uploader"
 	"net/http"
 	"time"
 
@@ -32,6 +35,10 @@ func (h *MediaHandler) Upload(c *fiber.Ctx) error {
 	if err != nil {
 		return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
 	}
+	checksums, err := helper.FormChecksums(c)
+	if err != nil {
+		return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+	}
 
 	opened, err := fileHeader.Open()
 	if err != nil {
@@ -39,15 +46,17 @@ func (h *MediaHandler) Upload(c *fiber.Ctx) error {
 	}
 	_ = opened.Close()
 
-	meta, url, err := h.svc.Upload(c.UserContext(), fileHeader, folder, mode, mtPtr)
+	meta, url, err := h.svc.Upload(c.UserContext(), fileHeader, folder, mode, mtPtr, checksums["file"])
 	if err != nil {
-		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		status, code := helper.MapError(err, http.StatusInternalServerError, helper.ErrInvalidOperation)
+		return helper.SendError(c, status, err, code)
 	}
 
 	return helper.SendSuccess(c, http.StatusOK, "upload success", dto.UploadResponse{
-		ID:  meta.ID.Hex(),
-		Key: meta.Key,
-		URL: url,
+		ID:        meta.ID.Hex(),
+		Key:       meta.Key,
+		URL:       &url.URL,
+		ExpiresAt: url.ExpiresAt,
 	})
 }
 
@@ -64,10 +73,13 @@ func (h *MediaHandler) GetURL(c *fiber.Ctx) error {
 		}
 	}
 	url, err := h.svc.GetURL(c.UserContext(), id, duration)
+	if errors.Is(err, service.ErrAccessDenied) {
+		return helper.SendError(c, http.StatusForbidden, err, helper.ErrInvalidOperation)
+	}
 	if err != nil {
 		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 	}
-	return helper.SendSuccess(c, http.StatusOK, "ok", fiber.Map{"url": url})
+	return helper.SendSuccess(c, http.StatusOK, "ok", url)
 }
 
 func (h *MediaHandler) GetMeta(c *fiber.Ctx) error {
@@ -76,6 +88,9 @@ func (h *MediaHandler) GetMeta(c *fiber.Ctx) error {
 		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
 	}
 	meta, err := h.svc.GetMeta(c.UserContext(), id)
+	if errors.Is(err, service.ErrAccessDenied) {
+		return helper.SendError(c, http.StatusForbidden, err, helper.ErrInvalidOperation)
+	}
 	if err != nil {
 		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 	}
@@ -95,10 +110,34 @@ func (h *MediaHandler) GetURLByKey(c *fiber.Ctx) error {
 		}
 	}
 	url, err := h.svc.GetURLByKey(c.UserContext(), key, duration)
+	if errors.Is(err, service.ErrAccessDenied) {
+		return helper.SendError(c, http.StatusForbidden, err, helper.ErrInvalidOperation)
+	}
 	if err != nil {
 		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 	}
-	return helper.SendSuccess(c, http.StatusOK, "ok", fiber.Map{"url": url})
+	return helper.SendSuccess(c, http.StatusOK, "ok", url)
+}
+
+// SignURLs ký lại url cho nhiều key / asset id một lần, dùng khi client cần làm mới url sắp hết hạn
+func (h *MediaHandler) SignURLs(c *fiber.Ctx) error {
+	var req dto.SignURLsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+	}
+	if req.TTLSeconds < 0 {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("ttl_seconds must not be negative"), helper.ErrInvalidRequest)
+	}
+	var duration *time.Duration
+	if req.TTLSeconds > 0 {
+		d := time.Duration(req.TTLSeconds) * time.Second
+		duration = &d
+	}
+	items, err := h.svc.SignBatch(c.UserContext(), req.Keys, req.IDs, duration)
+	if err != nil {
+		return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "ok", dto.SignURLsResponse{Items: items})
 }
 
 func (h *MediaHandler) Delete(c *fiber.Ctx) error {
@@ -107,7 +146,26 @@ func (h *MediaHandler) Delete(c *fiber.Ctx) error {
 		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
 	}
 	if err := h.svc.Delete(c.UserContext(), id); err != nil {
+		if errors.Is(err, service.ErrAccessDenied) || errors.Is(err, repository.ErrNotReferenced) {
+			return helper.SendError(c, http.StatusForbidden, err, helper.ErrInvalidOperation)
+		}
 		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 	}
 	return helper.SendSuccess(c, http.StatusOK, "deleted", nil)
 }
+
+// GetContent stream nội dung asset qua service (hỗ trợ Range / If-None-Match), không lộ signed url
+func (h *MediaHandler) GetContent(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+	}
+	obj, err := h.svc.OpenContent(c.UserContext(), id, uploader.GetObjectOptions{
+		Range:       c.Get(fiber.HeaderRange),
+		IfNoneMatch: c.Get(fiber.HeaderIfNoneMatch),
+	})
+	if errors.Is(err, service.ErrAccessDenied) {
+		return helper.SendError(c, http.StatusForbidden, err, helper.ErrInvalidOperation)
+	}
+	return helper.SendObject(c, obj, err)
+}
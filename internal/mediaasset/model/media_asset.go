@@ -3,6 +3,8 @@ package model
 import (
 	"time"
 
+	"media-service/pkg/uploader"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -16,10 +18,14 @@ const (
 	MediaOther MediaType = "other"
 )
 
+// LegacyRefOwner giữ tham chiếu của asset cũ không rõ người upload, chỉ super admin trả được
+const LegacyRefOwner = "_legacy"
+
 type MediaAsset struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Type        MediaType          `bson:"type" json:"type"`
 	Key         string             `bson:"key" json:"key"`
+	Bucket      string             `bson:"bucket,omitempty" json:"bucket,omitempty"`
 	FileName    string             `bson:"file_name" json:"file_name"`
 	ContentType string             `bson:"content_type" json:"content_type"`
 	Size        int64              `bson:"size" json:"size"`
@@ -27,4 +33,16 @@ type MediaAsset struct {
 	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
 	CreatedBy   *string            `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	Checksum    *uploader.Checksum `bson:"checksum,omitempty" json:"checksum,omitempty"`
+
+	// dedup theo nội dung trong cùng organization
+	OrganizationID string `bson:"organization_id,omitempty" json:"organization_id,omitempty"`
+	SHA256         string `bson:"sha256,omitempty" json:"sha256,omitempty"`
+	RefCount       int    `bson:"ref_count,omitempty" json:"ref_count"` // số lần upload đang dùng chung object, 0 = asset cũ (1 tham chiếu)
+	// số tham chiếu của từng user đã upload (tổng = RefCount), user chỉ trả được tham chiếu của chính mình
+	Refs map[string]int `bson:"refs,omitempty" json:"-"`
+
+	// xoá mềm, document vẫn nằm trong thùng rác cho tới khi bị purge (xem internal/trash)
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
 }
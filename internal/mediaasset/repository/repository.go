@@ -2,19 +2,37 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"media-service/internal/mediaasset/model"
+	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrNotReferenced: user không giữ tham chiếu nào tới asset nên không được trả tham chiếu
+var ErrNotReferenced = errors.New("media asset is not referenced by current user")
+
 type MediaRepository interface {
 	Create(ctx context.Context, media *model.MediaAsset) (primitive.ObjectID, error)
 	GetByID(ctx context.Context, id primitive.ObjectID) (*model.MediaAsset, error)
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.MediaAsset, error)
 	DeleteByID(ctx context.Context, id primitive.ObjectID) error
 	UpdateFields(ctx context.Context, id primitive.ObjectID, update bson.M) error
 	FindByKey(ctx context.Context, key string) (*model.MediaAsset, error)
+	FindByKeys(ctx context.Context, keys []string) ([]*model.MediaAsset, error)
+
+	FindBySHA256(ctx context.Context, organizationID, mode, sha256 string) (*model.MediaAsset, error)
+	// AddRef thêm một tham chiếu của owner, trả về nil nếu asset vừa bị xoá
+	AddRef(ctx context.Context, id primitive.ObjectID, owner string) (*model.MediaAsset, error)
+	// ReleaseRef trả một tham chiếu của owner, xoá mềm document khi không còn tham chiếu; trả về true nếu document đã vào thùng rác.
+	// Owner không giữ tham chiếu nào → ErrNotReferenced.
+	ReleaseRef(ctx context.Context, id primitive.ObjectID, owner, deletedBy string) (bool, error)
+	EnsureIndexes(ctx context.Context) error
 }
 
 type mediaRepository struct {
@@ -38,7 +56,7 @@ func (r *mediaRepository) Create(ctx context.Context, media *model.MediaAsset) (
 
 func (r *mediaRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*model.MediaAsset, error) {
 	var out model.MediaAsset
-	if err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&out); err != nil {
+	if err := r.col.FindOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}}).Decode(&out); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
 		}
@@ -47,6 +65,19 @@ func (r *mediaRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*
 	return &out, nil
 }
 
+func (r *mediaRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.MediaAsset, error) {
+	cur, err := r.col.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "deleted_at": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []*model.MediaAsset
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (r *mediaRepository) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.col.DeleteOne(ctx, bson.M{"_id": id})
 	return err
@@ -59,7 +90,53 @@ func (r *mediaRepository) UpdateFields(ctx context.Context, id primitive.ObjectI
 
 func (r *mediaRepository) FindByKey(ctx context.Context, key string) (*model.MediaAsset, error) {
 	var out model.MediaAsset
-	if err := r.col.FindOne(ctx, bson.M{"key": key}).Decode(&out); err != nil {
+	if err := r.col.FindOne(ctx, bson.M{"key": key, "deleted_at": bson.M{"$exists": false}}).Decode(&out); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *mediaRepository) FindByKeys(ctx context.Context, keys []string) ([]*model.MediaAsset, error) {
+	cur, err := r.col.Find(ctx, bson.M{"key": bson.M{"$in": keys}, "deleted_at": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []*model.MediaAsset
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *mediaRepository) FindBySHA256(ctx context.Context, organizationID, mode, sha256 string) (*model.MediaAsset, error) {
+	var out model.MediaAsset
+	filter := bson.M{"organization_id": organizationID, "mode": mode, "sha256": sha256, "deleted_at": bson.M{"$exists": false}}
+	if err := r.col.FindOne(ctx, filter).Decode(&out); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *mediaRepository) AddRef(ctx context.Context, id primitive.ObjectID, owner string) (*model.MediaAsset, error) {
+	field, err := refField(owner)
+	if err != nil {
+		return nil, err
+	}
+	update := bson.M{
+		"$inc": bson.M{"ref_count": 1, field: 1},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var out model.MediaAsset
+	if err := r.col.FindOneAndUpdate(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}}, update, opts).Decode(&out); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
 		}
@@ -67,3 +144,98 @@ func (r *mediaRepository) FindByKey(ctx context.Context, key string) (*model.Med
 	}
 	return &out, nil
 }
+
+func (r *mediaRepository) ReleaseRef(ctx context.Context, id primitive.ObjectID, owner, deletedBy string) (bool, error) {
+	field, err := refField(owner)
+	if err != nil {
+		return false, err
+	}
+	held := bson.M{"$gt": 0}
+
+	// lặp lại khi có upload trùng chen vào giữa lúc giảm và lúc xoá
+	for {
+		res, err := r.col.UpdateOne(ctx,
+			bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}, "ref_count": bson.M{"$gt": 1}, field: held},
+			bson.M{"$inc": bson.M{"ref_count": -1, field: -1}, "$set": bson.M{"updated_at": time.Now()}},
+		)
+		if err != nil {
+			return false, err
+		}
+		if res.ModifiedCount > 0 {
+			return false, nil
+		}
+
+		// bỏ sha256 để unique index không chặn upload mới cùng nội dung; asset được khôi phục sẽ không còn được dedup
+		trashed, err := r.col.UpdateOne(ctx,
+			bson.M{
+				"_id":        id,
+				"deleted_at": bson.M{"$exists": false},
+				field:        held,
+				"$or": []bson.M{
+					{"ref_count": bson.M{"$lte": 1}},
+					{"ref_count": bson.M{"$exists": false}},
+				},
+			},
+			bson.M{
+				"$set":   bson.M{"deleted_at": time.Now(), "deleted_by": deletedBy},
+				"$unset": bson.M{"sha256": ""},
+			},
+		)
+		if err != nil {
+			return false, err
+		}
+		if trashed.ModifiedCount > 0 {
+			return true, nil
+		}
+
+		exists, err := r.col.CountDocuments(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}})
+		if err != nil {
+			return false, err
+		}
+		if exists == 0 {
+			return false, nil
+		}
+		stillHeld, err := r.col.CountDocuments(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}, field: held})
+		if err != nil {
+			return false, err
+		}
+		if stillHeld == 0 {
+			return false, ErrNotReferenced
+		}
+	}
+}
+
+// refField là đường dẫn tới số tham chiếu của owner trong refs
+func refField(owner string) (string, error) {
+	if owner == "" || strings.ContainsAny(owner, ".$") {
+		return "", fmt.Errorf("invalid media asset owner %q", owner)
+	}
+	return "refs." + owner, nil
+}
+
+// EnsureIndexes tạo unique index (organization_id, mode, sha256) để hai upload trùng đồng thời không tạo hai asset,
+// đồng thời gán ref_count của asset cũ (chưa có refs) cho người upload hoặc model.LegacyRefOwner
+func (r *mediaRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "mode", Value: 1}, {Key: "sha256", Value: 1}},
+		Options: options.Index().
+			SetName("organization_id_mode_sha256").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"sha256": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.col.UpdateMany(ctx,
+		bson.M{"refs": bson.M{"$exists": false}, "deleted_at": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"refs": bson.M{"$arrayToObject": bson.A{bson.A{bson.M{
+				"k": bson.M{"$ifNull": bson.A{"$created_by", model.LegacyRefOwner}},
+				"v": bson.M{"$max": bson.A{"$ref_count", 1}},
+			}}}},
+			"ref_count": bson.M{"$max": bson.A{"$ref_count", 1}},
+		}}}},
+	)
+	return err
+}
@@ -1,17 +1,21 @@
 package route
 
 import (
+	"media-service/internal/gateway"
 	"media-service/internal/mediaasset/handler"
+	"media-service/internal/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func RegisterMediaRoutes(app *fiber.App, h *handler.MediaHandler) {
+func RegisterMediaRoutes(app *fiber.App, h *handler.MediaHandler, userGw gateway.UserGateway) {
 	v2 := app.Group("/v2/media")
 	
-	v2.Post("/upload", h.Upload)
-	v2.Get("/:id/url", h.GetURL)
-	v2.Get("/:id", h.GetMeta)
-	v2.Delete("/:id", h.Delete)
-	v2.Get("/url", h.GetURLByKey)
+	v2.Post("/upload", middleware.Secured(userGw), h.Upload)
+	v2.Post("/urls", middleware.Secured(userGw), h.SignURLs)
+	v2.Get("/url", middleware.Secured(userGw), h.GetURLByKey)
+	v2.Get("/:id/url", middleware.Secured(userGw), h.GetURL)
+	v2.Get("/:id/content", middleware.Secured(userGw), h.GetContent)
+	v2.Get("/:id", middleware.Secured(userGw), h.GetMeta)
+	v2.Delete("/:id", middleware.Secured(userGw), h.Delete)
 }
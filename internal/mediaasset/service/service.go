@@ -1,9 +1,13 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -12,35 +16,75 @@ import (
 	"strings"
 	"time"
 
+	"media-service/helper"
+	"media-service/internal/mediaasset/dto"
 	"media-service/internal/mediaasset/model"
 	"media-service/internal/mediaasset/repository"
+	outboxService "media-service/internal/outbox/service"
+	quotaModel "media-service/internal/quota/model"
+	quotaService "media-service/internal/quota/service"
 	"media-service/internal/s3"
+	trashService "media-service/internal/trash/service"
+	"media-service/pkg/config"
+	"media-service/pkg/imagevariant"
 	"media-service/pkg/uploader"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// ErrAccessDenied: user hiện tại không thuộc organization của asset và không phải người upload
+var ErrAccessDenied = errors.New("access denied")
+
 type MediaService interface {
-	Upload(ctx context.Context, fileHeader *multipart.FileHeader, folder, mode string, mediaType *string) (*model.MediaAsset, *string, error)
-	GetURL(ctx context.Context, id string, duration *time.Duration) (*string, error)
+	// Upload dùng lại asset đã có nếu organization của user hiện tại đã upload file giống hệt (sha256) cùng mode.
+	// expected != nil thì nội dung file phải khớp checksum client gửi
+	Upload(ctx context.Context, fileHeader *multipart.FileHeader, folder, mode string, mediaType *string, expected *uploader.Checksum) (*model.MediaAsset, *s3.SignedURL, error)
+	// Register ghi nhận một object đã nằm sẵn trên bucket (presigned upload) thành MediaAsset
+	Register(ctx context.Context, key, bucket, fileName, contentType string, size int64, mode string, createdBy string) (*model.MediaAsset, error)
+	GetURL(ctx context.Context, id string, duration *time.Duration) (*s3.SignedURL, error)
 	GetMeta(ctx context.Context, id string) (*model.MediaAsset, error)
-	GetURLByKey(ctx context.Context, key string, duration *time.Duration) (*string, error)
+	// GetURLByKey ký url cho key của một asset, cùng quy tắc truy cập với GetURL
+	GetURLByKey(ctx context.Context, key string, duration *time.Duration) (*s3.SignedURL, error)
+	// SignBatch ký lại url cho nhiều key / asset id mà user hiện tại được xem (key phải thuộc một asset),
+	// lỗi của từng item nằm trong kết quả thay vì làm hỏng cả batch
+	SignBatch(ctx context.Context, keys, ids []string, duration *time.Duration) ([]dto.SignedURLItem, error)
+	// Delete trả tham chiếu của user hiện tại, object chỉ vào thùng rác khi không còn ai tham chiếu
 	Delete(ctx context.Context, id string) error
+	// OpenContent mở object của asset để stream qua service, chỉ cho user cùng organization / người upload
+	OpenContent(ctx context.Context, id string, opts uploader.GetObjectOptions) (*uploader.ObjectReader, error)
 }
 
+const (
+	defaultMaxURLTTLHours = 7 * 24
+	maxSignBatchSize      = 100
+)
+
 type mediaService struct {
-	repo repository.MediaRepository
-	s3   s3.Service
+	repo           repository.MediaRepository
+	s3             s3.Service
+	deletionOutbox outboxService.DeletionService
+	trash          trashService.TrashService
+	quota          quotaService.QuotaService
+	maxURLTTL      time.Duration
 }
 
-func NewMediaService(repo repository.MediaRepository) MediaService {
+func NewMediaService(repo repository.MediaRepository, deletionOutbox outboxService.DeletionService, trash trashService.TrashService, quota quotaService.QuotaService) MediaService {
+	maxTTLHours := config.AppConfig.Storage.SignedURLMaxTTLHours
+	if maxTTLHours <= 0 {
+		maxTTLHours = defaultMaxURLTTLHours
+	}
 	return &mediaService{
-		repo: repo,
-		s3:   s3.NewFromConfig(),
+		repo:           repo,
+		s3:             s3.NewFromConfig(),
+		deletionOutbox: deletionOutbox,
+		trash:          trash,
+		quota:          quota,
+		maxURLTTL:      time.Duration(maxTTLHours) * time.Hour,
 	}
 }
 
-func (s *mediaService) Upload(ctx context.Context, fileHeader *multipart.FileHeader, folder, mode string, mediaType *string) (*model.MediaAsset, *string, error) {
+func (s *mediaService) Upload(ctx context.Context, fileHeader *multipart.FileHeader, folder, mode string, mediaType *string, expected *uploader.Checksum) (*model.MediaAsset, *s3.SignedURL, error) {
 	if fileHeader == nil {
 		return nil, nil, fmt.Errorf("file is required")
 	}
@@ -51,6 +95,12 @@ func (s *mediaService) Upload(ctx context.Context, fileHeader *multipart.FileHea
 	if err != nil {
 		upMode = uploader.UploadPrivate
 	}
+	userID := helper.GetUserID(ctx)
+	if userID == "" {
+		return nil, nil, ErrAccessDenied
+	}
+	// scope dedup luôn lấy từ user đã xác thực, không nhận từ form
+	organizationID := helper.GetCurrentOrganizationID(ctx)
 
 	file, err := fileHeader.Open()
 	if err != nil {
@@ -58,40 +108,168 @@ func (s *mediaService) Upload(ctx context.Context, fileHeader *multipart.FileHea
 	}
 	defer file.Close()
 
-	data, err := io.ReadAll(file)
-	if err != nil {
+	// đọc 1 lượt để lấy sha256 + 512 byte đầu cho content type, sau đó seek lại để upload
+	hasher := sha256.New()
+	head := &headBuffer{limit: 512}
+	writers := []io.Writer{hasher, head}
+	var verifier *uploader.ChecksumHasher
+	if expected != nil {
+		verifier = uploader.NewChecksumHasher(expected.Algorithm)
+		writers = append(writers, verifier)
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), file); err != nil {
 		return nil, nil, err
 	}
+	digest := hasher.Sum(nil)
+	sum := hex.EncodeToString(digest)
 
-	key := s.buildObjectKey(folder, fileHeader.Filename)
-	url, err := s.s3.Save(ctx, data, key, upMode)
-	if err != nil {
+	// file hỏng không được dùng lại asset cũ lẫn upload mới
+	if verifier != nil {
+		if err := expected.Verify(verifier.Checksum()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// cùng nội dung, cùng mode trong cùng organization → dùng lại object đã có
+	if existing, err := s.reuseExisting(ctx, organizationID, modeName(upMode), sum, userID); err != nil {
+		return nil, nil, err
+	} else if existing != nil {
+		signed, err := s.s3.For(existing.Bucket).Sign(ctx, existing.Key, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return existing, signed, nil
+	}
+
+	// file trùng không tốn thêm dung lượng nên chỉ kiểm tra quota khi thực sự upload
+	if err := s.quota.Check(ctx, organizationID, fileHeader.Size); err != nil {
 		return nil, nil, err
 	}
 
-	ct := http.DetectContentType(data)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	ct := http.DetectContentType(head.buf)
+	key := s.buildObjectKey(folder, fileHeader.Filename)
+	if upMode == uploader.UploadPublic {
+		key = uploader.PublicKey(key)
+	}
 	mt := detectMediaType(ct, mediaType)
 
+	var body io.Reader = file
+	size := fileHeader.Size
+	expectedStored := &uploader.Checksum{
+		Algorithm: uploader.ChecksumSHA256,
+		Value:     base64.StdEncoding.EncodeToString(digest),
+	}
+	if mt == model.MediaImage {
+		// ảnh được lưu đã xoay theo EXIF và không còn metadata; sha256 vẫn là của file gốc để dedup
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		normalized, changed, err := imagevariant.Sanitize(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if changed {
+			body, size, expectedStored = bytes.NewReader(normalized), int64(len(normalized)), nil
+		} else {
+			body = bytes.NewReader(data)
+		}
+	}
+
+	storage := s.s3.Route(string(quotaModel.CategoryMediaAsset), organizationID)
+	_, checksum, err := storage.SaveReaderChecked(ctx, body, key, ct, upMode, expectedStored)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	now := time.Now()
 	doc := &model.MediaAsset{
-		ID:          primitive.NewObjectID(),
-		Type:        mt,
-		Key:         key,
-		FileName:    fileHeader.Filename,
-		ContentType: ct,
-		Size:        fileHeader.Size,
-		Mode:        strings.ToLower(mode),
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:             primitive.NewObjectID(),
+		Type:           mt,
+		Key:            key,
+		Bucket:         storage.Bucket(),
+		FileName:       fileHeader.Filename,
+		ContentType:    ct,
+		Size:           size,
+		Mode:           modeName(upMode),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		CreatedBy:      &userID,
+		OrganizationID: organizationID,
+		Checksum:       checksum,
+		RefCount:       1,
+		Refs:           map[string]int{userID: 1},
+	}
+	// user không thuộc organization nào thì không dedup: scope rỗng sẽ dùng chung giữa mọi user như vậy
+	if organizationID != "" {
+		doc.SHA256 = sum
 	}
 	_, err = s.repo.Create(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		// upload trùng chạy song song đã tạo asset trước → bỏ object vừa upload
+		_ = s.deletionOutbox.Enqueue(ctx, "dedup_media_asset", storage.Bucket(), key)
+		existing, err := s.reuseExisting(ctx, organizationID, modeName(upMode), sum, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if existing == nil {
+			return nil, nil, fmt.Errorf("media asset was deleted concurrently, please retry")
+		}
+		signed, err := s.s3.For(existing.Bucket).Sign(ctx, existing.Key, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return existing, signed, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	s.quota.Record(ctx, storage.Bucket(), organizationID, quotaModel.CategoryMediaAsset, key, size)
+	signed, err := storage.Sign(ctx, key, nil)
 	if err != nil {
 		return nil, nil, err
 	}
-	return doc, url, nil
+	return doc, signed, nil
 }
 
-func (s *mediaService) GetURL(ctx context.Context, id string, duration *time.Duration) (*string, error) {
+func (s *mediaService) Register(ctx context.Context, key, bucket, fileName, contentType string, size int64, mode string, createdBy string) (*model.MediaAsset, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	if _, err := uploader.UploadModeFromString(mode); err != nil {
+		mode = "private"
+	}
+
+	now := time.Now()
+	doc := &model.MediaAsset{
+		ID:             primitive.NewObjectID(),
+		Type:           detectMediaType(contentType, nil),
+		Key:            key,
+		Bucket:         bucket,
+		FileName:       fileName,
+		ContentType:    contentType,
+		Size:           size,
+		Mode:           strings.ToLower(mode),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		OrganizationID: helper.GetCurrentOrganizationID(ctx),
+		RefCount:       1,
+	}
+	if createdBy != "" {
+		doc.CreatedBy = &createdBy
+		doc.Refs = map[string]int{createdBy: 1}
+	}
+	if _, err := s.repo.Create(ctx, doc); err != nil {
+		return nil, err
+	}
+	s.quota.Record(ctx, doc.Bucket, doc.OrganizationID, quotaModel.CategoryMediaAsset, key, size)
+	return doc, nil
+}
+
+func (s *mediaService) GetURL(ctx context.Context, id string, duration *time.Duration) (*s3.SignedURL, error) {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
@@ -103,7 +281,10 @@ func (s *mediaService) GetURL(ctx context.Context, id string, duration *time.Dur
 	if doc == nil {
 		return nil, fmt.Errorf("media not found")
 	}
-	return s.s3.Get(ctx, doc.Key, duration)
+	if !canAccess(ctx, doc) {
+		return nil, ErrAccessDenied
+	}
+	return s.s3.For(doc.Bucket).Sign(ctx, doc.Key, s.clampTTL(duration))
 }
 
 func (s *mediaService) GetMeta(ctx context.Context, id string) (*model.MediaAsset, error) {
@@ -111,14 +292,123 @@ func (s *mediaService) GetMeta(ctx context.Context, id string) (*model.MediaAsse
 	if err != nil {
 		return nil, err
 	}
-	return s.repo.GetByID(ctx, oid)
+	doc, err := s.repo.GetByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	if doc != nil && !canAccess(ctx, doc) {
+		return nil, ErrAccessDenied
+	}
+	return doc, nil
 }
 
-func (s *mediaService) GetURLByKey(ctx context.Context, key string, duration *time.Duration) (*string, error) {
+func (s *mediaService) GetURLByKey(ctx context.Context, key string, duration *time.Duration) (*s3.SignedURL, error) {
 	if key == "" {
 		return nil, fmt.Errorf("key is required")
 	}
-	return s.s3.Get(ctx, key, duration)
+	// chỉ ký key thuộc một asset mà user hiện tại được xem, không ký key tùy ý trên bucket mặc định
+	doc, err := s.repo.FindByKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("media not found")
+	}
+	if !canAccess(ctx, doc) {
+		return nil, ErrAccessDenied
+	}
+	return s.s3.For(doc.Bucket).Sign(ctx, doc.Key, s.clampTTL(duration))
+}
+
+func (s *mediaService) SignBatch(ctx context.Context, keys, ids []string, duration *time.Duration) ([]dto.SignedURLItem, error) {
+	if len(keys)+len(ids) == 0 {
+		return nil, fmt.Errorf("keys or ids is required")
+	}
+	if len(keys)+len(ids) > maxSignBatchSize {
+		return nil, fmt.Errorf("at most %d urls per request", maxSignBatchSize)
+	}
+	duration = s.clampTTL(duration)
+
+	// key cũng phải thuộc một asset user được xem: không ký key tuỳ ý trên bucket, và ký đúng bucket của asset
+	byKey := make(map[string]*model.MediaAsset, len(keys))
+	if len(keys) > 0 {
+		docs, err := s.repo.FindByKeys(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			byKey[doc.Key] = doc
+		}
+	}
+
+	// lấy toàn bộ asset trong một query, id sai định dạng / không tồn tại báo lỗi riêng từng item
+	oids := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+	byID := make(map[string]*model.MediaAsset, len(oids))
+	if len(oids) > 0 {
+		docs, err := s.repo.GetByIDs(ctx, oids)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			byID[doc.ID.Hex()] = doc
+		}
+	}
+
+	items := make([]dto.SignedURLItem, 0, len(keys)+len(ids))
+	for _, key := range keys {
+		item := dto.SignedURLItem{Key: key}
+		if key == "" {
+			item.Error = "key is required"
+		} else {
+			s.signAsset(ctx, &item, byKey[key], duration)
+		}
+		items = append(items, item)
+	}
+	for _, id := range ids {
+		item := dto.SignedURLItem{ID: id}
+		s.signAsset(ctx, &item, byID[id], duration)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// signAsset ký url cho asset nếu user hiện tại được xem, lỗi ghi vào item
+func (s *mediaService) signAsset(ctx context.Context, item *dto.SignedURLItem, doc *model.MediaAsset, duration *time.Duration) {
+	switch {
+	case doc == nil:
+		item.Error = "media not found"
+	case !canAccess(ctx, doc):
+		item.Error = ErrAccessDenied.Error()
+	default:
+		item.ID, item.Key = doc.ID.Hex(), doc.Key
+		s.fillSigned(ctx, item, s.s3.For(doc.Bucket), doc.Key, duration)
+	}
+}
+
+func (s *mediaService) fillSigned(ctx context.Context, item *dto.SignedURLItem, storage s3.Service, key string, duration *time.Duration) {
+	signed, err := storage.Sign(ctx, key, duration)
+	if err != nil {
+		item.Error = err.Error()
+		return
+	}
+	item.URL, item.ExpiresAt = signed.URL, signed.ExpiresAt
+}
+
+// clampTTL giới hạn thời hạn client yêu cầu trong (0, maxURLTTL], nil = dùng mặc định của storage
+func (s *mediaService) clampTTL(duration *time.Duration) *time.Duration {
+	if duration == nil || *duration <= 0 {
+		return nil
+	}
+	if *duration > s.maxURLTTL {
+		d := s.maxURLTTL
+		return &d
+	}
+	return duration
 }
 
 func (s *mediaService) Delete(ctx context.Context, id string) error {
@@ -133,10 +423,23 @@ func (s *mediaService) Delete(ctx context.Context, id string) error {
 	if doc == nil {
 		return fmt.Errorf("media not found")
 	}
-	if err := s.s3.Delete(ctx, doc.Key); err != nil {
+	if !canAccess(ctx, doc) {
+		return ErrAccessDenied
+	}
+	// mỗi user chỉ trả được tham chiếu của chính mình, gọi lại DELETE không làm giảm tham chiếu của người khác
+	owner := helper.GetUserID(ctx)
+	if doc.Refs[owner] == 0 && doc.Refs[model.LegacyRefOwner] > 0 && isSuperAdmin(ctx) {
+		owner = model.LegacyRefOwner
+	}
+	// object dùng chung chỉ vào thùng rác khi tham chiếu cuối cùng bị xoá
+	trashed, err := s.repo.ReleaseRef(ctx, oid, owner, helper.GetUserID(ctx))
+	if err != nil {
 		return err
 	}
-	return s.repo.DeleteByID(ctx, oid)
+	if trashed {
+		s.trash.MoveObjects(ctx, doc.Bucket, doc.Key)
+	}
+	return nil
 }
 
 func (s *mediaService) buildObjectKey(folder, filename string) string {
@@ -155,6 +458,79 @@ func (s *mediaService) buildObjectKey(folder, filename string) string {
 	return fmt.Sprintf("%s/%s/%s-%d-%s%s", safeFolder, datePath, safeBase, time.Now().UnixNano(), randomHex, safeExt)
 }
 
+func (s *mediaService) OpenContent(ctx context.Context, id string, opts uploader.GetObjectOptions) (*uploader.ObjectReader, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.repo.GetByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, uploader.ErrObjectNotFound
+	}
+	if !canAccess(ctx, doc) {
+		return nil, ErrAccessDenied
+	}
+	return s.s3.For(doc.Bucket).Open(ctx, doc.Key, opts)
+}
+
+func canAccess(ctx context.Context, doc *model.MediaAsset) bool {
+	currentUser := helper.GetCurrentUser(ctx)
+	if currentUser == nil {
+		return false
+	}
+	if currentUser.IsSuperAdmin {
+		return true
+	}
+	if doc.CreatedBy != nil && *doc.CreatedBy == currentUser.ID {
+		return true
+	}
+	return helper.IsOrganizationMember(currentUser, doc.OrganizationID)
+}
+
+// modeName là giá trị lưu ở field mode, cũng là một phần của scope dedup
+func modeName(mode uploader.UploadMode) string {
+	if mode == uploader.UploadPublic {
+		return "public"
+	}
+	return "private"
+}
+
+func isSuperAdmin(ctx context.Context) bool {
+	currentUser := helper.GetCurrentUser(ctx)
+	return currentUser != nil && currentUser.IsSuperAdmin
+}
+
+// reuseExisting thêm tham chiếu của owner vào asset cùng nội dung, nil nếu chưa có (hoặc user không có organization)
+func (s *mediaService) reuseExisting(ctx context.Context, organizationID, mode, sum, owner string) (*model.MediaAsset, error) {
+	if organizationID == "" {
+		return nil, nil
+	}
+	existing, err := s.repo.FindBySHA256(ctx, organizationID, mode, sum)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	return s.repo.AddRef(ctx, existing.ID, owner)
+}
+
+// headBuffer giữ lại tối đa limit byte đầu tiên được ghi vào
+type headBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if remain := b.limit - len(b.buf); remain > 0 {
+		if len(p) < remain {
+			remain = len(p)
+		}
+		b.buf = append(b.buf, p[:remain]...)
+	}
+	return len(p), nil
+}
+
 func detectMediaType(contentType string, override *string) model.MediaType {
 	if override != nil && *override != "" {
 		switch strings.ToLower(*override) {
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DeletionStatus string
+
+const (
+	DeletionStatusPending DeletionStatus = "pending"
+	DeletionStatusFailed  DeletionStatus = "failed" // hết số lần retry, cần xử lý tay
+)
+
+// PendingDeletion là một object cần xoá khỏi bucket, được worker xử lý bất đồng bộ
+type PendingDeletion struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Key         string             `bson:"key" json:"key"`
+	Bucket      string             `bson:"bucket,omitempty" json:"bucket,omitempty"`
+	Reason      string             `bson:"reason" json:"reason"` // flow đã tạo yêu cầu xoá, để debug
+	Status      DeletionStatus     `bson:"status" json:"status"`
+	Attempts    int                `bson:"attempts" json:"attempts"`
+	LastError   string             `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	AvailableAt time.Time          `bson:"available_at" json:"available_at"` // lần thử tiếp theo
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"media-service/internal/outbox/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type DeletionRepository interface {
+	InsertMany(ctx context.Context, entries []model.PendingDeletion) error
+	// ClaimNext lấy một entry đến hạn và đẩy available_at ra sau lease để worker khác không lấy trùng
+	ClaimNext(ctx context.Context, lease time.Duration) (*model.PendingDeletion, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	MarkRetry(ctx context.Context, id primitive.ObjectID, lastErr string, availableAt time.Time) error
+	MarkFailed(ctx context.Context, id primitive.ObjectID, lastErr string) error
+}
+
+type deletionRepository struct {
+	col *mongo.Collection
+}
+
+func NewDeletionRepository(col *mongo.Collection) DeletionRepository {
+	return &deletionRepository{col: col}
+}
+
+func (r *deletionRepository) InsertMany(ctx context.Context, entries []model.PendingDeletion) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(entries))
+	for _, e := range entries {
+		docs = append(docs, e)
+	}
+	_, err := r.col.InsertMany(ctx, docs)
+	return err
+}
+
+func (r *deletionRepository) ClaimNext(ctx context.Context, lease time.Duration) (*model.PendingDeletion, error) {
+	now := time.Now()
+	filter := bson.M{
+		"status":       model.DeletionStatusPending,
+		"available_at": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"available_at": now.Add(lease), "updated_at": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"available_at": 1}).
+		SetReturnDocument(options.After)
+
+	var out model.PendingDeletion
+	if err := r.col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&out); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *deletionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.col.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *deletionRepository) MarkRetry(ctx context.Context, id primitive.ObjectID, lastErr string, availableAt time.Time) error {
+	_, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"last_error": lastErr, "available_at": availableAt, "updated_at": time.Now()}},
+	)
+	return err
+}
+
+func (r *deletionRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, lastErr string) error {
+	_, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"status": model.DeletionStatusFailed, "last_error": lastErr, "updated_at": time.Now()}},
+	)
+	return err
+}
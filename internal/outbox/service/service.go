@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"media-service/internal/outbox/model"
+	"media-service/internal/outbox/repository"
+	"media-service/internal/s3"
+	"media-service/logger"
+	"media-service/pkg/config"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultPollIntervalSeconds = 10
+	defaultMaxAttempts         = 10
+	defaultBaseBackoff         = 30 * time.Second
+	maxBackoff                 = 6 * time.Hour
+	claimLease                 = 5 * time.Minute // entry bị giữ nếu worker chết giữa chừng
+)
+
+// DeletionService thay cho việc gọi s3Service.Delete trực tiếp: key được ghi vào outbox
+// sau khi metadata đã được cập nhật, worker sẽ xoá object với retry + backoff.
+// Nếu ghi outbox lỗi thì object chỉ thành orphan và sẽ được storage GC dọn.
+type DeletionService interface {
+	// Enqueue ghi các key cần xoá khỏi bucket (tên trong cấu hình, "" = mặc định)
+	Enqueue(ctx context.Context, reason string, bucket string, keys ...string) error
+	// Start chạy worker cho tới khi ctx bị huỷ
+	Start(ctx context.Context)
+}
+
+type deletionService struct {
+	repo         repository.DeletionRepository
+	s3Service    s3.Service
+	pollInterval time.Duration
+	maxAttempts  int
+	baseBackoff  time.Duration
+}
+
+func NewDeletionService(repo repository.DeletionRepository, s3Service s3.Service) DeletionService {
+	cfg := config.AppConfig.Outbox
+
+	pollSeconds := cfg.PollIntervalSeconds
+	if pollSeconds <= 0 {
+		pollSeconds = defaultPollIntervalSeconds
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	return &deletionService{
+		repo:         repo,
+		s3Service:    s3Service,
+		pollInterval: time.Duration(pollSeconds) * time.Second,
+		maxAttempts:  maxAttempts,
+		baseBackoff:  defaultBaseBackoff,
+	}
+}
+
+func (s *deletionService) Enqueue(ctx context.Context, reason string, bucket string, keys ...string) error {
+	now := time.Now()
+	entries := make([]model.PendingDeletion, 0, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		entries = append(entries, model.PendingDeletion{
+			ID:          primitive.NewObjectID(),
+			Key:         key,
+			Bucket:      bucket,
+			Reason:      reason,
+			Status:      model.DeletionStatusPending,
+			AvailableAt: now,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+
+	if err := s.repo.InsertMany(ctx, entries); err != nil {
+		logger.WriteLogEx("error", "failed to enqueue object deletion", map[string]any{
+			"reason": reason,
+			"keys":   keys,
+			"error":  err.Error(),
+		})
+		return err
+	}
+	return nil
+}
+
+func (s *deletionService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.drain(ctx)
+			}
+		}
+	}()
+}
+
+// drain xử lý hết các entry đã đến hạn
+func (s *deletionService) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		entry, err := s.repo.ClaimNext(ctx, claimLease)
+		if err != nil {
+			logger.WriteLogEx("error", "failed to claim pending deletion", err)
+			return
+		}
+		if entry == nil {
+			return
+		}
+		s.process(ctx, entry)
+	}
+}
+
+func (s *deletionService) process(ctx context.Context, entry *model.PendingDeletion) {
+	err := s.s3Service.For(entry.Bucket).Delete(ctx, entry.Key)
+	if err == nil {
+		if err := s.repo.Delete(ctx, entry.ID); err != nil {
+			logger.WriteLogEx("error", "failed to remove processed deletion", err)
+		}
+		return
+	}
+
+	if entry.Attempts >= s.maxAttempts {
+		logger.WriteLogEx("error", "giving up deleting object", map[string]any{
+			"key":      entry.Key,
+			"attempts": entry.Attempts,
+			"error":    err.Error(),
+		})
+		if err := s.repo.MarkFailed(ctx, entry.ID, err.Error()); err != nil {
+			logger.WriteLogEx("error", "failed to mark deletion as failed", err)
+		}
+		return
+	}
+
+	if err := s.repo.MarkRetry(ctx, entry.ID, err.Error(), time.Now().Add(s.backoff(entry.Attempts))); err != nil {
+		logger.WriteLogEx("error", "failed to reschedule deletion", err)
+	}
+}
+
+// backoff tăng gấp đôi sau mỗi lần thử: 30s, 1m, 2m, 4m ... tối đa 6h
+func (s *deletionService) backoff(attempts int) time.Duration {
+	d := s.baseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
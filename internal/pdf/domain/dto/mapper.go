@@ -37,15 +37,16 @@ func ToResourceResponses(
 			resp.TargetInfor = getUserInfoByRole(ctx, userGw, r.TargetID, organizationID)
 		}
 
+		storage := s3Svc.For(r.Bucket)
 		if r.SignatureKey != nil {
-			url, err := s3Svc.Get(ctx, *r.SignatureKey, nil)
+			url, err := storage.Get(ctx, *r.SignatureKey, nil)
 			if err == nil {
 				resp.SignatureUrl = url
 			}
 		}
 
 		if r.PDFKey != nil && *r.PDFKey != "" {
-			url, err := s3Svc.Get(ctx, *r.PDFKey, nil)
+			url, err := storage.Get(ctx, *r.PDFKey, nil)
 			if err == nil {
 				resp.PDFUrl = url
 			}
@@ -2,6 +2,7 @@ package dto
 
 import (
 	"media-service/internal/pdf/model"
+	"media-service/pkg/uploader"
 	"mime/multipart"
 )
 
@@ -22,6 +23,8 @@ type UpdateResourceRequest struct {
 	File         *multipart.FileHeader `form:"file"`
 	ResourceType string                `form:"resource_type"`
 	Url          *string               `form:"url"`
+	// checksum client gửi kèm File, nil nếu không có
+	Checksum *uploader.Checksum `form:"-"`
 }
 
 type UploadSignatureRequest struct {
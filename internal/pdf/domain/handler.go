@@ -72,6 +72,11 @@ func (h *UserResourceHandler) UploadDocumentToResource(c *fiber.Ctx) error {
 		} else {
 			req.File = file
 		}
+		checksums, err := helper.FormChecksums(c)
+		if err != nil {
+			return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		}
+		req.Checksum = checksums["file"]
 
 		if resourceType := c.FormValue("resource_type"); resourceType != "" {
 			req.ResourceType = resourceType
@@ -97,7 +102,8 @@ func (h *UserResourceHandler) UploadDocumentToResource(c *fiber.Ctx) error {
 
 	res, err := h.userResourceService.UploadDocumentToResource(c.UserContext(), id, req)
 	if err != nil {
-		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		status, code := helper.MapError(err, http.StatusInternalServerError, helper.ErrInvalidOperation)
+		return helper.SendError(c, status, err, code)
 	}
 
 	return helper.SendSuccess(c, http.StatusOK, "upload document success", res)
@@ -3,6 +3,7 @@ package domain
 import (
 	"context"
 	"media-service/internal/pdf/model"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,7 +18,8 @@ type UserResourceRepository interface {
 	GetStudentResources(ctx context.Context, studentIDs []string) ([]*model.UserResource, error)
 	UpdateResourceByID(ctx context.Context, id primitive.ObjectID, pdf *model.UserResource) error
 	UpdateResourceFields(ctx context.Context, id primitive.ObjectID, updateFields bson.M) error
-	DeleteResourceByID(ctx context.Context, id primitive.ObjectID) error
+	// SoftDeleteResourceByID trả về false nếu resource không tồn tại hoặc đã bị xoá trước đó
+	SoftDeleteResourceByID(ctx context.Context, id primitive.ObjectID, deletedBy string) (bool, error)
 	// GetPDFsByStudent(ctx context.Context, studentID string) ([]*model.StudentReportPDF, error)
 	// GetPDFByID(ctx context.Context, id primitive.ObjectID) (*model.StudentReportPDF, error)
 	// DeletePDFByID(ctx context.Context, id primitive.ObjectID) error
@@ -43,7 +45,7 @@ func (p *userResourceRepository) GetResourceByID(ctx context.Context, id primiti
 
 	var pdf *model.UserResource
 
-	if err := p.UserResourceCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&pdf); err != nil {
+	if err := p.UserResourceCollection.FindOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}}).Decode(&pdf); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
 		}
@@ -60,6 +62,7 @@ func (p *userResourceRepository) GetSelfResources(ctx context.Context, ownerID s
 	filter := bson.M{
 		"uploader_id.owner_id": ownerID,
 		"target_id":            nil,
+		"deleted_at":           bson.M{"$exists": false},
 	}
 
 	cursor, err := p.UserResourceCollection.Find(ctx, filter)
@@ -82,6 +85,7 @@ func (p *userResourceRepository) GetRelatedResources(ctx context.Context, ownerI
 	filter := bson.M{
 		"uploader_id.owner_id": ownerID,
 		"target_id.owner_id":   bson.M{"$exists": true},
+		"deleted_at":           bson.M{"$exists": false},
 	}
 
 	if studentID != "" {
@@ -106,6 +110,7 @@ func (p *userResourceRepository) GetStudentResources(ctx context.Context, studen
 	var resources []*model.UserResource
 	filter := bson.M{
 		"target_id.owner_id": bson.M{"$in": studentIDs},
+		"deleted_at":         bson.M{"$exists": false},
 	}
 	cursor, err := p.UserResourceCollection.Find(ctx, filter)
 	if err != nil {
@@ -128,7 +133,13 @@ func (p *userResourceRepository) UpdateResourceFields(ctx context.Context, id pr
 	return err
 }
 
-func (p *userResourceRepository) DeleteResourceByID(ctx context.Context, id primitive.ObjectID) error {
-	_, err := p.UserResourceCollection.DeleteOne(ctx, bson.M{"_id": id})
-	return err
+func (p *userResourceRepository) SoftDeleteResourceByID(ctx context.Context, id primitive.ObjectID, deletedBy string) (bool, error) {
+	res, err := p.UserResourceCollection.UpdateOne(ctx,
+		bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"deleted_at": time.Now(), "deleted_by": deletedBy}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
 }
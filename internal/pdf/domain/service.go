@@ -5,9 +5,14 @@ import (
 	"fmt"
 	"media-service/helper"
 	"media-service/internal/gateway"
+	outboxService "media-service/internal/outbox/service"
 	"media-service/internal/pdf/domain/dto"
 	"media-service/internal/pdf/model"
+	quotaModel "media-service/internal/quota/model"
+	quotaService "media-service/internal/quota/service"
 	"media-service/internal/s3"
+	trashService "media-service/internal/trash/service"
+	"media-service/pkg/objectkey"
 	"media-service/pkg/uploader"
 	"time"
 
@@ -29,15 +34,24 @@ type userResourceService struct {
 	UserResourceRepository UserResourceRepository
 	s3Service              s3.Service
 	userGateway            gateway.UserGateway
+	trash                  trashService.TrashService
+	quotaService           quotaService.QuotaService
+	deletionOutbox         outboxService.DeletionService
 }
 
 func NewUserResourceService(userResourceRepository UserResourceRepository,
 	s3Service s3.Service,
-	userGateway gateway.UserGateway) UserResourceService {
+	userGateway gateway.UserGateway,
+	trash trashService.TrashService,
+	quotaSvc quotaService.QuotaService,
+	deletionOutbox outboxService.DeletionService) UserResourceService {
 	return &userResourceService{
 		UserResourceRepository: userResourceRepository,
 		s3Service:              s3Service,
 		userGateway:            userGateway,
+		trash:                  trash,
+		quotaService:           quotaSvc,
+		deletionOutbox:         deletionOutbox,
 	}
 }
 
@@ -125,6 +139,7 @@ func (s *userResourceService) CreateResource(ctx context.Context, req dto.Create
 		SignatureKey: nil,
 		URL:          nil,
 		PDFKey:       nil,
+		Bucket:       s.s3Service.Route(string(quotaModel.CategoryDocument), helper.GetCurrentOrganizationID(ctx)).Bucket(),
 		CreatedBy:    helper.GetUserID(ctx),
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
@@ -212,17 +227,20 @@ func (s *userResourceService) UploadDocumentToResource(ctx context.Context, id s
 		return "", err
 	}
 
-	if pdfData.PDFKey != nil {
-		err = s.s3Service.Delete(ctx, *pdfData.PDFKey)
-		if err != nil {
-			return "", err
-		}
-	}
-
 	if pdfData == nil {
 		return "", fmt.Errorf("pdf not found")
 	}
 
+	// kiểm tra quota và checksum trước khi lưu file mới
+	if req.ResourceType == "pdf" && req.File != nil {
+		if err := s.quotaService.Check(ctx, pdfData.Organization, req.File.Size); err != nil {
+			return "", err
+		}
+		if err := helper.VerifyFileChecksum(req.File, req.Checksum); err != nil {
+			return "", err
+		}
+	}
+
 	if req.ResourceType == "pdf" && req.File != nil {
 		resource, err := s.UserResourceRepository.GetResourceByID(ctx, objectID)
 		if err != nil {
@@ -232,28 +250,44 @@ func (s *userResourceService) UploadDocumentToResource(ctx context.Context, id s
 			return "", fmt.Errorf("resource not found")
 		}
 
-		key := helper.BuildObjectKeyS3("pdf_media", req.File.Filename, *req.FileName)
+		key, err := objectkey.Build(objectkey.Object{
+			OrganizationID: resource.Organization,
+			Entity:         objectkey.EntityDocument,
+			EntityID:       resource.ID.Hex(),
+			Slot:           "pdf",
+			FileName:       req.File.Filename,
+			Title:          *req.FileName,
+			Folder:         "pdf_media",
+		})
+		if err != nil {
+			return "", err
+		}
 		f, openErr := req.File.Open()
 		if openErr != nil {
 			return "", openErr
 		}
 		defer f.Close()
 		ct := req.File.Header.Get("Content-Type")
-		_, err = s.s3Service.SaveReader(ctx, f, key, ct, uploader.UploadPrivate)
+		_, checksum, err := s.s3Service.For(resource.Bucket).SaveReaderChecked(ctx, f, key, ct, uploader.UploadPrivate, req.Checksum)
 		if err != nil {
 			return "", err
 		}
+		s.quotaService.Record(ctx, resource.Bucket, resource.Organization, quotaModel.CategoryDocument, key, req.File.Size)
 
 		resource.FileName = req.FileName
 		resource.ResourceType = req.ResourceType
 		resource.PDFKey = &key
+		resource.PDFChecksum = checksum
 		resource.URL = nil
 		resource.UpdatedAt = time.Now()
 
 		err = s.UserResourceRepository.UpdateResourceByID(ctx, objectID, resource)
 		if err != nil {
+			// document vẫn trỏ file cũ, file mới vừa lưu thành rác
+			_ = s.deletionOutbox.Enqueue(ctx, "update_pdf_rollback", resource.Bucket, key)
 			return "", err
 		}
+		s.enqueueReplaced(ctx, "update_pdf", pdfData.Bucket, pdfData.PDFKey, key)
 
 		return key, nil
 
@@ -269,6 +303,7 @@ func (s *userResourceService) UploadDocumentToResource(ctx context.Context, id s
 		resource.ResourceType = req.ResourceType
 		resource.URL = req.Url
 		resource.PDFKey = nil
+		resource.PDFChecksum = nil
 		resource.FileName = nil
 		resource.UpdatedAt = time.Now()
 
@@ -276,6 +311,7 @@ func (s *userResourceService) UploadDocumentToResource(ctx context.Context, id s
 		if err != nil {
 			return "", err
 		}
+		s.enqueueReplaced(ctx, "update_pdf", pdfData.Bucket, pdfData.PDFKey, "")
 
 		return *req.Url, nil
 	} else {
@@ -299,13 +335,7 @@ func (s *userResourceService) UploadSignatureToResource(ctx context.Context, id
 		return "", fmt.Errorf("pdf not found")
 	}
 
-	if pdfData.SignatureKey != nil {
-		err = s.s3Service.Delete(ctx, *pdfData.SignatureKey)
-		if err != nil {
-			return "", err
-		}
-	}
-
+	oldSignatureKey := pdfData.SignatureKey
 	pdfData.SignatureKey = &req.SignatureKey
 	pdfData.UpdatedAt = time.Now()
 
@@ -313,11 +343,21 @@ func (s *userResourceService) UploadSignatureToResource(ctx context.Context, id
 	if err != nil {
 		return "", err
 	}
+	s.enqueueReplaced(ctx, "update_signature", pdfData.Bucket, oldSignatureKey, req.SignatureKey)
 
 	return req.SignatureKey, nil
 
 }
 
+// enqueueReplaced đưa object cũ vào outbox sau khi document đã trỏ sang object mới,
+// object được worker xoá sau, lỗi ghi outbox đã được log
+func (s *userResourceService) enqueueReplaced(ctx context.Context, reason, bucket string, oldKey *string, newKey string) {
+	if oldKey == nil || *oldKey == "" || *oldKey == newKey {
+		return
+	}
+	_ = s.deletionOutbox.Enqueue(ctx, reason, bucket, *oldKey)
+}
+
 func (s *userResourceService) UpdateResourceStatus(ctx context.Context, id string, req dto.UpdateResourceStatusRequest) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -412,24 +452,23 @@ func (s *userResourceService) DeleteResource(ctx context.Context, id string) err
 		return fmt.Errorf("resource not found")
 	}
 
-	if resource.PDFKey != nil {
-		err = s.s3Service.Delete(ctx, *resource.PDFKey)
-		if err != nil {
-			return err
-		}
+	deleted, err := s.UserResourceRepository.SoftDeleteResourceByID(ctx, objectID, helper.GetUserID(ctx))
+	if err != nil {
+		return err
 	}
-
-	if resource.SignatureKey != nil {
-		err = s.s3Service.Delete(ctx, *resource.SignatureKey)
-		if err != nil {
-			return err
-		}
+	if !deleted {
+		return fmt.Errorf("resource not found")
 	}
 
-	err = s.UserResourceRepository.DeleteResourceByID(ctx, objectID)
-	if err != nil {
-		return err
+	// file pdf & chữ ký nằm trong thùng rác, job purge sẽ xoá hẳn sau thời gian lưu giữ
+	keys := make([]string, 0, 2)
+	if resource.PDFKey != nil {
+		keys = append(keys, *resource.PDFKey)
+	}
+	if resource.SignatureKey != nil {
+		keys = append(keys, *resource.SignatureKey)
 	}
+	s.trash.MoveObjects(ctx, resource.Bucket, keys...)
 
 	return nil
 
@@ -3,6 +3,8 @@ package model
 import (
 	"time"
 
+	"media-service/pkg/uploader"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -21,9 +23,14 @@ type UserResource struct {
 	SignatureKey *string            `json:"signature_key" bson:"signature_key"`
 	URL          *string            `json:"url" bson:"url"`
 	PDFKey       *string            `json:"pdf_key" bson:"pdf_key"`
+	PDFChecksum  *uploader.Checksum `json:"pdf_checksum" bson:"pdf_checksum"`
+	Bucket       string             `json:"bucket,omitempty" bson:"bucket,omitempty"` // chứa cả pdf và chữ ký
 	CreatedBy    string             `json:"created_by" bson:"created_by"`
 	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+	// xoá mềm
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 }
 
 type Owner struct {
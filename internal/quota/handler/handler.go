@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"fmt"
+	"media-service/helper"
+	"media-service/internal/quota/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type QuotaHandler struct {
+	svc service.QuotaService
+}
+
+func NewQuotaHandler(svc service.QuotaService) *QuotaHandler {
+	return &QuotaHandler{svc: svc}
+}
+
+// GetUsage trả về dung lượng đã dùng của organization, chia theo loại dữ liệu
+func (h *QuotaHandler) GetUsage(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	currentUser := helper.GetCurrentUser(ctx)
+
+	organizationID := c.Query("organization_id")
+	if organizationID == "" {
+		organizationID = helper.GetCurrentOrganizationID(ctx)
+	}
+	if organizationID == "" {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("organization_id is required"), helper.ErrInvalidRequest)
+	}
+	if currentUser == nil || (!currentUser.IsSuperAdmin && !helper.IsOrganizationMember(currentUser, organizationID)) {
+		return helper.SendError(c, http.StatusForbidden, fmt.Errorf("access denied"), helper.ErrInvalidOperation)
+	}
+
+	usage, err := h.svc.Usage(ctx, organizationID)
+	if err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "success", usage)
+}
+
+// Backfill ghi vào sổ dung lượng các object có từ trước, mặc định dry_run=true
+func (h *QuotaHandler) Backfill(c *fiber.Ctx) error {
+	dryRun := c.Query("dry_run") != "false"
+	report, err := h.svc.Backfill(c.UserContext(), dryRun)
+	if err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "storage usage backfill finished", report)
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// GlobalOrganizationID là organization_id của dung lượng do upload không thuộc organization nào,
+// các upload này dùng chung một quota thay vì không bị tính
+const GlobalOrganizationID = "global"
+
+// Category là loại dữ liệu dùng để chia nhỏ dung lượng của organization
+type Category string
+
+const (
+	CategoryTopic           Category = "topic"
+	CategoryVocabulary      Category = "vocabulary"
+	CategoryStudentResource Category = "student_resource" // topic resource của học sinh
+	CategoryDocument        Category = "document"         // pdf resource
+	CategoryVideo           Category = "video"            // video uploader
+	CategoryMediaAsset      Category = "media_asset"
+)
+
+var Categories = []Category{
+	CategoryTopic,
+	CategoryVocabulary,
+	CategoryStudentResource,
+	CategoryDocument,
+	CategoryVideo,
+	CategoryMediaAsset,
+}
+
+// CategoryNames trả về Categories dạng chuỗi (giá trị hợp lệ của storage.routing.categories)
+func CategoryNames() []string {
+	names := make([]string, 0, len(Categories))
+	for _, c := range Categories {
+		names = append(names, string(c))
+	}
+	return names
+}
+
+// UsageEntry là một object đang chiếm dung lượng của organization, mỗi (bucket, key) một entry:
+// cùng key ở hai bucket (chuyển bucket, routing, migration) là hai object riêng
+type UsageEntry struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	Bucket         string             `bson:"bucket" json:"bucket,omitempty"` // tên trong cấu hình, "" = bucket mặc định
+	Key            string             `bson:"key" json:"key"`
+	OrganizationID string             `bson:"organization_id" json:"organization_id"`
+	Category       Category           `bson:"category" json:"category"`
+	Size           int64              `bson:"size" json:"size"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type Usage struct {
+	OrganizationID string             `json:"organization_id"`
+	UsedBytes      int64              `json:"used_bytes"`
+	QuotaBytes     int64              `json:"quota_bytes"` // 0 = không giới hạn
+	RemainingBytes *int64             `json:"remaining_bytes,omitempty"`
+	ByType         map[Category]int64 `json:"by_type"`
+}
+
+// Object là một object được document tham chiếu, dùng để dựng lại sổ dung lượng
+type Object struct {
+	Bucket         string
+	Key            string
+	OrganizationID string
+	Category       Category
+}
+
+// BackfillReport là kết quả của một lần dựng lại sổ dung lượng từ các document
+type BackfillReport struct {
+	DryRun          bool      `json:"dry_run"`
+	ScannedObjects  int       `json:"scanned_objects"`
+	RecordedObjects int       `json:"recorded_objects"`
+	RecordedBytes   int64     `json:"recorded_bytes"`
+	LegacyEntries   int64     `json:"legacy_entries"`   // entry kiểu cũ không có bucket, được thay bằng entry dựng lại
+	ExistingObjects int       `json:"existing_objects"` // đã có trong sổ, giữ nguyên
+	MissingObjects  int       `json:"missing_objects"`  // document tham chiếu nhưng object không còn trên storage
+	Errors          []string  `json:"errors,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+
+	"media-service/internal/quota/model"
+	gcRepository "media-service/internal/storagegc/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ObjectRepository liệt kê các object đang được document tham chiếu, dùng để dựng lại sổ dung lượng
+type ObjectRepository interface {
+	// EachObject gọi fn cho từng object của các document chưa bị xoá mềm
+	EachObject(ctx context.Context, fn func(model.Object) error) error
+}
+
+type ObjectCollections struct {
+	Topics         *mongo.Collection
+	Vocabularies   *mongo.Collection
+	TopicResources *mongo.Collection
+	PDFResources   *mongo.Collection
+	VideoUploaders *mongo.Collection
+	MediaAssets    *mongo.Collection
+}
+
+type objectRepository struct {
+	cols ObjectCollections
+}
+
+func NewObjectRepository(cols ObjectCollections) ObjectRepository {
+	return &objectRepository{cols: cols}
+}
+
+// objectSource là một collection cùng cách lấy organization của document
+type objectSource struct {
+	col      *mongo.Collection
+	category model.Category
+	owner    func(doc bson.M) string
+}
+
+func (r *objectRepository) EachObject(ctx context.Context, fn func(model.Object) error) error {
+	topicOrgs, err := r.topicOrganizations(ctx)
+	if err != nil {
+		return err
+	}
+	byTopic := func(doc bson.M) string {
+		topicID, _ := doc["topic_id"].(string)
+		return topicOrgs[topicID]
+	}
+	field := func(name string) func(bson.M) string {
+		return func(doc bson.M) string {
+			v, _ := doc[name].(string)
+			return v
+		}
+	}
+
+	sources := []objectSource{
+		{r.cols.Topics, model.CategoryTopic, field("organization_id")},
+		{r.cols.Vocabularies, model.CategoryVocabulary, byTopic},
+		{r.cols.TopicResources, model.CategoryStudentResource, byTopic},
+		{r.cols.PDFResources, model.CategoryDocument, field("organization")},
+		// video uploader không thuộc organization nào, tính vào global
+		{r.cols.VideoUploaders, model.CategoryVideo, field("organization_id")},
+		{r.cols.MediaAssets, model.CategoryMediaAsset, field("organization_id")},
+	}
+	for _, src := range sources {
+		if src.col == nil {
+			continue
+		}
+		if err := r.eachInCollection(ctx, src, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *objectRepository) eachInCollection(ctx context.Context, src objectSource, fn func(model.Object) error) error {
+	cursor, err := src.col.Find(ctx, bson.M{"deleted_at": bson.M{"$exists": false}}, options.Find().SetBatchSize(500))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		base := model.Object{OrganizationID: src.owner(doc), Category: src.category}
+		base.Bucket, _ = doc["bucket"].(string)
+
+		keys := map[string]struct{}{}
+		walk(doc, keys)
+		for key := range keys {
+			obj := base
+			obj.Key = key
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+	}
+	return cursor.Err()
+}
+
+// topicOrganizations trả về organization theo topic id, vocabulary và topic resource chỉ lưu topic_id
+func (r *objectRepository) topicOrganizations(ctx context.Context) (map[string]string, error) {
+	orgs := map[string]string{}
+	if r.cols.Topics == nil {
+		return orgs, nil
+	}
+	cursor, err := r.cols.Topics.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"organization_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID             primitive.ObjectID `bson:"_id"`
+			OrganizationID string             `bson:"organization_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		orgs[doc.ID.Hex()] = doc.OrganizationID
+	}
+	return orgs, cursor.Err()
+}
+
+// walk duyệt đệ quy document (language_config, images, variants... là mảng lồng nhau)
+func walk(v interface{}, keys map[string]struct{}) {
+	switch val := v.(type) {
+	case bson.M:
+		for field, child := range val {
+			if s, ok := child.(string); ok {
+				if gcRepository.IsKeyField(field) && s != "" {
+					keys[s] = struct{}{}
+				}
+				continue
+			}
+			walk(child, keys)
+		}
+	case bson.D:
+		for _, e := range val {
+			walk(bson.M{e.Key: e.Value}, keys)
+		}
+	case bson.A:
+		for _, child := range val {
+			walk(child, keys)
+		}
+	case []interface{}:
+		for _, child := range val {
+			walk(child, keys)
+		}
+	}
+}
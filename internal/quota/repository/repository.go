@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"media-service/internal/quota/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UsageRepository là sổ dung lượng: mỗi object (bucket, key) đang lưu trên storage ứng với một entry
+type UsageRepository interface {
+	// Record ghi đè entry cùng bucket + key (upload lại cùng key không bị tính hai lần)
+	Record(ctx context.Context, entry *model.UsageEntry) error
+	Release(ctx context.Context, bucket, key string) error
+	// Copy tạo entry cho destKey giống hệt srcKey trong cùng bucket, không làm gì nếu srcKey chưa được ghi nhận
+	Copy(ctx context.Context, bucket, srcKey, destKey string) error
+	Exists(ctx context.Context, bucket, key string) (bool, error)
+	// DeleteLegacy xoá entry kiểu cũ (_id là key, không có bucket), dryRun chỉ đếm
+	DeleteLegacy(ctx context.Context, dryRun bool) (int64, error)
+	Summary(ctx context.Context, organizationID string) (map[model.Category]int64, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type usageRepository struct {
+	col *mongo.Collection
+}
+
+func NewUsageRepository(col *mongo.Collection) UsageRepository {
+	return &usageRepository{col: col}
+}
+
+func (r *usageRepository) Record(ctx context.Context, entry *model.UsageEntry) error {
+	// _id rỗng: giữ _id của entry đang có, upsert mới thì mongo tự sinh
+	doc := *entry
+	doc.ID = primitive.NilObjectID
+	_, err := r.col.ReplaceOne(ctx, bson.M{"bucket": entry.Bucket, "key": entry.Key}, doc, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *usageRepository) Release(ctx context.Context, bucket, key string) error {
+	_, err := r.col.DeleteOne(ctx, bson.M{"bucket": bucket, "key": key})
+	return err
+}
+
+func (r *usageRepository) Copy(ctx context.Context, bucket, srcKey, destKey string) error {
+	var entry model.UsageEntry
+	if err := r.col.FindOne(ctx, bson.M{"bucket": bucket, "key": srcKey}).Decode(&entry); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+	entry.Key = destKey
+	return r.Record(ctx, &entry)
+}
+
+func (r *usageRepository) Exists(ctx context.Context, bucket, key string) (bool, error) {
+	n, err := r.col.CountDocuments(ctx, bson.M{"bucket": bucket, "key": key}, options.Count().SetLimit(1))
+	return n > 0, err
+}
+
+func (r *usageRepository) Summary(ctx context.Context, organizationID string) (map[model.Category]int64, error) {
+	cursor, err := r.col.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"organization_id": organizationID}}},
+		{{Key: "$group", Value: bson.M{"_id": "$category", "size": bson.M{"$sum": "$size"}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Category model.Category `bson:"_id"`
+		Size     int64          `bson:"size"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	out := make(map[model.Category]int64, len(rows))
+	for _, row := range rows {
+		out[row.Category] = row.Size
+	}
+	return out, nil
+}
+
+func (r *usageRepository) DeleteLegacy(ctx context.Context, dryRun bool) (int64, error) {
+	filter := bson.M{"key": bson.M{"$exists": false}}
+	if dryRun {
+		return r.col.CountDocuments(ctx, filter)
+	}
+	res, err := r.col.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+func (r *usageRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "bucket", Value: 1}, {Key: "key", Value: 1}},
+			// entry kiểu cũ chưa có field key (xem DeleteLegacy) không được chặn việc tạo index
+			Options: options.Index().SetName("bucket_key").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"key": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.D{{Key: "organization_id", Value: 1}, {Key: "category", Value: 1}},
+			Options: options.Index().SetName("organization_id_category"),
+		},
+	})
+	return err
+}
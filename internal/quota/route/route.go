@@ -0,0 +1,21 @@
+package route
+
+import (
+	"media-service/internal/gateway"
+	"media-service/internal/middleware"
+	"media-service/internal/quota/handler"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterQuotaRoutes(app *fiber.App, h *handler.QuotaHandler, userGw gateway.UserGateway) {
+	storage := app.Group("/api/v2/storage")
+	storage.Use(middleware.Secured(userGw))
+
+	storage.Get("/usage", h.GetUsage)
+
+	admin := app.Group("/api/v2/admin/storage")
+	admin.Use(middleware.Secured(userGw))
+
+	admin.Post("/usage/backfill", middleware.RequireAdmin(), h.Backfill)
+}
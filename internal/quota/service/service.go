@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"media-service/internal/quota/model"
+	"media-service/internal/quota/repository"
+	"media-service/internal/s3"
+	"media-service/logger"
+	"media-service/pkg/config"
+	"media-service/pkg/uploader"
+)
+
+const bytesPerMB = 1024 * 1024
+
+// QuotaService kiểm tra và ghi nhận dung lượng theo organization.
+// Việc trừ dung lượng khi object bị xoá do s3.Service tự làm, mọi đường xoá đều đi qua đó.
+type QuotaService interface {
+	// Check trả về model.ErrQuotaExceeded nếu lưu thêm size byte sẽ vượt quota.
+	// Hai upload đồng thời có thể cùng lọt qua, quota là giới hạn mềm.
+	// organizationID rỗng được tính vào model.GlobalOrganizationID (Record cũng vậy).
+	Check(ctx context.Context, organizationID string, size int64) error
+	// Record ghi nhận object trong bucket (tên trong cấu hình, "" = mặc định) vừa được gắn vào organization;
+	// size < 0 thì lấy từ HEAD. Lỗi chỉ được ghi log để không làm hỏng upload đã thành công.
+	Record(ctx context.Context, bucket, organizationID string, category model.Category, key string, size int64)
+	Usage(ctx context.Context, organizationID string) (*model.Usage, error)
+	// Backfill ghi vào sổ các object đang được document tham chiếu nhưng chưa có entry
+	// (object có từ trước khi có sổ dung lượng hoặc chỉ có entry kiểu cũ không có bucket).
+	// Entry đã có được giữ nguyên, dryRun chỉ đếm.
+	Backfill(ctx context.Context, dryRun bool) (*model.BackfillReport, error)
+}
+
+type quotaService struct {
+	repo      repository.UsageRepository
+	objects   repository.ObjectRepository
+	s3Service s3.Service
+}
+
+func NewQuotaService(repo repository.UsageRepository, objects repository.ObjectRepository, s3Service s3.Service) QuotaService {
+	return &quotaService{
+		repo:      repo,
+		objects:   objects,
+		s3Service: s3Service,
+	}
+}
+
+func (s *quotaService) Check(ctx context.Context, organizationID string, size int64) error {
+	organizationID = usageOwner(organizationID)
+	limit := s.limit(organizationID)
+	if limit <= 0 {
+		return nil
+	}
+
+	used, err := s.used(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	if used+size > limit {
+		return model.ErrQuotaExceeded
+	}
+	return nil
+}
+
+func (s *quotaService) Record(ctx context.Context, bucket, organizationID string, category model.Category, key string, size int64) {
+	if key == "" {
+		return
+	}
+
+	// tên bucket đã chuẩn hoá (bucket không có trong cấu hình rơi về mặc định), giống tên s3.Service dùng khi Release
+	storage := s.s3Service.For(bucket)
+	if size < 0 {
+		info, err := storage.Head(ctx, key)
+		if err != nil {
+			logger.WriteLogEx("warn", "failed to read object size for usage", map[string]any{
+				"bucket": bucket,
+				"key":    key,
+				"error":  err.Error(),
+			})
+			return
+		}
+		size = info.Size
+	}
+
+	err := s.repo.Record(ctx, &model.UsageEntry{
+		Bucket:         storage.Bucket(),
+		Key:            key,
+		OrganizationID: usageOwner(organizationID),
+		Category:       category,
+		Size:           size,
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		logger.WriteLogEx("error", "failed to record storage usage", map[string]any{
+			"organization_id": organizationID,
+			"key":             key,
+			"error":           err.Error(),
+		})
+	}
+}
+
+func (s *quotaService) Usage(ctx context.Context, organizationID string) (*model.Usage, error) {
+	summary, err := s.repo.Summary(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &model.Usage{
+		OrganizationID: organizationID,
+		QuotaBytes:     s.limit(organizationID),
+		ByType:         make(map[model.Category]int64, len(model.Categories)),
+	}
+	for _, category := range model.Categories {
+		usage.ByType[category] = 0
+	}
+	for category, size := range summary {
+		usage.ByType[category] = size
+		usage.UsedBytes += size
+	}
+	if usage.QuotaBytes > 0 {
+		remaining := usage.QuotaBytes - usage.UsedBytes
+		if remaining < 0 {
+			remaining = 0
+		}
+		usage.RemainingBytes = &remaining
+	}
+	return usage, nil
+}
+
+func (s *quotaService) used(ctx context.Context, organizationID string) (int64, error) {
+	summary, err := s.repo.Summary(ctx, organizationID)
+	if err != nil {
+		return 0, err
+	}
+	var used int64
+	for _, size := range summary {
+		used += size
+	}
+	return used, nil
+}
+
+// usageOwner là organization được tính dung lượng, upload không có organization tính vào global
+func usageOwner(organizationID string) string {
+	if organizationID == "" {
+		return model.GlobalOrganizationID
+	}
+	return organizationID
+}
+
+// limit trả về quota tính bằng byte, 0 = không giới hạn
+func (s *quotaService) limit(organizationID string) int64 {
+	cfg := config.AppConfig.Quota
+	if mb, ok := cfg.Organizations[organizationID]; ok {
+		return mb * bytesPerMB
+	}
+	return cfg.DefaultMB * bytesPerMB
+}
+
+func (s *quotaService) Backfill(ctx context.Context, dryRun bool) (*model.BackfillReport, error) {
+	report := &model.BackfillReport{DryRun: dryRun, StartedAt: time.Now()}
+
+	// entry kiểu cũ không biết object nằm ở bucket nào, bỏ đi rồi dựng lại từ document
+	legacy, err := s.repo.DeleteLegacy(ctx, dryRun)
+	if err != nil {
+		return report, err
+	}
+	report.LegacyEntries = legacy
+
+	err = s.objects.EachObject(ctx, func(obj model.Object) error {
+		report.ScannedObjects++
+		storage := s.s3Service.For(obj.Bucket)
+
+		exists, err := s.repo.Exists(ctx, storage.Bucket(), obj.Key)
+		if err != nil {
+			return err
+		}
+		if exists {
+			report.ExistingObjects++
+			return nil
+		}
+
+		info, err := storage.Head(ctx, obj.Key)
+		if errors.Is(err, uploader.ErrObjectNotFound) {
+			report.MissingObjects++
+			return nil
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("head %s: %v", obj.Key, err))
+			return nil
+		}
+
+		report.RecordedObjects++
+		report.RecordedBytes += info.Size
+		if dryRun {
+			return nil
+		}
+		if err := s.repo.Record(ctx, &model.UsageEntry{
+			Bucket:         storage.Bucket(),
+			Key:            obj.Key,
+			OrganizationID: usageOwner(obj.OrganizationID),
+			Category:       obj.Category,
+			Size:           info.Size,
+			CreatedAt:      time.Now(),
+		}); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("record %s: %v", obj.Key, err))
+		}
+		return nil
+	})
+	report.FinishedAt = time.Now()
+	if err != nil {
+		return report, err
+	}
+
+	logger.WriteLogEx("info", "storage usage backfill finished", map[string]any{
+		"dry_run":          dryRun,
+		"scanned_objects":  report.ScannedObjects,
+		"recorded_objects": report.RecordedObjects,
+		"recorded_bytes":   report.RecordedBytes,
+		"missing_objects":  report.MissingObjects,
+		"errors":           len(report.Errors),
+	})
+	return report, nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"media-service/pkg/db"
 	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -143,3 +144,19 @@ func (s *RedisService) HasAnyUploadInProgress(ctx context.Context) (bool, error)
 	}
 	return false, nil
 }
+
+// GetSignedURL trả về signed url đã cache, rỗng nếu chưa có
+func (s *RedisService) GetSignedURL(ctx context.Context, cacheKey string) (string, error) {
+	val, err := db.Client.Get(ctx, "signed_url:"+cacheKey).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get signed url: %w", err)
+	}
+	return val, nil
+}
+
+func (s *RedisService) SetSignedURL(ctx context.Context, cacheKey, signedURL string, ttl time.Duration) error {
+	return db.Client.Set(ctx, "signed_url:"+cacheKey, signedURL, ttl).Err()
+}
@@ -0,0 +1,48 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"media-service/pkg/uploader"
+)
+
+// objectReaderAt đọc object bằng Range GET, mỗi ReadAt là một request.
+// Chỉ phù hợp cho parser đọc vài đoạn header (mediaprobe), không dùng để tải cả file.
+type objectReaderAt struct {
+	ctx     context.Context
+	storage Service
+	key     string
+	size    int64
+}
+
+// NewReaderAt trả về io.ReaderAt trên object key và kích thước của object.
+func NewReaderAt(ctx context.Context, storage Service, key string) (io.ReaderAt, int64, error) {
+	info, err := storage.Head(ctx, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &objectReaderAt{ctx: ctx, storage: storage, key: key, size: info.Size}, info.Size, nil
+}
+
+func (o *objectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= o.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	end := min(off+int64(len(p)), o.size) - 1
+	obj, err := o.storage.Open(o.ctx, o.key, uploader.GetObjectOptions{Range: fmt.Sprintf("bytes=%d-%d", off, end)})
+	if err != nil {
+		return 0, err
+	}
+	defer obj.Body.Close()
+
+	n, err := io.ReadFull(obj.Body, p[:end-off+1])
+	if err == nil && n < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,103 @@
+package s3
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"media-service/logger"
+	"media-service/pkg/config"
+	"media-service/pkg/uploader"
+)
+
+// routes giữ service của từng bucket đã cấu hình, dùng chung giữa các service được tạo từ nó
+type routes struct {
+	buckets       map[string]*service // "" = bucket mặc định
+	names         []string
+	categories    map[string]string
+	organizations map[string]string
+}
+
+func newRoutes(defaultSvc *service, buckets map[string]config.SenboxFormSubmitBucket, routing config.StorageRouting) *routes {
+	r := &routes{
+		buckets:       map[string]*service{"": defaultSvc},
+		names:         []string{""},
+		categories:    map[string]string{},
+		organizations: map[string]string{},
+	}
+
+	for name, bucketCfg := range buckets {
+		if name == "" {
+			panic("invalid s3 buckets config: bucket name is required")
+		}
+		// local storage chỉ có một thư mục, mọi bucket đều trỏ về đó
+		if config.AppConfig.Storage.Provider == ProviderLocal {
+			r.buckets[name] = defaultSvc
+			continue
+		}
+		r.buckets[name] = &service{
+			name:        name,
+			provider:    NewProvider(config.StorageTarget{Provider: ProviderS3, S3: bucketCfg}),
+			routes:      r,
+			urlCache:    defaultSvc.urlCache,
+			cacheBucket: defaultSvc.cacheBucket,
+			cachePrefix: bucketCfg.BucketName,
+			usage:       defaultSvc.usage,
+		}
+		r.names = append(r.names, name)
+	}
+	sort.Strings(r.names[1:])
+
+	for category, name := range routing.Categories {
+		if !slices.Contains(options.Categories, category) {
+			panic(fmt.Sprintf("invalid storage routing config: unknown category %s", category))
+		}
+		r.categories[category] = r.mustExist(name)
+	}
+	for organizationID, name := range routing.Organizations {
+		r.organizations[organizationID] = r.mustExist(name)
+	}
+	return r
+}
+
+func (r *routes) mustExist(name string) string {
+	if _, ok := r.buckets[name]; !ok {
+		panic(fmt.Sprintf("invalid storage routing config: bucket %s is not declared in s3.buckets", name))
+	}
+	return name
+}
+
+func (s *service) Bucket() string {
+	return s.name
+}
+
+func (s *service) Provider() uploader.UploadProvider {
+	return s.provider
+}
+
+func (s *service) For(bucket string) Service {
+	if bucket == s.name {
+		return s
+	}
+	if svc, ok := s.routes.buckets[bucket]; ok {
+		return svc
+	}
+	// bucket đã bị bỏ khỏi cấu hình: đọc bucket mặc định còn hơn trả lỗi cho mọi request
+	logger.WriteLogEx("warn", "unknown storage bucket, falling back to default", bucket)
+	return s.routes.buckets[""]
+}
+
+func (s *service) Route(category string, organizationID string) Service {
+	if name, ok := s.routes.organizations[organizationID]; ok && organizationID != "" {
+		return s.For(name)
+	}
+	return s.For(s.routes.categories[category])
+}
+
+func (s *service) Buckets() []Service {
+	out := make([]Service, 0, len(s.routes.names))
+	for _, name := range s.routes.names {
+		out = append(out, s.routes.buckets[name])
+	}
+	return out
+}
@@ -2,27 +2,173 @@ package s3
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"sync"
 	"time"
 
+	"media-service/internal/redis"
+	"media-service/logger"
 	"media-service/pkg/config"
+	"media-service/pkg/db"
 	"media-service/pkg/uploader"
 )
 
+const (
+	ProviderS3    = "s3"
+	ProviderLocal = "local"
+)
+
 type Service interface {
+	// Bucket là tên bucket trong cấu hình (s3.buckets) mà service này đọc / ghi, "" = bucket mặc định.
+	// Lưu giá trị này cùng document để lần đọc sau gọi For đúng bucket.
+	Bucket() string
+	// For trả về service của bucket đã lưu trên document
+	For(bucket string) Service
+	// Route chọn bucket cho document mới theo storage.routing, category là một trong Options.Categories
+	Route(category string, organizationID string) Service
+	// Buckets trả về service của mọi bucket đã cấu hình, bucket mặc định đứng đầu
+	Buckets() []Service
+	// Provider trả về provider bên dưới của bucket này, vd để phục vụ url ký bởi local provider
+	Provider() uploader.UploadProvider
+
 	Save(ctx context.Context, data []byte, key string, mode uploader.UploadMode) (*string, error)
 	SaveReader(ctx context.Context, r io.Reader, key string, contentType string, mode uploader.UploadMode) (*string, error)
+	// SaveReaderChecked kiểm tra nội dung với checksum client gửi (expected, có thể nil) và trả về
+	// checksum đã xác minh để lưu cùng key. Không khớp → uploader.ErrChecksumMismatch, object không được giữ lại.
+	SaveReaderChecked(ctx context.Context, r io.Reader, key string, contentType string, mode uploader.UploadMode, expected *uploader.Checksum) (*string, *uploader.Checksum, error)
 	Get(ctx context.Context, key string, duration *time.Duration) (*string, error)
+	// Sign giống Get nhưng trả về kèm thời điểm url hết hạn
+	Sign(ctx context.Context, key string, duration *time.Duration) (*SignedURL, error)
 	Delete(ctx context.Context, key string) error
+
+	CreateMultipartUpload(ctx context.Context, key string, contentType string) (string, error)
+	UploadPart(ctx context.Context, key, uploadID string, partNumber int32, r io.Reader, size int64) (string, error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []uploader.CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+
+	PresignPut(ctx context.Context, key string, contentType string, mode uploader.UploadMode, duration time.Duration) (string, map[string]string, error)
+	Head(ctx context.Context, key string) (*uploader.ObjectInfo, error)
+	List(ctx context.Context, prefix string, fn func(uploader.ObjectInfo) error) error
+	Open(ctx context.Context, key string, opts uploader.GetObjectOptions) (*uploader.ObjectReader, error)
+	Copy(ctx context.Context, srcKey, destKey string) error
+
+	// tiering (xem internal/tiering)
+	SetStorageClass(ctx context.Context, key string, class uploader.StorageClass) error
+	Tag(ctx context.Context, key string, tags map[string]string) error
+	Restore(ctx context.Context, key string, days int32, tier string) error
+}
+
+// SignedURL is a download url and the moment it stops working; ExpiresAt is nil for public urls.
+type SignedURL struct {
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
+const (
+	defaultSignedURLBucket = time.Hour
+	defaultSignedURLTTL    = 24 * time.Hour
+	// không giữ cache quá lâu dù url còn hạn dài
+	maxSignedURLCacheTTL = 24 * time.Hour
+)
+
 type service struct {
+	name     string
 	provider uploader.UploadProvider
+	routes   *routes
+
+	// cache signed url theo (key, mốc hết hạn), nil = không cache
+	urlCache    *redis.RedisService
+	cacheBucket time.Duration
+	cachePrefix string
+
+	// sổ dung lượng theo organization, nil = không theo dõi
+	usage UsageTracker
+}
+
+// UsageTracker is told about every object the storage deletes or copies so the usage
+// of its owner stays in step. bucket is the configured bucket name, "" for the default one.
+type UsageTracker interface {
+	Release(ctx context.Context, bucket, key string) error
+	Copy(ctx context.Context, bucket, srcKey, destKey string) error
+}
+
+// Options are the pieces of the storage layer that belong to feature packages and are
+// handed in by the composition root instead of being imported here.
+type Options struct {
+	// MirrorPrefixes receive the encryption policy of every s3.encryption.prefixes entry
+	// placed after them, e.g. the trash prefix that keeps the original key behind it.
+	MirrorPrefixes []string
+	// Usage tracks deletes and copies, nil disables tracking.
+	Usage UsageTracker
+	// Categories are the values storage.routing.categories may use.
+	Categories []string
 }
 
+var (
+	defaultOnce    sync.Once
+	defaultService Service
+	options        Options
+)
+
+// Configure sets the options used by NewFromConfig and NewProvider. It must be called
+// before the first of them, the process-wide service is built only once.
+func Configure(opts Options) {
+	options = opts
+}
+
+// NewFromConfig returns the process-wide storage service. The provider (and the
+// CloudFront private key) is only built once no matter how many dependencies ask for it.
 func NewFromConfig() Service {
-	s3Cfg := config.AppConfig.S3.SenboxFormSubmitBucket
-	provider := uploader.NewS3Provider(
+	defaultOnce.Do(func() {
+		defaultService = newFromConfig()
+	})
+	return defaultService
+}
+
+func newFromConfig() Service {
+	svc := &service{usage: options.Usage}
+
+	cacheCfg := config.AppConfig.Storage.SignedURLCache
+	if cacheCfg.Enabled && db.Client != nil {
+		svc.urlCache = redis.NewRedisService()
+		svc.cacheBucket = defaultSignedURLBucket
+		if cacheCfg.BucketMinutes > 0 {
+			svc.cacheBucket = time.Duration(cacheCfg.BucketMinutes) * time.Minute
+		}
+	}
+
+	target := config.StorageTarget{
+		Provider: config.AppConfig.Storage.Provider,
+		Local:    config.AppConfig.Storage.Local,
+		S3:       config.AppConfig.S3.SenboxFormSubmitBucket,
+	}
+	svc.provider = NewProvider(target)
+	svc.cachePrefix = target.S3.BucketName
+	if target.Provider == ProviderLocal {
+		svc.cachePrefix = ProviderLocal
+	}
+	svc.routes = newRoutes(svc, config.AppConfig.S3.Buckets, config.AppConfig.Storage.Routing)
+	return svc
+}
+
+// NewProvider builds the upload provider described by target, without any of the
+// caching / usage tracking of Service. Used on its own by tools that talk to several storages.
+func NewProvider(target config.StorageTarget) uploader.UploadProvider {
+	if target.Provider == ProviderLocal {
+		provider, err := uploader.NewLocalProvider(
+			target.Local.RootDir,
+			target.Local.BaseURL,
+			target.Local.SigningSecret,
+		)
+		if err != nil {
+			panic(fmt.Sprintf("invalid storage config: %v", err))
+		}
+		return provider
+	}
+
+	s3Cfg := target.S3
+	return uploader.NewS3Provider(
 		s3Cfg.AccessKey,
 		s3Cfg.SecretKey,
 		s3Cfg.BucketName,
@@ -30,22 +176,230 @@ func NewFromConfig() Service {
 		s3Cfg.Domain,
 		s3Cfg.CloudfrontKeyGroupID,
 		s3Cfg.CloudfrontKeyPath,
+		uploader.WithEndpoint(s3Cfg.Endpoint),
+		uploader.WithPathStyle(s3Cfg.UsePathStyle),
+		uploader.WithURLStrategy(s3Cfg.URLStrategy),
+		uploader.WithEncryption(encryptionRules(s3Cfg.Encryption)),
+		uploader.WithPublicBaseURL(s3Cfg.PublicBaseURL),
 	)
-	return &service{provider: provider}
+}
+
+func encryptionRules(cfg config.EncryptionConfig) uploader.EncryptionRules {
+	build := func(name string, p config.EncryptionPolicy) uploader.Encryption {
+		enc, err := uploader.NewEncryption(p.Type, p.KMSKeyID, p.CustomerKey)
+		if err != nil {
+			panic(fmt.Sprintf("invalid s3 encryption config (%s): %v", name, err))
+		}
+		return enc
+	}
+
+	rules := uploader.EncryptionRules{
+		Default:  build("default", cfg.Default),
+		Modes:    map[uploader.UploadMode]uploader.Encryption{},
+		Prefixes: map[string]uploader.Encryption{},
+	}
+	for name, p := range cfg.Modes {
+		mode, err := uploader.UploadModeFromString(name)
+		if err != nil {
+			panic(fmt.Sprintf("invalid s3 encryption config: unknown mode %s", name))
+		}
+		rules.Modes[mode] = build("mode "+name, p)
+	}
+	for prefix, p := range cfg.Prefixes {
+		rules.Prefixes[prefix] = build("prefix "+prefix, p)
+	}
+	// bản sao dưới prefix khác (vd thùng rác) giữ cùng policy với key gốc (và đọc lại được nếu là SSE-C)
+	for _, mirror := range options.MirrorPrefixes {
+		for prefix, p := range cfg.Prefixes {
+			if _, ok := cfg.Prefixes[mirror+prefix]; !ok {
+				rules.Prefixes[mirror+prefix] = build("prefix "+prefix, p)
+			}
+		}
+	}
+	return rules
 }
 
 func (s *service) Save(ctx context.Context, data []byte, key string, mode uploader.UploadMode) (*string, error) {
-	return s.provider.SaveFileUploaded(ctx, data, key, mode)
+	// gửi kèm checksum để bucket xác nhận đã nhận đúng nội dung
+	return s.provider.SaveFileUploaded(ctx, data, key, mode, uploader.ComputeChecksum(uploader.ChecksumSHA256, data))
 }
 
 func (s *service) SaveReader(ctx context.Context, r io.Reader, key string, contentType string, mode uploader.UploadMode) (*string, error) {
-	return s.provider.SaveFileUploadedReader(ctx, r, key, contentType, mode)
+	url, _, err := s.SaveReaderChecked(ctx, r, key, contentType, mode, nil)
+	return url, err
+}
+
+func (s *service) SaveReaderChecked(ctx context.Context, r io.Reader, key string, contentType string, mode uploader.UploadMode, expected *uploader.Checksum) (*string, *uploader.Checksum, error) {
+	alg := uploader.ChecksumSHA256
+	if expected != nil {
+		alg = expected.Algorithm
+	}
+	hasher := uploader.NewChecksumHasher(alg)
+
+	// file upload (multipart.File) đọc lại được: tính trước để từ chối sớm và để bucket tự kiểm tra lúc PutObject
+	if rs, ok := r.(io.ReadSeeker); ok {
+		if _, err := io.Copy(hasher, rs); err != nil {
+			return nil, nil, err
+		}
+		actual := hasher.Checksum()
+		if err := expected.Verify(actual); err != nil {
+			return nil, nil, err
+		}
+		if _, err := rs.Seek(0, io.SeekStart); err != nil {
+			return nil, nil, err
+		}
+		url, err := s.provider.SaveFileUploadedReader(ctx, rs, key, contentType, mode, actual)
+		if err != nil {
+			return nil, nil, err
+		}
+		return url, actual, nil
+	}
+
+	// stream một chiều: tính trong lúc upload, bucket vẫn kiểm tra expected nếu có
+	url, err := s.provider.SaveFileUploadedReader(ctx, io.TeeReader(r, hasher), key, contentType, mode, expected)
+	if err != nil {
+		return nil, nil, err
+	}
+	actual := hasher.Checksum()
+	if err := expected.Verify(actual); err != nil {
+		_ = s.provider.DeleteFileUploaded(ctx, key)
+		return nil, nil, err
+	}
+	return url, actual, nil
 }
 
 func (s *service) Get(ctx context.Context, key string, duration *time.Duration) (*string, error) {
-	return s.provider.GetFileUploaded(ctx, key, duration)
+	signed, err := s.Sign(ctx, key, duration)
+	if err != nil {
+		return nil, err
+	}
+	return &signed.URL, nil
+}
+
+func (s *service) Sign(ctx context.Context, key string, duration *time.Duration) (*SignedURL, error) {
+	d := defaultSignedURLTTL
+	if duration != nil {
+		d = *duration
+	}
+	now := time.Now()
+
+	// provider có thể ký ngắn hơn yêu cầu (presigned GET tối đa 7 ngày), 0 = url public không hết hạn
+	lifetime := s.provider.URLLifetime(key, d)
+	if lifetime <= 0 {
+		url, err := s.provider.GetFileUploaded(ctx, key, &d)
+		if err != nil {
+			return nil, err
+		}
+		return &SignedURL{URL: *url}, nil
+	}
+
+	bucket := s.cacheBucket
+	if lifetime < bucket {
+		bucket = lifetime
+	}
+	if s.urlCache == nil || bucket <= 0 {
+		expiresAt := now.Add(lifetime)
+		url, err := s.provider.GetFileUploaded(ctx, key, &lifetime)
+		if err != nil {
+			return nil, err
+		}
+		return &SignedURL{URL: *url, ExpiresAt: &expiresAt}, nil
+	}
+
+	// làm tròn thời điểm hết hạn lên mốc bucket → mọi request trong cùng cửa sổ dùng chung một url,
+	// url luôn còn hạn ít nhất bằng duration được yêu cầu (trừ khi provider giới hạn thời hạn, khi đó làm tròn xuống)
+	expiresAt := now.Add(lifetime).Truncate(bucket).Add(bucket)
+	if lifetime < d {
+		expiresAt = now.Add(lifetime).Truncate(bucket)
+	}
+	cacheKey := fmt.Sprintf("%s:%d:%s", s.cachePrefix, expiresAt.Unix(), key)
+
+	if cached, err := s.urlCache.GetSignedURL(ctx, cacheKey); err != nil {
+		logger.WriteLogEx("warn", "signed url cache read failed", err)
+	} else if cached != "" {
+		return &SignedURL{URL: cached, ExpiresAt: &expiresAt}, nil
+	}
+
+	signDuration := expiresAt.Sub(now)
+	signedURL, err := s.provider.GetFileUploaded(ctx, key, &signDuration)
+	if err != nil {
+		return nil, err
+	}
+	cacheTTL := signDuration
+	if cacheTTL > maxSignedURLCacheTTL {
+		cacheTTL = maxSignedURLCacheTTL
+	}
+	if err := s.urlCache.SetSignedURL(ctx, cacheKey, *signedURL, cacheTTL); err != nil {
+		logger.WriteLogEx("warn", "signed url cache write failed", err)
+	}
+	return &SignedURL{URL: *signedURL, ExpiresAt: &expiresAt}, nil
 }
 
 func (s *service) Delete(ctx context.Context, key string) error {
-	return s.provider.DeleteFileUploaded(ctx, key)
+	if err := s.provider.DeleteFileUploaded(ctx, key); err != nil {
+		return err
+	}
+	if s.usage != nil {
+		if err := s.usage.Release(ctx, s.name, key); err != nil {
+			logger.WriteLogEx("warn", "failed to release storage usage", err)
+		}
+	}
+	return nil
+}
+
+func (s *service) CreateMultipartUpload(ctx context.Context, key string, contentType string) (string, error) {
+	return s.provider.CreateMultipartUpload(ctx, key, contentType)
+}
+
+func (s *service) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, r io.Reader, size int64) (string, error) {
+	return s.provider.UploadPart(ctx, key, uploadID, partNumber, r, size)
+}
+
+func (s *service) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []uploader.CompletedPart) error {
+	return s.provider.CompleteMultipartUpload(ctx, key, uploadID, parts)
+}
+
+func (s *service) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	return s.provider.AbortMultipartUpload(ctx, key, uploadID)
+}
+
+func (s *service) PresignPut(ctx context.Context, key string, contentType string, mode uploader.UploadMode, duration time.Duration) (string, map[string]string, error) {
+	return s.provider.PresignPutObject(ctx, key, contentType, mode, duration)
+}
+
+func (s *service) Head(ctx context.Context, key string) (*uploader.ObjectInfo, error) {
+	return s.provider.HeadObject(ctx, key)
+}
+
+func (s *service) List(ctx context.Context, prefix string, fn func(uploader.ObjectInfo) error) error {
+	return s.provider.ListObjects(ctx, prefix, fn)
+}
+
+func (s *service) Open(ctx context.Context, key string, opts uploader.GetObjectOptions) (*uploader.ObjectReader, error) {
+	return s.provider.GetObject(ctx, key, opts)
+}
+
+func (s *service) Copy(ctx context.Context, srcKey, destKey string) error {
+	if err := s.provider.CopyObject(ctx, srcKey, destKey); err != nil {
+		return err
+	}
+	// bản sao (ví dụ trong thùng rác) vẫn tính vào dung lượng của organization
+	if s.usage != nil {
+		if err := s.usage.Copy(ctx, s.name, srcKey, destKey); err != nil {
+			logger.WriteLogEx("warn", "failed to copy storage usage", err)
+		}
+	}
+	return nil
+}
+
+func (s *service) SetStorageClass(ctx context.Context, key string, class uploader.StorageClass) error {
+	return s.provider.SetStorageClass(ctx, key, class)
+}
+
+func (s *service) Tag(ctx context.Context, key string, tags map[string]string) error {
+	return s.provider.TagObject(ctx, key, tags)
+}
+
+func (s *service) Restore(ctx context.Context, key string, days int32, tier string) error {
+	return s.provider.RestoreObject(ctx, key, days, tier)
 }
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"media-service/helper"
+	"media-service/internal/storagegc/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type GCHandler struct {
+	svc service.GCService
+}
+
+func NewGCHandler(svc service.GCService) *GCHandler {
+	return &GCHandler{svc: svc}
+}
+
+// Run chạy GC ngay lập tức, mặc định dry_run=true
+func (h *GCHandler) Run(c *fiber.Ctx) error {
+	dryRun := c.Query("dry_run") != "false"
+	report, err := h.svc.Run(c.UserContext(), dryRun)
+	if err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "storage gc finished", report)
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+type OrphanObject struct {
+	Key          string    `json:"key"`
+	Bucket       string    `json:"bucket,omitempty"` // tên trong s3.buckets, rỗng = bucket mặc định
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// Report là kết quả của một lần chạy GC
+type Report struct {
+	DryRun         bool           `json:"dry_run"`
+	GracePeriod    string         `json:"grace_period"`
+	Prefixes       []string       `json:"prefixes"`
+	ReferencedKeys int            `json:"referenced_keys"`
+	ScannedObjects int            `json:"scanned_objects"`
+	OrphanCount    int            `json:"orphan_count"`
+	OrphanBytes    int64          `json:"orphan_bytes"`
+	DeletedCount   int            `json:"deleted_count"`
+	Orphans        []OrphanObject `json:"orphans"` // tối đa MaxReportedOrphans phần tử
+	Errors         []string       `json:"errors,omitempty"`
+	StartedAt      time.Time      `json:"started_at"`
+	FinishedAt     time.Time      `json:"finished_at"`
+}
+
+const MaxReportedOrphans = 1000
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// keyFields là tên các field chứa object key trong các collection media
+var keyFields = map[string]bool{
+	"key":               true,
+	"image_key":         true,
+	"audio_key":         true,
+	"video_key":         true,
+	"image_preview_key": true,
+	"pdf_key":           true,
+	"signature_key":     true,
+}
+
+// IsKeyField reports whether a document field holds an object key.
+func IsKeyField(field string) bool {
+	return keyFields[field]
+}
+
+type ReferenceRepository interface {
+	// ReferencedKeys trả về tất cả object key đang được document nào đó tham chiếu
+	ReferencedKeys(ctx context.Context) (map[string]struct{}, error)
+	// MediaAssetFolders trả về các folder gốc đang được media asset sử dụng
+	MediaAssetFolders(ctx context.Context) ([]string, error)
+}
+
+type referenceRepository struct {
+	collections      []*mongo.Collection
+	mediaAssetCol    *mongo.Collection
+	uploadSessionCol *mongo.Collection
+}
+
+// NewReferenceRepository nhận các collection có chứa object key (topics, vocabularies, topic_resources,
+// video_uploaders, pdf_resources, media_assets) cùng upload_sessions.
+func NewReferenceRepository(mediaAssetCol, uploadSessionCol *mongo.Collection, collections ...*mongo.Collection) ReferenceRepository {
+	return &referenceRepository{
+		collections:      append(collections, mediaAssetCol),
+		mediaAssetCol:    mediaAssetCol,
+		uploadSessionCol: uploadSessionCol,
+	}
+}
+
+func (r *referenceRepository) ReferencedKeys(ctx context.Context) (map[string]struct{}, error) {
+	keys := map[string]struct{}{}
+
+	for _, col := range r.collections {
+		if err := r.collectKeys(ctx, col, bson.M{}, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	// upload đang dở hoặc đã xong nhưng chưa được gắn vào đâu vẫn phải giữ object
+	if r.uploadSessionCol != nil {
+		filter := bson.M{"status": bson.M{"$in": []string{"pending", "completed"}}}
+		if err := r.collectKeys(ctx, r.uploadSessionCol, filter, keys); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func (r *referenceRepository) MediaAssetFolders(ctx context.Context) ([]string, error) {
+	values, err := r.mediaAssetCol.Distinct(ctx, "key", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	folders := make([]string, 0)
+	for _, v := range values {
+		key, ok := v.(string)
+		if !ok {
+			continue
+		}
+		idx := strings.Index(key, "/")
+		if idx <= 0 {
+			continue
+		}
+		folder := key[:idx+1]
+		if !seen[folder] {
+			seen[folder] = true
+			folders = append(folders, folder)
+		}
+	}
+	return folders, nil
+}
+
+func (r *referenceRepository) collectKeys(ctx context.Context, col *mongo.Collection, filter bson.M, keys map[string]struct{}) error {
+	cursor, err := col.Find(ctx, filter, options.Find().SetBatchSize(500))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		walk(doc, keys)
+	}
+	return cursor.Err()
+}
+
+// walk duyệt đệ quy document (language_config, images... là mảng lồng nhau)
+func walk(v interface{}, keys map[string]struct{}) {
+	switch val := v.(type) {
+	case bson.M:
+		for field, child := range val {
+			if s, ok := child.(string); ok {
+				if keyFields[field] && s != "" {
+					keys[s] = struct{}{}
+				}
+				continue
+			}
+			walk(child, keys)
+		}
+	case bson.D:
+		for _, e := range val {
+			walk(bson.M{e.Key: e.Value}, keys)
+		}
+	case bson.A:
+		for _, child := range val {
+			walk(child, keys)
+		}
+	case []interface{}:
+		for _, child := range val {
+			walk(child, keys)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package route
+
+import (
+	"media-service/internal/gateway"
+	"media-service/internal/middleware"
+	"media-service/internal/storagegc/handler"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterGCRoutes(app *fiber.App, h *handler.GCHandler, userGw gateway.UserGateway) {
+	admin := app.Group("/api/v2/admin/storage")
+	admin.Use(middleware.Secured(userGw))
+
+	admin.Post("/gc", middleware.RequireAdmin(), h.Run)
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"media-service/internal/s3"
+	"media-service/internal/storagegc/model"
+	"media-service/internal/storagegc/repository"
+	trashModel "media-service/internal/trash/model"
+	"media-service/logger"
+	"media-service/pkg/config"
+	"media-service/pkg/uploader"
+)
+
+const (
+	defaultIntervalHours    = 24
+	defaultGracePeriodHours = 72
+)
+
+// defaultPrefixes là các folder mà service tự ghi object vào (key policy legacy và scoped)
+var defaultPrefixes = []string{
+	"topic_media/",
+	"vocabulary_media/",
+	"topic_resource/",
+	"media_video_uploader/",
+	"pdf_media/",
+	"uploads/",
+	uploader.PublicPrefix,
+}
+
+type GCService interface {
+	// Run quét bucket một lần, dryRun = true chỉ báo cáo không xoá
+	Run(ctx context.Context, dryRun bool) (*model.Report, error)
+	// Start chạy GC định kỳ cho tới khi ctx bị huỷ
+	Start(ctx context.Context)
+}
+
+type gcService struct {
+	repo        repository.ReferenceRepository
+	s3Service   s3.Service
+	gracePeriod time.Duration
+	interval    time.Duration
+	dryRun      bool
+	prefixes    []string
+
+	running sync.Mutex // không cho hai lần chạy chồng lên nhau
+}
+
+func NewGCService(repo repository.ReferenceRepository, s3Service s3.Service) GCService {
+	cfg := config.AppConfig.GC
+
+	graceHours := cfg.GracePeriodHours
+	if graceHours <= 0 {
+		graceHours = defaultGracePeriodHours
+	}
+	intervalHours := cfg.IntervalHours
+	if intervalHours <= 0 {
+		intervalHours = defaultIntervalHours
+	}
+
+	return &gcService{
+		repo:        repo,
+		s3Service:   s3Service,
+		gracePeriod: time.Duration(graceHours) * time.Hour,
+		interval:    time.Duration(intervalHours) * time.Hour,
+		dryRun:      cfg.DryRun,
+		prefixes:    append(append([]string{}, defaultPrefixes...), cfg.Prefixes...),
+	}
+}
+
+func (s *gcService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := s.Run(ctx, s.dryRun)
+				if err != nil {
+					logger.WriteLogEx("error", "storage gc failed", err)
+					continue
+				}
+				logger.WriteLogData("info", report)
+			}
+		}
+	}()
+}
+
+func (s *gcService) Run(ctx context.Context, dryRun bool) (*model.Report, error) {
+	if !s.running.TryLock() {
+		return nil, fmt.Errorf("storage gc is already running")
+	}
+	defer s.running.Unlock()
+
+	report := &model.Report{
+		DryRun:      dryRun,
+		GracePeriod: s.gracePeriod.String(),
+		Orphans:     []model.OrphanObject{},
+		StartedAt:   time.Now(),
+	}
+
+	prefixes, err := s.resolvePrefixes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Prefixes = prefixes
+
+	// lấy danh sách key trước khi list bucket: object được upload sau thời điểm này
+	// chắc chắn mới hơn cutoff nên không bị xoá nhầm
+	referenced, err := s.repo.ReferencedKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.ReferencedKeys = len(referenced)
+	cutoff := time.Now().Add(-s.gracePeriod)
+
+	// mọi bucket dùng chung danh sách key: key được tham chiếu ở bất kỳ đâu đều được giữ lại
+	for _, storage := range s.s3Service.Buckets() {
+		for _, prefix := range prefixes {
+			s.collect(ctx, storage, prefix, referenced, cutoff, dryRun, report)
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+func (s *gcService) collect(ctx context.Context, storage s3.Service, prefix string, referenced map[string]struct{}, cutoff time.Time, dryRun bool, report *model.Report) {
+	err := storage.List(ctx, prefix, func(obj uploader.ObjectInfo) error {
+		report.ScannedObjects++
+		if _, ok := referenced[obj.Key]; ok {
+			return nil
+		}
+		if obj.LastModified.After(cutoff) {
+			return nil
+		}
+
+		report.OrphanCount++
+		report.OrphanBytes += obj.Size
+		if len(report.Orphans) < model.MaxReportedOrphans {
+			report.Orphans = append(report.Orphans, model.OrphanObject{
+				Key:          obj.Key,
+				Bucket:       storage.Bucket(),
+				Size:         obj.Size,
+				LastModified: obj.LastModified,
+			})
+		}
+
+		if dryRun {
+			return nil
+		}
+		if err := storage.Delete(ctx, obj.Key); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", obj.Key, err))
+			return nil
+		}
+		report.DeletedCount++
+		return nil
+	})
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("list %s: %v", prefix, err))
+	}
+}
+
+// resolvePrefixes gộp prefix mặc định, prefix cấu hình và folder của media asset, bỏ prefix lồng nhau
+func (s *gcService) resolvePrefixes(ctx context.Context) ([]string, error) {
+	folders, err := s.repo.MediaAssetFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	all := append(append([]string{}, s.prefixes...), folders...)
+	normalized := make([]string, 0, len(all))
+	for _, p := range all {
+		p = strings.Trim(strings.TrimSpace(p), "/")
+		if p == "" {
+			// prefix rỗng = cả bucket, không cho phép
+			continue
+		}
+		if strings.HasPrefix(p+"/", trashModel.Prefix) {
+			// object trong thùng rác do job purge quản lý
+			continue
+		}
+		normalized = append(normalized, p+"/")
+	}
+	sort.Strings(normalized)
+
+	out := make([]string, 0, len(normalized))
+	for _, p := range normalized {
+		if len(out) > 0 && strings.HasPrefix(p, out[len(out)-1]) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rewrite đổi prefix của key khi chép sang storage mới, ví dụ "topic_media/" -> "org/abc/topic_media/"
+type Rewrite struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ParseRewrite đọc rewrite dạng "from=to"
+func ParseRewrite(s string) (Rewrite, error) {
+	from, to, ok := strings.Cut(s, "=")
+	if !ok || from == "" {
+		return Rewrite{}, fmt.Errorf("invalid rewrite %q, expected from=to", s)
+	}
+	return Rewrite{From: from, To: to}, nil
+}
+
+// Apply trả về giá trị mới theo rewrite đầu tiên khớp prefix
+func Apply(rewrites []Rewrite, value string) (string, bool) {
+	for _, rw := range rewrites {
+		if strings.HasPrefix(value, rw.From) {
+			return rw.To + strings.TrimPrefix(value, rw.From), true
+		}
+	}
+	return value, false
+}
+
+type Options struct {
+	DryRun      bool
+	Concurrency int
+	// StatePath là file ghi lại các key đã chép xong, chạy lại với cùng file sẽ bỏ qua các key đó
+	StatePath string
+	// KeyRewrites đổi key đích, document tham chiếu được cập nhật theo (trừ khi DryRun)
+	KeyRewrites []Rewrite
+	// URLRewrites đổi các url còn lưu trong document (field *_url), ví dụ domain cũ -> domain mới
+	URLRewrites []Rewrite
+}
+
+type Failure struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// Report là kết quả của một lần migrate-storage
+type Report struct {
+	DryRun           bool      `json:"dry_run"`
+	ReferencedKeys   int       `json:"referenced_keys"`
+	CopiedObjects    int       `json:"copied_objects"`
+	CopiedBytes      int64     `json:"copied_bytes"`
+	SkippedObjects   int       `json:"skipped_objects"` // đã có ở đích (cùng size) hoặc đã xong ở lần chạy trước
+	MissingObjects   int       `json:"missing_objects"` // được tham chiếu nhưng không có ở nguồn
+	RewrittenKeys    int       `json:"rewritten_keys"`
+	UpdatedDocuments int       `json:"updated_documents"`
+	Failures         []Failure `json:"failures"`
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+}
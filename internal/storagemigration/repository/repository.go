@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	gcRepository "media-service/internal/storagegc/repository"
+	"media-service/internal/storagemigration/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReferenceRepository interface {
+	// UpdateReferences thay object key (theo keys: cũ -> mới) và url đã lưu (theo urlRewrites) trong mọi document,
+	// dryRun = true chỉ đếm. Trả về số document bị thay đổi.
+	UpdateReferences(ctx context.Context, keys map[string]string, urlRewrites []model.Rewrite, dryRun bool) (int, error)
+}
+
+type referenceRepository struct {
+	collections []*mongo.Collection
+	usageCol    *mongo.Collection
+}
+
+// NewReferenceRepository nhận storage_usage (mỗi entry một bucket + key) và các collection chứa object key / url.
+func NewReferenceRepository(usageCol *mongo.Collection, collections ...*mongo.Collection) ReferenceRepository {
+	return &referenceRepository{collections: collections, usageCol: usageCol}
+}
+
+func (r *referenceRepository) UpdateReferences(ctx context.Context, keys map[string]string, urlRewrites []model.Rewrite, dryRun bool) (int, error) {
+	updated := 0
+	for _, col := range r.collections {
+		n, err := r.updateCollection(ctx, col, keys, urlRewrites, dryRun)
+		updated += n
+		if err != nil {
+			return updated, err
+		}
+	}
+
+	if r.usageCol == nil {
+		return updated, nil
+	}
+	n, err := r.renameUsage(ctx, keys, dryRun)
+	return updated + n, err
+}
+
+func (r *referenceRepository) updateCollection(ctx context.Context, col *mongo.Collection, keys map[string]string, urlRewrites []model.Rewrite, dryRun bool) (int, error) {
+	cursor, err := col.Find(ctx, bson.M{}, options.Find().SetBatchSize(500))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return updated, err
+		}
+
+		// chỉ $set các field cấp 1 có thay đổi để không ghi đè field khác
+		set := bson.M{}
+		for field, child := range doc {
+			if field == "_id" {
+				continue
+			}
+			if value, changed := rewrite(field, child, keys, urlRewrites); changed {
+				set[field] = value
+			}
+		}
+		if len(set) == 0 {
+			continue
+		}
+		updated++
+		if dryRun {
+			continue
+		}
+		if _, err := col.UpdateOne(ctx, bson.M{"_id": doc["_id"]}, bson.M{"$set": set}); err != nil {
+			return updated, err
+		}
+	}
+	return updated, cursor.Err()
+}
+
+// renameUsage chuyển entry dung lượng sang key mới, ở mọi bucket có entry của key cũ
+func (r *referenceRepository) renameUsage(ctx context.Context, keys map[string]string, dryRun bool) (int, error) {
+	renamed := 0
+	for oldKey, newKey := range keys {
+		if oldKey == newKey {
+			continue
+		}
+		cursor, err := r.usageCol.Find(ctx, bson.M{"key": oldKey}, options.Find().SetProjection(bson.M{"_id": 1}))
+		if err != nil {
+			return renamed, err
+		}
+		var entries []bson.M
+		if err := cursor.All(ctx, &entries); err != nil {
+			return renamed, err
+		}
+		for _, entry := range entries {
+			renamed++
+			if dryRun {
+				continue
+			}
+			_, err := r.usageCol.UpdateOne(ctx, bson.M{"_id": entry["_id"]}, bson.M{"$set": bson.M{"key": newKey}})
+			if mongo.IsDuplicateKeyError(err) {
+				// bucket đã có entry của key mới, bỏ entry cũ để không tính hai lần
+				_, err = r.usageCol.DeleteOne(ctx, bson.M{"_id": entry["_id"]})
+			}
+			if err != nil {
+				return renamed, err
+			}
+		}
+	}
+	return renamed, nil
+}
+
+// rewrite thay giá trị tại field, document / mảng lồng nhau được sửa tại chỗ
+func rewrite(field string, v interface{}, keys map[string]string, urlRewrites []model.Rewrite) (interface{}, bool) {
+	switch val := v.(type) {
+	case string:
+		if val == "" {
+			return val, false
+		}
+		if gcRepository.IsKeyField(field) {
+			if newKey, ok := keys[val]; ok && newKey != val {
+				return newKey, true
+			}
+			return val, false
+		}
+		if strings.HasSuffix(strings.ToLower(field), "url") {
+			return model.Apply(urlRewrites, val)
+		}
+		return val, false
+	case bson.M:
+		changed := false
+		for name, child := range val {
+			if value, ok := rewrite(name, child, keys, urlRewrites); ok {
+				val[name] = value
+				changed = true
+			}
+		}
+		return val, changed
+	case bson.A:
+		changed := false
+		for i, child := range val {
+			if value, ok := rewrite(field, child, keys, urlRewrites); ok {
+				val[i] = value
+				changed = true
+			}
+		}
+		return val, changed
+	}
+	return v, false
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gcRepository "media-service/internal/storagegc/repository"
+	"media-service/internal/storagemigration/model"
+	"media-service/internal/storagemigration/repository"
+	trashModel "media-service/internal/trash/model"
+	"media-service/pkg/uploader"
+)
+
+const (
+	defaultConcurrency = 8
+	progressEvery      = 500
+)
+
+type MigrationService interface {
+	// Run chép mọi object được Mongo tham chiếu từ storage nguồn sang storage đích
+	Run(ctx context.Context, opts model.Options) (*model.Report, error)
+}
+
+type migrationService struct {
+	keys        gcRepository.ReferenceRepository
+	refs        repository.ReferenceRepository
+	source      uploader.UploadProvider
+	destination uploader.UploadProvider
+}
+
+func NewMigrationService(keys gcRepository.ReferenceRepository, refs repository.ReferenceRepository, source, destination uploader.UploadProvider) MigrationService {
+	return &migrationService{
+		keys:        keys,
+		refs:        refs,
+		source:      source,
+		destination: destination,
+	}
+}
+
+// copyJob là một object cần chép, trash = bản trong thùng rác của key được tham chiếu (có thể không tồn tại)
+type copyJob struct {
+	srcKey  string
+	destKey string
+	trash   bool
+}
+
+// stateKey gồm cả key đích để đổi rewrite giữa hai lần chạy không bị bỏ sót
+func (j copyJob) stateKey() string {
+	return j.srcKey + "\t" + j.destKey
+}
+
+func (s *migrationService) Run(ctx context.Context, opts model.Options) (*model.Report, error) {
+	report := &model.Report{
+		DryRun:    opts.DryRun,
+		Failures:  []model.Failure{},
+		StartedAt: time.Now(),
+	}
+
+	referenced, err := s.keys.ReferencedKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.ReferencedKeys = len(referenced)
+
+	state, err := openState(opts.StatePath)
+	if err != nil {
+		return nil, err
+	}
+	defer state.Close()
+
+	// thứ tự cố định để log tiến độ dễ theo dõi khi chạy lại
+	sorted := make([]string, 0, len(referenced))
+	for key := range referenced {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	jobs := make(chan copyJob)
+	go func() {
+		defer close(jobs)
+		for _, key := range sorted {
+			destKey, _ := model.Apply(opts.KeyRewrites, key)
+			// document đã xoá mềm giữ key gốc, object nằm dưới trash/ và phải đi theo key mới
+			for _, job := range []copyJob{
+				{srcKey: key, destKey: destKey},
+				{srcKey: trashModel.Key(key), destKey: trashModel.Key(destKey), trash: true},
+			} {
+				select {
+				case jobs <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	var (
+		mu       sync.Mutex
+		done     int
+		migrated = map[string]string{} // key đã có ở đích: key cũ -> key mới
+	)
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				copied, size, err := s.copy(ctx, job, state, opts.DryRun)
+
+				mu.Lock()
+				switch {
+				case errors.Is(err, uploader.ErrObjectNotFound):
+					if !job.trash {
+						report.MissingObjects++
+						report.Failures = append(report.Failures, model.Failure{Key: job.srcKey, Error: "object not found in source"})
+					}
+				case err != nil:
+					report.Failures = append(report.Failures, model.Failure{Key: job.srcKey, Error: err.Error()})
+				case copied:
+					report.CopiedObjects++
+					report.CopiedBytes += size
+				default:
+					report.SkippedObjects++
+				}
+				if err == nil && !job.trash {
+					migrated[job.srcKey] = job.destKey
+				}
+				done++
+				if done%progressEvery == 0 {
+					log.Printf("migrate-storage: %d/%d objects processed, %d failures", done, 2*len(sorted), len(report.Failures))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		// bị ngắt giữa chừng: trả về phần đã làm, chạy lại với cùng state file để tiếp tục
+		report.FinishedAt = time.Now()
+		return report, err
+	}
+
+	// chỉ đổi tham chiếu của key đã chắc chắn có ở đích, key lỗi giữ nguyên để chạy lại
+	if len(opts.KeyRewrites) > 0 || len(opts.URLRewrites) > 0 {
+		rewritten := map[string]string{}
+		for oldKey, newKey := range migrated {
+			if oldKey != newKey {
+				rewritten[oldKey] = newKey
+			}
+		}
+		report.RewrittenKeys = len(rewritten)
+		updated, err := s.refs.UpdateReferences(ctx, rewritten, opts.URLRewrites, opts.DryRun)
+		report.UpdatedDocuments = updated
+		if err != nil {
+			report.Failures = append(report.Failures, model.Failure{Error: fmt.Sprintf("update references: %v", err)})
+		}
+	}
+
+	sort.Slice(report.Failures, func(i, j int) bool { return report.Failures[i].Key < report.Failures[j].Key })
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// copy trả về copied = false khi object đã có ở đích
+func (s *migrationService) copy(ctx context.Context, job copyJob, state *stateFile, dryRun bool) (bool, int64, error) {
+	if state.Done(job.stateKey()) {
+		return false, 0, nil
+	}
+
+	src, err := s.source.HeadObject(ctx, job.srcKey)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// lần chạy trước bị ngắt sau khi chép xong nhưng trước khi kịp ghi state
+	if dest, err := s.destination.HeadObject(ctx, job.destKey); err == nil && dest.Size == src.Size {
+		return false, 0, state.MarkDone(job.stateKey())
+	} else if err != nil && !errors.Is(err, uploader.ErrObjectNotFound) {
+		return false, 0, fmt.Errorf("head destination %s: %w", job.destKey, err)
+	}
+
+	if dryRun {
+		return true, src.Size, nil
+	}
+
+	obj, err := s.source.GetObject(ctx, job.srcKey, uploader.GetObjectOptions{})
+	if err != nil {
+		return false, 0, err
+	}
+	defer obj.Body.Close()
+
+	contentType := src.ContentType
+	if contentType == "" {
+		contentType = obj.ContentType
+	}
+	mode := uploader.UploadPrivate
+	if uploader.IsPublicKey(strings.TrimPrefix(job.destKey, trashModel.Prefix)) {
+		mode = uploader.UploadPublic
+	}
+	if _, err := s.destination.SaveFileUploadedReader(ctx, obj.Body, job.destKey, contentType, mode, nil); err != nil {
+		return false, 0, fmt.Errorf("write %s: %w", job.destKey, err)
+	}
+
+	dest, err := s.destination.HeadObject(ctx, job.destKey)
+	if err != nil {
+		return false, 0, fmt.Errorf("verify %s: %w", job.destKey, err)
+	}
+	if dest.Size != src.Size {
+		return false, 0, fmt.Errorf("size mismatch for %s: source %d bytes, destination %d bytes", job.destKey, src.Size, dest.Size)
+	}
+	return true, src.Size, state.MarkDone(job.stateKey())
+}
+
+// stateFile ghi mỗi key đã chép xong thành một dòng để chạy lại có thể tiếp tục, path rỗng = không lưu
+type stateFile struct {
+	mu   sync.Mutex
+	done map[string]bool
+	f    *os.File
+}
+
+func openState(path string) (*stateFile, error) {
+	st := &stateFile{done: map[string]bool{}}
+	if path == "" {
+		return st, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if key := scanner.Text(); key != "" {
+			st.done[key] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	st.f = f
+	return st, nil
+}
+
+func (st *stateFile) Done(key string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.done[key]
+}
+
+func (st *stateFile) MarkDone(key string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.done[key] = true
+	if st.f == nil {
+		return nil
+	}
+	_, err := st.f.WriteString(key + "\n")
+	return err
+}
+
+func (st *stateFile) Close() error {
+	if st.f == nil {
+		return nil
+	}
+	return st.f.Close()
+}
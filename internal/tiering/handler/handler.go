@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"media-service/helper"
+	"media-service/internal/tiering/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TieringHandler struct {
+	svc service.TieringService
+}
+
+func NewTieringHandler(svc service.TieringService) *TieringHandler {
+	return &TieringHandler{svc: svc}
+}
+
+// Run áp dụng các rule tiering ngay lập tức, mặc định dry_run=true
+func (h *TieringHandler) Run(c *fiber.Ctx) error {
+	dryRun := c.Query("dry_run") != "false"
+	report, err := h.svc.Run(c.UserContext(), dryRun)
+	if err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "storage tiering finished", report)
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	MethodCopy = "copy" // copy-in-place với storage class mới, có hiệu lực ngay
+	MethodTag  = "tag"  // chỉ gắn tag, lifecycle rule của bucket chuyển class sau
+)
+
+// TagKey là tag lifecycle rule của bucket lọc theo khi method = tag
+const TagKey = "storage-class"
+
+// Candidate là một topic resource có ảnh cần chuyển class
+type Candidate struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	ImageKey     string             `bson:"image_key"`
+	Bucket       string             `bson:"bucket"`
+	StorageClass string             `bson:"storage_class"`
+	CreatedAt    time.Time          `bson:"created_at"`
+}
+
+type RuleReport struct {
+	Prefix       string `json:"prefix"`
+	MinAgeDays   int    `json:"min_age_days"`
+	StorageClass string `json:"storage_class"`
+	Matched      int    `json:"matched"`
+	Transitioned int    `json:"transitioned"`
+}
+
+// Report là kết quả của một lần chạy tiering
+type Report struct {
+	DryRun     bool         `json:"dry_run"`
+	Method     string       `json:"method"`
+	Rules      []RuleReport `json:"rules"`
+	Errors     []string     `json:"errors,omitempty"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at"`
+}
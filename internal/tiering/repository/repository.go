@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"media-service/internal/tiering/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TierRepository interface {
+	// EachCandidate gọi fn cho từng topic resource chưa xoá có image_key dưới prefix,
+	// tạo trước createdBefore và storage_class không nằm trong exclude
+	EachCandidate(ctx context.Context, prefix string, createdBefore time.Time, exclude []string, fn func(model.Candidate) error) error
+	SetStorageClass(ctx context.Context, id primitive.ObjectID, class string, at time.Time) error
+}
+
+type tierRepository struct {
+	col *mongo.Collection
+}
+
+func NewTierRepository(topicResourceCol *mongo.Collection) TierRepository {
+	return &tierRepository{col: topicResourceCol}
+}
+
+func (r *tierRepository) EachCandidate(ctx context.Context, prefix string, createdBefore time.Time, exclude []string, fn func(model.Candidate) error) error {
+	filter := bson.M{
+		"image_key":     bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)},
+		"created_at":    bson.M{"$lt": createdBefore},
+		"deleted_at":    bson.M{"$exists": false},
+		"storage_class": bson.M{"$nin": exclude}, // document chưa có field cũng khớp
+	}
+	opts := options.Find().
+		SetProjection(bson.M{"image_key": 1, "bucket": 1, "storage_class": 1, "created_at": 1}).
+		SetBatchSize(500)
+	cursor, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var c model.Candidate
+		if err := cursor.Decode(&c); err != nil {
+			return err
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (r *tierRepository) SetStorageClass(ctx context.Context, id primitive.ObjectID, class string, at time.Time) error {
+	_, err := r.col.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"storage_class": class, "tiered_at": at}})
+	return err
+}
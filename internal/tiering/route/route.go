@@ -0,0 +1,16 @@
+package route
+
+import (
+	"media-service/internal/gateway"
+	"media-service/internal/middleware"
+	"media-service/internal/tiering/handler"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterTieringRoutes(app *fiber.App, h *handler.TieringHandler, userGw gateway.UserGateway) {
+	admin := app.Group("/api/v2/admin/storage")
+	admin.Use(middleware.Secured(userGw))
+
+	admin.Post("/tiering", middleware.RequireAdmin(), h.Run)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"media-service/internal/s3"
+	"media-service/internal/tiering/model"
+	"media-service/internal/tiering/repository"
+	"media-service/logger"
+	"media-service/pkg/config"
+	"media-service/pkg/uploader"
+)
+
+const (
+	defaultIntervalHours = 24
+	defaultRestoreDays   = 7
+	defaultRestoreTier   = "Standard"
+)
+
+type rule struct {
+	prefix string
+	minAge int // ngày
+	class  uploader.StorageClass
+}
+
+type TieringService interface {
+	// Run áp dụng các rule một lần, dryRun = true chỉ đếm
+	Run(ctx context.Context, dryRun bool) (*model.Report, error)
+	// Start chạy tiering định kỳ cho tới khi ctx bị huỷ
+	Start(ctx context.Context)
+	// EnsureReadable cho biết ảnh đã đọc được chưa; object đang lưu trữ (GLACIER, DEEP_ARCHIVE)
+	// được yêu cầu khôi phục và trả về false cho tới khi bản khôi phục sẵn sàng
+	EnsureReadable(ctx context.Context, bucket, key, storageClass string) (bool, error)
+}
+
+type tieringService struct {
+	repo        repository.TierRepository
+	s3Service   s3.Service
+	rules       []rule
+	method      string
+	interval    time.Duration
+	restoreDays int32
+	restoreTier string
+
+	running sync.Mutex // không cho hai lần chạy chồng lên nhau
+}
+
+func NewTieringService(repo repository.TierRepository, s3Service s3.Service) TieringService {
+	cfg := config.AppConfig.Tiering
+
+	rules := make([]rule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		class, err := uploader.ParseStorageClass(r.StorageClass)
+		if err != nil || r.Prefix == "" || r.MinAgeDays <= 0 {
+			panic(fmt.Sprintf("invalid tiering rule %+v", r))
+		}
+		rules = append(rules, rule{prefix: r.Prefix, minAge: r.MinAgeDays, class: class})
+	}
+	// rule cũ nhất (class lạnh nhất) chạy trước để object không bị chuyển hai lần trong một lượt
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].minAge > rules[j].minAge })
+
+	method := strings.ToLower(cfg.Method)
+	if method != model.MethodTag {
+		method = model.MethodCopy
+	}
+	intervalHours := cfg.IntervalHours
+	if intervalHours <= 0 {
+		intervalHours = defaultIntervalHours
+	}
+	restoreDays := cfg.RestoreDays
+	if restoreDays <= 0 {
+		restoreDays = defaultRestoreDays
+	}
+	restoreTier := cfg.RestoreTier
+	if restoreTier == "" {
+		restoreTier = defaultRestoreTier
+	}
+
+	return &tieringService{
+		repo:        repo,
+		s3Service:   s3Service,
+		rules:       rules,
+		method:      method,
+		interval:    time.Duration(intervalHours) * time.Hour,
+		restoreDays: int32(restoreDays),
+		restoreTier: restoreTier,
+	}
+}
+
+func (s *tieringService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := s.Run(ctx, false)
+				if err != nil {
+					logger.WriteLogEx("error", "storage tiering failed", err)
+					continue
+				}
+				logger.WriteLogData("info", report)
+			}
+		}
+	}()
+}
+
+func (s *tieringService) Run(ctx context.Context, dryRun bool) (*model.Report, error) {
+	if !s.running.TryLock() {
+		return nil, fmt.Errorf("storage tiering is already running")
+	}
+	defer s.running.Unlock()
+
+	report := &model.Report{
+		DryRun:    dryRun,
+		Method:    s.method,
+		Rules:     make([]model.RuleReport, 0, len(s.rules)),
+		StartedAt: time.Now(),
+	}
+
+	for _, r := range s.rules {
+		rr := model.RuleReport{Prefix: r.prefix, MinAgeDays: r.minAge, StorageClass: string(r.class)}
+		// bỏ qua object đã ở class này hoặc lạnh hơn
+		exclude := make([]string, 0)
+		for _, c := range r.class.AtLeastAsCold() {
+			exclude = append(exclude, string(c))
+		}
+		cutoff := time.Now().AddDate(0, 0, -r.minAge)
+
+		err := s.repo.EachCandidate(ctx, r.prefix, cutoff, exclude, func(c model.Candidate) error {
+			rr.Matched++
+			if dryRun {
+				return nil
+			}
+			if err := s.transition(ctx, s.s3Service.For(c.Bucket), c.ImageKey, r.class); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", c.ImageKey, err))
+				return nil
+			}
+			if err := s.repo.SetStorageClass(ctx, c.ID, string(r.class), time.Now()); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: update document: %v", c.ImageKey, err))
+				return nil
+			}
+			rr.Transitioned++
+			return nil
+		})
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("rule %s (%d days): %v", r.prefix, r.minAge, err))
+		}
+		report.Rules = append(report.Rules, rr)
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+func (s *tieringService) transition(ctx context.Context, storage s3.Service, key string, class uploader.StorageClass) error {
+	if s.method == model.MethodTag {
+		return storage.Tag(ctx, key, map[string]string{model.TagKey: string(class)})
+	}
+	return storage.SetStorageClass(ctx, key, class)
+}
+
+func (s *tieringService) EnsureReadable(ctx context.Context, bucket, key, storageClass string) (bool, error) {
+	if key == "" || !uploader.StorageClass(storageClass).IsArchived() {
+		return true, nil
+	}
+
+	// document chỉ ghi class đích, class thực tế (lifecycle chưa chạy, bản khôi phục) lấy từ bucket
+	storage := s.s3Service.For(bucket)
+	info, err := storage.Head(ctx, key)
+	if err != nil {
+		if errors.Is(err, uploader.ErrObjectNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	if !info.StorageClass.IsArchived() {
+		return true, nil
+	}
+	switch info.Restore {
+	case uploader.RestoreCompleted:
+		return true, nil
+	case uploader.RestoreInProgress:
+		return false, nil
+	}
+	if err := storage.Restore(ctx, key, s.restoreDays, s.restoreTier); err != nil {
+		return false, err
+	}
+	return false, nil
+}
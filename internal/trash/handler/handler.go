@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"media-service/helper"
+	"media-service/internal/trash/model"
+	"media-service/internal/trash/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+type TrashHandler struct {
+	svc service.TrashService
+}
+
+func NewTrashHandler(svc service.TrashService) *TrashHandler {
+	return &TrashHandler{svc: svc}
+}
+
+// List liệt kê các item trong thùng rác theo kind, mới xoá nhất trước
+func (h *TrashHandler) List(c *fiber.Ctx) error {
+	kind := model.Kind(c.Query("kind"))
+	if !kind.IsValid() {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("kind must be one of %v", model.Kinds), helper.ErrInvalidRequest)
+	}
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit := c.QueryInt("limit", defaultPageSize)
+	if limit < 1 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+
+	items, total, err := h.svc.List(c.UserContext(), kind, page, limit)
+	if err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "success", fiber.Map{
+		"items": items,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+func (h *TrashHandler) Restore(c *fiber.Ctx) error {
+	kind := model.Kind(c.Params("kind"))
+	if !kind.IsValid() {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("kind must be one of %v", model.Kinds), helper.ErrInvalidRequest)
+	}
+
+	item, err := h.svc.Restore(c.UserContext(), kind, c.Params("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotInTrash) {
+			return helper.SendError(c, http.StatusNotFound, err, helper.ErrNotFound)
+		}
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "restored", item)
+}
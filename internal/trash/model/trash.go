@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+)
+
+// Prefix là nơi chứa object của các document đã bị xoá mềm, giữ nguyên key gốc phía sau
+const Prefix = "trash/"
+
+type Kind string
+
+const (
+	KindTopicResource Kind = "topic_resource"
+	KindPDFResource   Kind = "pdf_resource"
+	KindVideoUploader Kind = "video_uploader"
+	KindMediaAsset    Kind = "media_asset"
+)
+
+var Kinds = []Kind{KindTopicResource, KindPDFResource, KindVideoUploader, KindMediaAsset}
+
+func (k Kind) IsValid() bool {
+	for _, kind := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Key trả về vị trí của object trong thùng rác
+func Key(key string) string {
+	return Prefix + key
+}
+
+// Item là một document đã bị xoá mềm cùng các object key gốc của nó
+type Item struct {
+	Kind      Kind      `json:"kind"`
+	ID        string    `json:"id"`
+	Title     string    `json:"title,omitempty"`
+	Keys      []string  `json:"keys"`
+	Bucket    string    `json:"bucket,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by,omitempty"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
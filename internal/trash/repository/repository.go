@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mediaModel "media-service/internal/media/model"
+	mediaassetModel "media-service/internal/mediaasset/model"
+	pdfModel "media-service/internal/pdf/model"
+	"media-service/internal/trash/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TrashRepository thao tác trên các document đã bị xoá mềm của mọi collection có thùng rác.
+// Việc đánh dấu xoá do repository của từng domain đảm nhiệm.
+type TrashRepository interface {
+	List(ctx context.Context, kind model.Kind, offset, limit int64) ([]model.Item, int64, error)
+	Get(ctx context.Context, kind model.Kind, id primitive.ObjectID) (*model.Item, error)
+	// Restore trả về false nếu document không còn trong thùng rác
+	Restore(ctx context.Context, kind model.Kind, id primitive.ObjectID) (bool, error)
+	FindExpired(ctx context.Context, kind model.Kind, before time.Time, limit int64) ([]model.Item, error)
+	// Purge xoá vĩnh viễn document nếu nó vẫn nằm trong thùng rác từ trước thời điểm before
+	Purge(ctx context.Context, kind model.Kind, id primitive.ObjectID, before time.Time) (bool, error)
+}
+
+type trashRepository struct {
+	collections map[model.Kind]*mongo.Collection
+}
+
+func NewTrashRepository(topicResourceCol, pdfCol, videoUploaderCol, mediaAssetCol *mongo.Collection) TrashRepository {
+	return &trashRepository{
+		collections: map[model.Kind]*mongo.Collection{
+			model.KindTopicResource: topicResourceCol,
+			model.KindPDFResource:   pdfCol,
+			model.KindVideoUploader: videoUploaderCol,
+			model.KindMediaAsset:    mediaAssetCol,
+		},
+	}
+}
+
+func (r *trashRepository) collection(kind model.Kind) (*mongo.Collection, error) {
+	col, ok := r.collections[kind]
+	if !ok || col == nil {
+		return nil, fmt.Errorf("unsupported trash kind: %s", kind)
+	}
+	return col, nil
+}
+
+func (r *trashRepository) List(ctx context.Context, kind model.Kind, offset, limit int64) ([]model.Item, int64, error) {
+	col, err := r.collection(kind)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter := bson.M{"deleted_at": bson.M{"$exists": true}}
+	total, err := col.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "deleted_at", Value: -1}}).
+		SetSkip(offset).
+		SetLimit(limit)
+	items, err := r.find(ctx, kind, col, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *trashRepository) Get(ctx context.Context, kind model.Kind, id primitive.ObjectID) (*model.Item, error) {
+	col, err := r.collection(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := col.FindOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": true}}).Raw()
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	item, err := decodeItem(kind, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *trashRepository) Restore(ctx context.Context, kind model.Kind, id primitive.ObjectID) (bool, error) {
+	col, err := r.collection(kind)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := col.UpdateOne(ctx,
+		bson.M{"_id": id, "deleted_at": bson.M{"$exists": true}},
+		bson.M{
+			"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+func (r *trashRepository) FindExpired(ctx context.Context, kind model.Kind, before time.Time, limit int64) ([]model.Item, error) {
+	col, err := r.collection(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "deleted_at", Value: 1}}).
+		SetLimit(limit)
+	return r.find(ctx, kind, col, bson.M{"deleted_at": bson.M{"$lte": before}}, opts)
+}
+
+func (r *trashRepository) Purge(ctx context.Context, kind model.Kind, id primitive.ObjectID, before time.Time) (bool, error) {
+	col, err := r.collection(kind)
+	if err != nil {
+		return false, err
+	}
+
+	// điều kiện deleted_at để không xoá nhầm document vừa được khôi phục
+	res, err := col.DeleteOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$lte": before}})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+func (r *trashRepository) find(ctx context.Context, kind model.Kind, col *mongo.Collection, filter bson.M, opts *options.FindOptions) ([]model.Item, error) {
+	cursor, err := col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := make([]model.Item, 0)
+	for cursor.Next(ctx) {
+		item, err := decodeItem(kind, cursor.Current)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, cursor.Err()
+}
+
+// decodeItem đọc document theo model của từng domain và lấy ra các object key của nó
+func decodeItem(kind model.Kind, raw bson.Raw) (model.Item, error) {
+	var (
+		id        primitive.ObjectID
+		title     string
+		keys      []string
+		deletedAt *time.Time
+		deletedBy string
+	)
+
+	switch kind {
+	case model.KindTopicResource:
+		var doc mediaModel.TopicResource
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return model.Item{}, err
+		}
+		id, title, deletedAt, deletedBy = doc.ID, doc.FileName, doc.DeletedAt, doc.DeletedBy
+		keys = append(keys, doc.ImageKey)
+
+	case model.KindPDFResource:
+		var doc pdfModel.UserResource
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return model.Item{}, err
+		}
+		id, deletedAt, deletedBy = doc.ID, doc.DeletedAt, doc.DeletedBy
+		if doc.FileName != nil {
+			title = *doc.FileName
+		}
+		if doc.PDFKey != nil {
+			keys = append(keys, *doc.PDFKey)
+		}
+		if doc.SignatureKey != nil {
+			keys = append(keys, *doc.SignatureKey)
+		}
+
+	case model.KindVideoUploader:
+		var doc mediaModel.VideoUploader
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return model.Item{}, err
+		}
+		id, title, deletedAt, deletedBy = doc.ID, doc.Title, doc.DeletedAt, doc.DeletedBy
+		for _, cfg := range doc.LanguageConfig {
+			keys = append(keys, cfg.VideoKey, cfg.ImagePreviewKey)
+		}
+
+	case model.KindMediaAsset:
+		var doc mediaassetModel.MediaAsset
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return model.Item{}, err
+		}
+		id, title, deletedAt, deletedBy = doc.ID, doc.FileName, doc.DeletedAt, doc.DeletedBy
+		keys = append(keys, doc.Key)
+
+	default:
+		return model.Item{}, fmt.Errorf("unsupported trash kind: %s", kind)
+	}
+
+	item := model.Item{
+		Kind:      kind,
+		ID:        id.Hex(),
+		Title:     title,
+		Keys:      make([]string, 0, len(keys)),
+		DeletedBy: deletedBy,
+	}
+	// mọi model đều lưu bucket dưới cùng tên field
+	item.Bucket, _ = raw.Lookup("bucket").StringValueOK()
+	if deletedAt != nil {
+		item.DeletedAt = *deletedAt
+	}
+	for _, key := range keys {
+		if key != "" {
+			item.Keys = append(item.Keys, key)
+		}
+	}
+	return item, nil
+}
@@ -0,0 +1,17 @@
+package route
+
+import (
+	"media-service/internal/gateway"
+	"media-service/internal/middleware"
+	"media-service/internal/trash/handler"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterTrashRoutes(app *fiber.App, h *handler.TrashHandler, userGw gateway.UserGateway) {
+	admin := app.Group("/api/v2/admin/trash")
+	admin.Use(middleware.Secured(userGw))
+
+	admin.Get("/", middleware.RequireAdmin(), h.List)
+	admin.Post("/:kind/:id/restore", middleware.RequireAdmin(), h.Restore)
+}
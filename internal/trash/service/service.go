@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	outboxService "media-service/internal/outbox/service"
+	"media-service/internal/s3"
+	"media-service/internal/trash/model"
+	"media-service/internal/trash/repository"
+	"media-service/logger"
+	"media-service/pkg/config"
+	"media-service/pkg/uploader"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultRetentionDays      = 30
+	defaultPurgeIntervalHours = 24
+	purgeBatchSize            = 100
+)
+
+var ErrNotInTrash = errors.New("item not found in trash")
+
+// TrashService quản lý object của các document đã bị xoá mềm: chuyển object vào Prefix khi xoá,
+// trả lại chỗ cũ khi khôi phục và xoá vĩnh viễn (qua deletion outbox) khi hết thời gian lưu giữ.
+type TrashService interface {
+	// MoveObjects được gọi sau khi document đã được đánh dấu xoá mềm, bucket là bucket lưu trên document.
+	// Lỗi chỉ được ghi log: object còn ở key gốc vẫn được restore / purge xử lý đúng.
+	MoveObjects(ctx context.Context, bucket string, keys ...string)
+	List(ctx context.Context, kind model.Kind, page, limit int) ([]model.Item, int64, error)
+	Restore(ctx context.Context, kind model.Kind, id string) (*model.Item, error)
+	// Start chạy job purge định kỳ cho tới khi ctx bị huỷ
+	Start(ctx context.Context)
+}
+
+type trashService struct {
+	repo           repository.TrashRepository
+	s3Service      s3.Service
+	deletionOutbox outboxService.DeletionService
+	retention      time.Duration
+	interval       time.Duration
+
+	purging sync.Mutex
+}
+
+func NewTrashService(repo repository.TrashRepository, s3Service s3.Service, deletionOutbox outboxService.DeletionService) TrashService {
+	cfg := config.AppConfig.Trash
+
+	retentionDays := cfg.RetentionDays
+	if retentionDays <= 0 {
+		retentionDays = defaultRetentionDays
+	}
+	intervalHours := cfg.PurgeIntervalHours
+	if intervalHours <= 0 {
+		intervalHours = defaultPurgeIntervalHours
+	}
+
+	return &trashService{
+		repo:           repo,
+		s3Service:      s3Service,
+		deletionOutbox: deletionOutbox,
+		retention:      time.Duration(retentionDays) * 24 * time.Hour,
+		interval:       time.Duration(intervalHours) * time.Hour,
+	}
+}
+
+func (s *trashService) MoveObjects(ctx context.Context, bucket string, keys ...string) {
+	storage := s.s3Service.For(bucket)
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := move(ctx, storage, key, model.Key(key)); err != nil {
+			logger.WriteLogEx("error", "failed to move object to trash", map[string]any{
+				"key":   key,
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+func (s *trashService) List(ctx context.Context, kind model.Kind, page, limit int) ([]model.Item, int64, error) {
+	if !kind.IsValid() {
+		return nil, 0, fmt.Errorf("invalid trash kind: %s", kind)
+	}
+
+	items, total, err := s.repo.List(ctx, kind, int64((page-1)*limit), int64(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range items {
+		items[i].PurgeAt = items[i].DeletedAt.Add(s.retention)
+	}
+	return items, total, nil
+}
+
+func (s *trashService) Restore(ctx context.Context, kind model.Kind, id string) (*model.Item, error) {
+	if !kind.IsValid() {
+		return nil, fmt.Errorf("invalid trash kind: %s", kind)
+	}
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.repo.Get(ctx, kind, oid)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrNotInTrash
+	}
+
+	// trả object về chỗ cũ trước, document chỉ hiện lại khi file đã sẵn sàng
+	storage := s.s3Service.For(item.Bucket)
+	for _, key := range item.Keys {
+		err := move(ctx, storage, model.Key(key), key)
+		if errors.Is(err, uploader.ErrObjectNotFound) {
+			// object chưa từng được chuyển vào thùng rác
+			if _, headErr := storage.Head(ctx, key); headErr == nil {
+				continue
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore object %s: %w", key, err)
+		}
+	}
+
+	restored, err := s.repo.Restore(ctx, kind, oid)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, ErrNotInTrash
+	}
+	return item, nil
+}
+
+func (s *trashService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.purge(ctx)
+			}
+		}
+	}()
+}
+
+// purge xoá vĩnh viễn các document đã nằm trong thùng rác quá thời gian lưu giữ
+func (s *trashService) purge(ctx context.Context) {
+	if !s.purging.TryLock() {
+		return
+	}
+	defer s.purging.Unlock()
+
+	before := time.Now().Add(-s.retention)
+	for _, kind := range model.Kinds {
+		for ctx.Err() == nil {
+			items, err := s.repo.FindExpired(ctx, kind, before, purgeBatchSize)
+			if err != nil {
+				logger.WriteLogEx("error", "failed to list expired trash items", err)
+				break
+			}
+
+			purged := 0
+			for _, item := range items {
+				if s.purgeItem(ctx, item, before) {
+					purged++
+				}
+			}
+			// batch lỗi toàn bộ thì dừng, lần chạy sau thử lại
+			if len(items) < purgeBatchSize || purged == 0 {
+				break
+			}
+		}
+	}
+}
+
+func (s *trashService) purgeItem(ctx context.Context, item model.Item, before time.Time) bool {
+	oid, err := primitive.ObjectIDFromHex(item.ID)
+	if err != nil {
+		return false
+	}
+
+	purged, err := s.repo.Purge(ctx, item.Kind, oid, before)
+	if err != nil {
+		logger.WriteLogEx("error", "failed to purge trash item", err)
+		return false
+	}
+	if !purged {
+		return false
+	}
+
+	// xoá cả key gốc phòng trường hợp object chưa được chuyển vào thùng rác
+	keys := make([]string, 0, len(item.Keys)*2)
+	for _, key := range item.Keys {
+		keys = append(keys, model.Key(key), key)
+	}
+	_ = s.deletionOutbox.Enqueue(ctx, "purge_"+string(item.Kind), item.Bucket, keys...)
+	return true
+}
+
+// move = copy + delete, lỗi khi xoá object nguồn chỉ được ghi log vì bản sao đã nằm đúng chỗ
+func move(ctx context.Context, storage s3.Service, srcKey, destKey string) error {
+	if err := storage.Copy(ctx, srcKey, destKey); err != nil {
+		return err
+	}
+	if err := storage.Delete(ctx, srcKey); err != nil {
+		logger.WriteLogEx("warn", "failed to delete source object after move", map[string]any{
+			"key":   srcKey,
+			"error": err.Error(),
+		})
+	}
+	return nil
+}
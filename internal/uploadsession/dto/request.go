@@ -0,0 +1,17 @@
+package dto
+
+type InitiateUploadRequest struct {
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Purpose     string `json:"purpose"` // topic_audio | topic_video | vocabulary_audio | vocabulary_video | video_uploader
+}
+
+type PresignUploadRequest struct {
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Purpose     string `json:"purpose"` // topic_audio | topic_video | vocabulary_audio | vocabulary_video | video_uploader | media_asset
+	Folder      string `json:"folder"`  // chỉ dùng cho media_asset, mặc định "uploads"
+	Mode        string `json:"mode"`    // chỉ dùng cho media_asset: public | private
+}
@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"media-service/internal/uploadsession/model"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type UploadSessionResponse struct {
+	ID            string               `json:"id"`
+	Key           string               `json:"key"`
+	Purpose       string               `json:"purpose"`
+	Method        string               `json:"method"`
+	FileName      string               `json:"file_name"`
+	ContentType   string               `json:"content_type"`
+	TotalSize     int64                `json:"total_size"`
+	PartSize      int64                `json:"part_size"`
+	TotalParts    int32                `json:"total_parts"`
+	UploadedParts []model.UploadedPart `json:"uploaded_parts"`
+	MissingParts  []int32              `json:"missing_parts"`
+	Status        string               `json:"status"`
+	MediaAssetID  string               `json:"media_asset_id,omitempty"`
+	ExpiresAt     time.Time            `json:"expires_at"`
+}
+
+// PresignUploadResponse describes the request the client must send straight to the bucket.
+type PresignUploadResponse struct {
+	ID        string            `json:"id"`
+	Key       string            `json:"key"`
+	Method    string            `json:"method"` // luôn là PUT
+	UploadURL string            `json:"upload_url"`
+	Headers   map[string]string `json:"headers"` // header bắt buộc phải gửi kèm, nếu thiếu chữ ký sẽ sai
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type UploadPartResponse struct {
+	PartNumber int32  `json:"part_number"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size"`
+}
+
+func ToUploadSessionResponse(s *model.UploadSession) *UploadSessionResponse {
+	if s == nil {
+		return nil
+	}
+
+	method := s.Method
+	if method == "" {
+		method = model.MethodMultipart
+	}
+
+	uploaded := make([]model.UploadedPart, 0, len(s.Parts))
+	missing := make([]int32, 0)
+	for n := int32(1); n <= s.TotalParts; n++ {
+		if part, ok := s.Parts[strconv.Itoa(int(n))]; ok {
+			uploaded = append(uploaded, part)
+		} else {
+			missing = append(missing, n)
+		}
+	}
+	sort.Slice(uploaded, func(i, j int) bool { return uploaded[i].PartNumber < uploaded[j].PartNumber })
+
+	return &UploadSessionResponse{
+		ID:            s.ID.Hex(),
+		Key:           s.Key,
+		Purpose:       string(s.Purpose),
+		Method:        string(method),
+		FileName:      s.FileName,
+		ContentType:   s.ContentType,
+		TotalSize:     s.TotalSize,
+		PartSize:      s.PartSize,
+		TotalParts:    s.TotalParts,
+		UploadedParts: uploaded,
+		MissingParts:  missing,
+		Status:        string(s.Status),
+		MediaAssetID:  s.MediaAssetID,
+		ExpiresAt:     s.ExpiresAt,
+	}
+}
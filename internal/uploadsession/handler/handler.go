@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"media-service/helper"
+	"media-service/internal/uploadsession/dto"
+	"media-service/internal/uploadsession/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type UploadSessionHandler struct {
+	svc service.UploadSessionService
+}
+
+func NewUploadSessionHandler(svc service.UploadSessionService) *UploadSessionHandler {
+	return &UploadSessionHandler{svc: svc}
+}
+
+func (h *UploadSessionHandler) Initiate(c *fiber.Ctx) error {
+	var req dto.InitiateUploadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+	}
+
+	session, err := h.svc.Initiate(c.UserContext(), req)
+	if err != nil {
+		status, code := helper.MapError(err, http.StatusInternalServerError, helper.ErrInvalidOperation)
+		return helper.SendError(c, status, err, code)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "initiate upload success", dto.ToUploadSessionResponse(session))
+}
+
+func (h *UploadSessionHandler) Presign(c *fiber.Ctx) error {
+	var req dto.PresignUploadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+	}
+
+	_, res, err := h.svc.Presign(c.UserContext(), req)
+	if err != nil {
+		status, code := helper.MapError(err, http.StatusInternalServerError, helper.ErrInvalidOperation)
+		return helper.SendError(c, status, err, code)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "presign upload success", res)
+}
+
+func (h *UploadSessionHandler) Confirm(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+	}
+
+	session, err := h.svc.Confirm(c.UserContext(), id)
+	if errors.Is(err, service.ErrAlreadyCompleted) {
+		return helper.SendError(c, http.StatusConflict, err, helper.ErrInvalidOperation)
+	}
+	if err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "confirm upload success", dto.ToUploadSessionResponse(session))
+}
+
+func (h *UploadSessionHandler) Get(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+	}
+
+	session, err := h.svc.Get(c.UserContext(), id)
+	if err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "ok", dto.ToUploadSessionResponse(session))
+}
+
+// UploadPart nhận raw body của một part (không phải multipart form)
+func (h *UploadSessionHandler) UploadPart(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+	}
+	partNumber, err := strconv.ParseInt(c.Params("part_number"), 10, 32)
+	if err != nil {
+		return helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+	}
+
+	part, err := h.svc.UploadPart(c.UserContext(), id, int32(partNumber), c.Body())
+	if err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "upload part success", dto.UploadPartResponse{
+		PartNumber: part.PartNumber,
+		ETag:       part.ETag,
+		Size:       part.Size,
+	})
+}
+
+func (h *UploadSessionHandler) Complete(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+	}
+
+	session, err := h.svc.Complete(c.UserContext(), id)
+	if errors.Is(err, service.ErrAlreadyCompleted) {
+		return helper.SendError(c, http.StatusConflict, err, helper.ErrInvalidOperation)
+	}
+	if err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "complete upload success", dto.ToUploadSessionResponse(session))
+}
+
+func (h *UploadSessionHandler) Abort(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return helper.SendError(c, http.StatusBadRequest, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+	}
+
+	if err := h.svc.Abort(c.UserContext(), id); err != nil {
+		return helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+	}
+	return helper.SendSuccess(c, http.StatusOK, "abort upload success", nil)
+}
@@ -0,0 +1,141 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	quotaModel "media-service/internal/quota/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UploadStatus string
+
+const (
+	UploadStatusPending   UploadStatus = "pending"
+	UploadStatusCompleted UploadStatus = "completed"
+	UploadStatusConsumed  UploadStatus = "consumed" // đã gắn vào topic / vocabulary / video uploader
+	UploadStatusAborted   UploadStatus = "aborted"
+	UploadStatusExpired   UploadStatus = "expired" // quá hạn khi còn pending, phần đã upload bị huỷ
+)
+
+// UploadPurpose decides which folder the object is stored under and which
+// flow is allowed to consume the finished upload.
+type UploadPurpose string
+
+const (
+	PurposeTopicAudio      UploadPurpose = "topic_audio"
+	PurposeTopicVideo      UploadPurpose = "topic_video"
+	PurposeVocabularyAudio UploadPurpose = "vocabulary_audio"
+	PurposeVocabularyVideo UploadPurpose = "vocabulary_video"
+	PurposeVideoUploader   UploadPurpose = "video_uploader"
+	PurposeMediaAsset      UploadPurpose = "media_asset" // chỉ dùng cho presigned upload, confirm sẽ tạo MediaAsset
+)
+
+// UploadMethod tells how the bytes reach the bucket.
+type UploadMethod string
+
+const (
+	MethodMultipart UploadMethod = "multipart" // client → service → S3 theo từng part
+	MethodPresigned UploadMethod = "presigned" // client PUT thẳng lên bucket
+)
+
+func (p UploadPurpose) Folder() string {
+	switch p {
+	case PurposeTopicAudio:
+		return "topic_media/audio"
+	case PurposeTopicVideo:
+		return "topic_media/video"
+	case PurposeVocabularyAudio:
+		return "vocabulary_media/audio"
+	case PurposeVocabularyVideo:
+		return "vocabulary_media/video"
+	case PurposeVideoUploader:
+		return "media_video_uploader"
+	case PurposeMediaAsset:
+		return "uploads"
+	default:
+		return ""
+	}
+}
+
+// Category là nhóm dữ liệu của document sẽ dùng upload này (chọn bucket theo storage.routing)
+func (p UploadPurpose) Category() quotaModel.Category {
+	switch p {
+	case PurposeTopicAudio, PurposeTopicVideo:
+		return quotaModel.CategoryTopic
+	case PurposeVocabularyAudio, PurposeVocabularyVideo:
+		return quotaModel.CategoryVocabulary
+	case PurposeVideoUploader:
+		return quotaModel.CategoryVideo
+	default:
+		return quotaModel.CategoryMediaAsset
+	}
+}
+
+func (p UploadPurpose) IsValid() bool {
+	return p.Folder() != ""
+}
+
+// IsPublic reports whether objects of this purpose are stored under uploader.PublicPrefix.
+func (p UploadPurpose) IsPublic() bool {
+	return p == PurposeVideoUploader
+}
+
+// AllowsContentType reports whether an object of the given content type may be used for this purpose.
+func (p UploadPurpose) AllowsContentType(contentType string) bool {
+	ct := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch p {
+	case PurposeTopicAudio, PurposeVocabularyAudio:
+		return strings.HasPrefix(ct, "audio/")
+	case PurposeTopicVideo, PurposeVocabularyVideo, PurposeVideoUploader:
+		return strings.HasPrefix(ct, "video/")
+	case PurposeMediaAsset:
+		return ct != ""
+	default:
+		return false
+	}
+}
+
+type UploadedPart struct {
+	PartNumber int32     `bson:"part_number" json:"part_number"`
+	ETag       string    `bson:"etag" json:"etag"`
+	Size       int64     `bson:"size" json:"size"`
+	UploadedAt time.Time `bson:"uploaded_at" json:"uploaded_at"`
+}
+
+type UploadSession struct {
+	ID          primitive.ObjectID      `bson:"_id" json:"id"`
+	UploadID    string                  `bson:"upload_id" json:"-"`
+	Key         string                  `bson:"key" json:"key"`
+	Bucket      string                  `bson:"bucket,omitempty" json:"-"`
+	Purpose     UploadPurpose           `bson:"purpose" json:"purpose"`
+	Method      UploadMethod            `bson:"method,omitempty" json:"method"` // rỗng = multipart (session cũ)
+	FileName    string                  `bson:"file_name" json:"file_name"`
+	ContentType string                  `bson:"content_type" json:"content_type"`
+	TotalSize   int64                   `bson:"total_size" json:"total_size"`
+	PartSize    int64                   `bson:"part_size" json:"part_size"`
+	TotalParts  int32                   `bson:"total_parts" json:"total_parts"`
+	Parts       map[string]UploadedPart `bson:"parts" json:"-"` // key = part number
+	Status      UploadStatus            `bson:"status" json:"status"`
+	CreatedBy   string                  `bson:"created_by" json:"created_by"`
+	ExpiresAt   time.Time               `bson:"expires_at" json:"expires_at"`
+	CreatedAt   time.Time               `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time               `bson:"updated_at" json:"updated_at"`
+
+	// chỉ dùng cho purpose media_asset
+	Mode         string `bson:"mode,omitempty" json:"mode,omitempty"`
+	MediaAssetID string `bson:"media_asset_id,omitempty" json:"media_asset_id,omitempty"`
+}
+
+// ExpectedPartSize returns the exact size the given part must have.
+func (s *UploadSession) ExpectedPartSize(partNumber int32) int64 {
+	if partNumber < s.TotalParts {
+		return s.PartSize
+	}
+	return s.TotalSize - int64(s.TotalParts-1)*s.PartSize
+}
+
+func (s *UploadSession) IsPresigned() bool {
+	return s.Method == MethodPresigned
+}
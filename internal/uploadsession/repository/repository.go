@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"media-service/internal/uploadsession/model"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type UploadSessionRepository interface {
+	Create(ctx context.Context, session *model.UploadSession) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*model.UploadSession, error)
+	SetPart(ctx context.Context, id primitive.ObjectID, part model.UploadedPart) error
+	UpdateStatus(ctx context.Context, id primitive.ObjectID, from, to model.UploadStatus) (bool, error)
+	Consume(ctx context.Context, id primitive.ObjectID, purpose model.UploadPurpose, createdBy string) (*model.UploadSession, error)
+	SetMediaAsset(ctx context.Context, id primitive.ObjectID, mediaAssetID string) error
+	// FindExpired trả về các session còn pending nhưng đã quá expires_at trước before
+	FindExpired(ctx context.Context, before time.Time, limit int64) ([]*model.UploadSession, error)
+}
+
+type uploadSessionRepository struct {
+	col *mongo.Collection
+}
+
+func NewUploadSessionRepository(col *mongo.Collection) UploadSessionRepository {
+	return &uploadSessionRepository{col: col}
+}
+
+func (r *uploadSessionRepository) Create(ctx context.Context, session *model.UploadSession) error {
+	_, err := r.col.InsertOne(ctx, session)
+	return err
+}
+
+func (r *uploadSessionRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*model.UploadSession, error) {
+	var out model.UploadSession
+	if err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&out); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetPart ghi đè part cùng số thứ tự, cho phép client upload lại part bị lỗi
+func (r *uploadSessionRepository) SetPart(ctx context.Context, id primitive.ObjectID, part model.UploadedPart) error {
+	_, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id, "status": model.UploadStatusPending},
+		bson.M{"$set": bson.M{
+			"parts." + strconv.Itoa(int(part.PartNumber)): part,
+			"updated_at": time.Now(),
+		}},
+	)
+	return err
+}
+
+// UpdateStatus chỉ chuyển trạng thái khi session đang ở trạng thái from
+func (r *uploadSessionRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, from, to model.UploadStatus) (bool, error) {
+	res, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id, "status": from},
+		bson.M{"$set": bson.M{"status": to, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// Consume đánh dấu một session đã hoàn tất là đã được sử dụng, để một object không bị gắn vào hai nơi
+func (r *uploadSessionRepository) Consume(ctx context.Context, id primitive.ObjectID, purpose model.UploadPurpose, createdBy string) (*model.UploadSession, error) {
+	filter := bson.M{
+		"_id":        id,
+		"status":     model.UploadStatusCompleted,
+		"purpose":    purpose,
+		"created_by": createdBy,
+	}
+	update := bson.M{"$set": bson.M{"status": model.UploadStatusConsumed, "updated_at": time.Now()}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var out model.UploadSession
+	if err := r.col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&out); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *uploadSessionRepository) FindExpired(ctx context.Context, before time.Time, limit int64) ([]*model.UploadSession, error) {
+	cursor, err := r.col.Find(ctx,
+		bson.M{"status": model.UploadStatusPending, "expires_at": bson.M{"$lt": before}},
+		options.Find().SetSort(bson.M{"expires_at": 1}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var out []*model.UploadSession
+	if err := cursor.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SetMediaAsset ghi lại MediaAsset đã được tạo từ session và đánh dấu session đã được sử dụng
+func (r *uploadSessionRepository) SetMediaAsset(ctx context.Context, id primitive.ObjectID, mediaAssetID string) error {
+	_, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id, "status": model.UploadStatusCompleted},
+		bson.M{"$set": bson.M{
+			"status":         model.UploadStatusConsumed,
+			"media_asset_id": mediaAssetID,
+			"updated_at":     time.Now(),
+		}},
+	)
+	return err
+}
@@ -0,0 +1,22 @@
+package route
+
+import (
+	"media-service/internal/gateway"
+	"media-service/internal/middleware"
+	"media-service/internal/uploadsession/handler"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterUploadSessionRoutes(app *fiber.App, h *handler.UploadSessionHandler, userGw gateway.UserGateway) {
+	uploads := app.Group("/api/v2/uploads")
+	uploads.Use(middleware.Secured(userGw))
+
+	uploads.Post("", h.Initiate)
+	uploads.Post("/presigned", h.Presign)
+	uploads.Get("/:id", h.Get)
+	uploads.Put("/:id/parts/:part_number", h.UploadPart)
+	uploads.Post("/:id/complete", h.Complete)
+	uploads.Post("/:id/confirm", h.Confirm)
+	uploads.Delete("/:id", h.Abort)
+}
@@ -0,0 +1,566 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"media-service/helper"
+	mediaassetService "media-service/internal/mediaasset/service"
+	quotaService "media-service/internal/quota/service"
+	"media-service/internal/s3"
+	"media-service/internal/uploadsession/dto"
+	"media-service/internal/uploadsession/model"
+	"media-service/internal/uploadsession/repository"
+	"media-service/logger"
+	"media-service/pkg/config"
+	"media-service/pkg/objectkey"
+	"media-service/pkg/uploader"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultPartSizeMB      = 8
+	minPartSizeMB          = 5 // giới hạn tối thiểu của S3 (trừ part cuối)
+	maxParts               = 10000
+	defaultSessionTTLHours = 7 * 24
+	defaultPresignTTLMin   = 15
+	defaultPresignMaxMB    = 5 * 1024 // S3 single PUT tối đa 5GB
+	defaultSweepMinutes    = 60
+	sweepBatchSize         = 100
+)
+
+// ErrAlreadyCompleted: session đã được complete / confirm bởi một request khác
+var ErrAlreadyCompleted = errors.New("upload session has already been completed")
+
+type UploadSessionService interface {
+	Initiate(ctx context.Context, req dto.InitiateUploadRequest) (*model.UploadSession, error)
+	Get(ctx context.Context, id string) (*model.UploadSession, error)
+	UploadPart(ctx context.Context, id string, partNumber int32, data []byte) (*model.UploadedPart, error)
+	Complete(ctx context.Context, id string) (*model.UploadSession, error)
+	Abort(ctx context.Context, id string) error
+	// Presign cấp presigned PUT url để client upload thẳng lên bucket, sau đó gọi Confirm
+	Presign(ctx context.Context, req dto.PresignUploadRequest) (*model.UploadSession, *dto.PresignUploadResponse, error)
+	// Confirm kiểm tra object đã có trên bucket (size / content type) rồi hoàn tất session
+	Confirm(ctx context.Context, id string) (*model.UploadSession, error)
+	// Consume trả về key của một upload đã hoàn tất và đánh dấu session đã được sử dụng.
+	// Object được chép sang bucket của document (bucket) nếu session nằm ở bucket khác.
+	Consume(ctx context.Context, id string, purpose model.UploadPurpose, bucket string) (string, error)
+	// Start định kỳ huỷ các session pending đã hết hạn cho tới khi ctx bị huỷ
+	Start(ctx context.Context)
+}
+
+type uploadSessionService struct {
+	repo           repository.UploadSessionRepository
+	s3Service      s3.Service
+	mediaService   mediaassetService.MediaService
+	quotaService   quotaService.QuotaService
+	partSize       int64
+	ttl            time.Duration
+	presignTTL     time.Duration
+	presignMaxSize int64
+	sweepInterval  time.Duration
+	sweeping       sync.Mutex
+}
+
+func NewUploadSessionService(repo repository.UploadSessionRepository, s3Service s3.Service, mediaService mediaassetService.MediaService, quotaSvc quotaService.QuotaService) UploadSessionService {
+	cfg := config.AppConfig.Upload
+
+	partSizeMB := cfg.MultipartPartSizeMB
+	if partSizeMB <= 0 {
+		partSizeMB = defaultPartSizeMB
+	}
+	if partSizeMB < minPartSizeMB {
+		partSizeMB = minPartSizeMB
+	}
+	ttlHours := cfg.SessionTTLHours
+	if ttlHours <= 0 {
+		ttlHours = defaultSessionTTLHours
+	}
+	presignTTLMin := cfg.PresignTTLMinutes
+	if presignTTLMin <= 0 {
+		presignTTLMin = defaultPresignTTLMin
+	}
+	presignMaxMB := cfg.PresignMaxSizeMB
+	if presignMaxMB <= 0 || presignMaxMB > defaultPresignMaxMB {
+		presignMaxMB = defaultPresignMaxMB
+	}
+	sweepMinutes := cfg.ExpirySweepMinutes
+	if sweepMinutes <= 0 {
+		sweepMinutes = defaultSweepMinutes
+	}
+
+	return &uploadSessionService{
+		repo:           repo,
+		s3Service:      s3Service,
+		mediaService:   mediaService,
+		quotaService:   quotaSvc,
+		partSize:       int64(partSizeMB) * 1024 * 1024,
+		ttl:            time.Duration(ttlHours) * time.Hour,
+		presignTTL:     time.Duration(presignTTLMin) * time.Minute,
+		presignMaxSize: int64(presignMaxMB) * 1024 * 1024,
+		sweepInterval:  time.Duration(sweepMinutes) * time.Minute,
+	}
+}
+
+func (s *uploadSessionService) Initiate(ctx context.Context, req dto.InitiateUploadRequest) (*model.UploadSession, error) {
+	purpose := model.UploadPurpose(strings.ToLower(strings.TrimSpace(req.Purpose)))
+	if !purpose.IsValid() || purpose == model.PurposeMediaAsset {
+		return nil, fmt.Errorf("invalid purpose: %s", req.Purpose)
+	}
+	if strings.TrimSpace(req.FileName) == "" {
+		return nil, fmt.Errorf("file name is required")
+	}
+	if req.Size <= 0 {
+		return nil, fmt.Errorf("size must be greater than 0")
+	}
+
+	partSize := s.partSize
+	totalParts := (req.Size + partSize - 1) / partSize
+	if totalParts > maxParts {
+		return nil, fmt.Errorf("file is too large")
+	}
+	// chặn sớm theo size khai báo, dung lượng thật được ghi nhận khi upload được sử dụng
+	if err := s.quotaService.Check(ctx, helper.GetCurrentOrganizationID(ctx), req.Size); err != nil {
+		return nil, err
+	}
+
+	id := primitive.NewObjectID()
+	key, err := sessionKey(ctx, id, purpose, purpose.Folder(), req.FileName)
+	if err != nil {
+		return nil, err
+	}
+	if purpose.IsPublic() {
+		key = uploader.PublicKey(key)
+	}
+	storage := s.s3Service.Route(string(purpose.Category()), helper.GetCurrentOrganizationID(ctx))
+	uploadID, err := storage.CreateMultipartUpload(ctx, key, req.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &model.UploadSession{
+		ID:          id,
+		UploadID:    uploadID,
+		Key:         key,
+		Bucket:      storage.Bucket(),
+		Purpose:     purpose,
+		Method:      model.MethodMultipart,
+		FileName:    req.FileName,
+		ContentType: req.ContentType,
+		TotalSize:   req.Size,
+		PartSize:    partSize,
+		TotalParts:  int32(totalParts),
+		Parts:       map[string]model.UploadedPart{},
+		Status:      model.UploadStatusPending,
+		CreatedBy:   helper.GetUserID(ctx),
+		ExpiresAt:   now.Add(s.ttl),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, session); err != nil {
+		_ = storage.AbortMultipartUpload(ctx, key, uploadID)
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *uploadSessionService) Get(ctx context.Context, id string) (*model.UploadSession, error) {
+	return s.getOwnedSession(ctx, id)
+}
+
+func (s *uploadSessionService) UploadPart(ctx context.Context, id string, partNumber int32, data []byte) (*model.UploadedPart, error) {
+	session, err := s.getOwnedSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensurePending(session); err != nil {
+		return nil, err
+	}
+	if session.IsPresigned() {
+		return nil, fmt.Errorf("presigned upload session does not accept parts")
+	}
+	if partNumber < 1 || partNumber > session.TotalParts {
+		return nil, fmt.Errorf("part number must be between 1 and %d", session.TotalParts)
+	}
+	if expected := session.ExpectedPartSize(partNumber); int64(len(data)) != expected {
+		return nil, fmt.Errorf("part %d must be %d bytes, got %d", partNumber, expected, len(data))
+	}
+
+	etag, err := s.s3Service.For(session.Bucket).UploadPart(ctx, session.Key, session.UploadID, partNumber, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	part := model.UploadedPart{
+		PartNumber: partNumber,
+		ETag:       etag,
+		Size:       int64(len(data)),
+		UploadedAt: time.Now(),
+	}
+	if err := s.repo.SetPart(ctx, session.ID, part); err != nil {
+		return nil, err
+	}
+	return &part, nil
+}
+
+func (s *uploadSessionService) Complete(ctx context.Context, id string) (*model.UploadSession, error) {
+	session, err := s.getOwnedSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensurePending(session); err != nil {
+		return nil, err
+	}
+	if session.IsPresigned() {
+		return nil, fmt.Errorf("presigned upload session must be confirmed instead")
+	}
+
+	parts := make([]uploader.CompletedPart, 0, session.TotalParts)
+	for n := int32(1); n <= session.TotalParts; n++ {
+		part, ok := session.Parts[strconv.Itoa(int(n))]
+		if !ok {
+			return nil, fmt.Errorf("part %d has not been uploaded", n)
+		}
+		parts = append(parts, uploader.CompletedPart{PartNumber: n, ETag: part.ETag})
+	}
+
+	if err := s.s3Service.For(session.Bucket).CompleteMultipartUpload(ctx, session.Key, session.UploadID, parts); err != nil {
+		return nil, err
+	}
+	ok, err := s.repo.UpdateStatus(ctx, session.ID, model.UploadStatusPending, model.UploadStatusCompleted)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrAlreadyCompleted
+	}
+	session.Status = model.UploadStatusCompleted
+	return session, nil
+}
+
+func (s *uploadSessionService) Abort(ctx context.Context, id string) error {
+	session, err := s.getOwnedSession(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session.Status != model.UploadStatusPending {
+		return fmt.Errorf("upload session is %s", session.Status)
+	}
+
+	storage := s.s3Service.For(session.Bucket)
+	if session.IsPresigned() {
+		// client có thể đã PUT object lên bucket
+		if err := storage.Delete(ctx, session.Key); err != nil {
+			return err
+		}
+	} else if err := storage.AbortMultipartUpload(ctx, session.Key, session.UploadID); err != nil {
+		return err
+	}
+	_, err = s.repo.UpdateStatus(ctx, session.ID, model.UploadStatusPending, model.UploadStatusAborted)
+	return err
+}
+
+func (s *uploadSessionService) Presign(ctx context.Context, req dto.PresignUploadRequest) (*model.UploadSession, *dto.PresignUploadResponse, error) {
+	purpose := model.UploadPurpose(strings.ToLower(strings.TrimSpace(req.Purpose)))
+	if !purpose.IsValid() {
+		return nil, nil, fmt.Errorf("invalid purpose: %s", req.Purpose)
+	}
+	if strings.TrimSpace(req.FileName) == "" {
+		return nil, nil, fmt.Errorf("file name is required")
+	}
+	if !purpose.AllowsContentType(req.ContentType) {
+		return nil, nil, fmt.Errorf("content type %q is not allowed for %s", req.ContentType, purpose)
+	}
+	if req.Size <= 0 {
+		return nil, nil, fmt.Errorf("size must be greater than 0")
+	}
+	if req.Size > s.presignMaxSize {
+		return nil, nil, fmt.Errorf("file is too large for a single upload, use a multipart upload session")
+	}
+	if err := s.quotaService.Check(ctx, helper.GetCurrentOrganizationID(ctx), req.Size); err != nil {
+		return nil, nil, err
+	}
+
+	folder := purpose.Folder()
+	mode := ""
+	if purpose == model.PurposeMediaAsset {
+		if f := strings.Trim(strings.ReplaceAll(req.Folder, "..", ""), "/ "); f != "" {
+			folder = f
+		}
+		mode = "private"
+		if _, err := uploader.UploadModeFromString(req.Mode); err == nil {
+			mode = strings.ToLower(req.Mode)
+		}
+	}
+
+	uploadMode := uploader.UploadPrivate
+	if m, err := uploader.UploadModeFromString(mode); err == nil {
+		uploadMode = m
+	}
+	id := primitive.NewObjectID()
+	key, err := sessionKey(ctx, id, purpose, folder, req.FileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if purpose.IsPublic() || uploadMode == uploader.UploadPublic {
+		key = uploader.PublicKey(key)
+	}
+	storage := s.s3Service.Route(string(purpose.Category()), helper.GetCurrentOrganizationID(ctx))
+	uploadURL, sseHeaders, err := storage.PresignPut(ctx, key, req.ContentType, uploadMode, s.presignTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	headers := map[string]string{"Content-Type": req.ContentType}
+	for k, v := range sseHeaders {
+		headers[k] = v
+	}
+
+	now := time.Now()
+	session := &model.UploadSession{
+		ID:          id,
+		Key:         key,
+		Bucket:      storage.Bucket(),
+		Purpose:     purpose,
+		Method:      model.MethodPresigned,
+		FileName:    req.FileName,
+		ContentType: req.ContentType,
+		TotalSize:   req.Size,
+		PartSize:    req.Size,
+		TotalParts:  1,
+		Parts:       map[string]model.UploadedPart{},
+		Status:      model.UploadStatusPending,
+		CreatedBy:   helper.GetUserID(ctx),
+		ExpiresAt:   now.Add(s.ttl),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Mode:        mode,
+	}
+	if err := s.repo.Create(ctx, session); err != nil {
+		return nil, nil, err
+	}
+
+	return session, &dto.PresignUploadResponse{
+		ID:        session.ID.Hex(),
+		Key:       key,
+		Method:    http.MethodPut,
+		UploadURL: uploadURL,
+		Headers:   headers,
+		ExpiresAt: now.Add(s.presignTTL),
+	}, nil
+}
+
+func (s *uploadSessionService) Confirm(ctx context.Context, id string) (*model.UploadSession, error) {
+	session, err := s.getOwnedSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensurePending(session); err != nil {
+		return nil, err
+	}
+	if !session.IsPresigned() {
+		return nil, fmt.Errorf("multipart upload session must be completed instead")
+	}
+
+	info, err := s.s3Service.For(session.Bucket).Head(ctx, session.Key)
+	if err != nil {
+		if errors.Is(err, uploader.ErrObjectNotFound) {
+			return nil, fmt.Errorf("object has not been uploaded yet")
+		}
+		return nil, err
+	}
+	// sai size / content type thì giữ session pending để client PUT lại
+	if info.Size != session.TotalSize {
+		return nil, fmt.Errorf("uploaded object is %d bytes, expected %d", info.Size, session.TotalSize)
+	}
+	if !session.Purpose.AllowsContentType(info.ContentType) {
+		return nil, fmt.Errorf("uploaded object content type %q is not allowed for %s", info.ContentType, session.Purpose)
+	}
+
+	ok, err := s.repo.UpdateStatus(ctx, session.ID, model.UploadStatusPending, model.UploadStatusCompleted)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrAlreadyCompleted
+	}
+	session.Status = model.UploadStatusCompleted
+
+	// topic / vocabulary / video uploader sẽ consume session qua *_upload_id
+	if session.Purpose != model.PurposeMediaAsset {
+		return session, nil
+	}
+
+	asset, err := s.mediaService.Register(ctx, session.Key, session.Bucket, session.FileName, info.ContentType, info.Size, session.Mode, session.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetMediaAsset(ctx, session.ID, asset.ID.Hex()); err != nil {
+		return nil, err
+	}
+	session.Status = model.UploadStatusConsumed
+	session.MediaAssetID = asset.ID.Hex()
+	return session, nil
+}
+
+func (s *uploadSessionService) Consume(ctx context.Context, id string, purpose model.UploadPurpose, bucket string) (string, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return "", fmt.Errorf("invalid upload id: %w", err)
+	}
+	session, err := s.repo.Consume(ctx, oid, purpose, helper.GetUserID(ctx))
+	if err != nil {
+		return "", err
+	}
+	if session == nil {
+		return "", fmt.Errorf("completed %s upload %s not found", purpose, id)
+	}
+	if session.Bucket != bucket {
+		if err := s.moveBucket(ctx, session, bucket); err != nil {
+			return "", err
+		}
+	}
+	return session.Key, nil
+}
+
+func (s *uploadSessionService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.sweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.sweepExpired(ctx)
+			}
+		}
+	}()
+}
+
+// sweepExpired huỷ multipart upload (hoặc object đã PUT qua presigned url) của các session hết hạn
+// rồi đánh dấu expired. Session huỷ lỗi giữ nguyên pending để lần chạy sau thử lại.
+func (s *uploadSessionService) sweepExpired(ctx context.Context) {
+	if !s.sweeping.TryLock() {
+		return
+	}
+	defer s.sweeping.Unlock()
+
+	for ctx.Err() == nil {
+		sessions, err := s.repo.FindExpired(ctx, time.Now(), sweepBatchSize)
+		if err != nil {
+			logger.WriteLogEx("error", "failed to list expired upload sessions", err)
+			return
+		}
+
+		expired := 0
+		for _, session := range sessions {
+			if s.expire(ctx, session) {
+				expired++
+			}
+		}
+		if len(sessions) < sweepBatchSize || expired == 0 {
+			return
+		}
+	}
+}
+
+func (s *uploadSessionService) expire(ctx context.Context, session *model.UploadSession) bool {
+	storage := s.s3Service.For(session.Bucket)
+	if !session.IsPresigned() {
+		// upload đã bị huỷ / complete mà chưa kịp đổi status thì S3 trả NoSuchUpload
+		if err := storage.AbortMultipartUpload(ctx, session.Key, session.UploadID); err != nil && !errors.Is(err, uploader.ErrObjectNotFound) {
+			logger.WriteLogEx("error", "failed to abort expired multipart upload", map[string]any{
+				"session_id": session.ID.Hex(),
+				"error":      err.Error(),
+			})
+			return false
+		}
+	}
+	// session pending chưa được gắn vào đâu, object (nếu có) là rác
+	if err := storage.Delete(ctx, session.Key); err != nil {
+		logger.WriteLogEx("error", "failed to delete object of expired upload session", map[string]any{
+			"session_id": session.ID.Hex(),
+			"error":      err.Error(),
+		})
+		return false
+	}
+	if _, err := s.repo.UpdateStatus(ctx, session.ID, model.UploadStatusPending, model.UploadStatusExpired); err != nil {
+		logger.WriteLogEx("error", "failed to mark upload session expired", err)
+		return false
+	}
+	return true
+}
+
+// ------------------- helpers -------------------
+
+// sessionKey đặt key theo storage.key_policy, session chưa biết document đích nên dùng id của session
+func sessionKey(ctx context.Context, id primitive.ObjectID, purpose model.UploadPurpose, folder, fileName string) (string, error) {
+	return objectkey.Build(objectkey.Object{
+		OrganizationID: helper.GetCurrentOrganizationID(ctx),
+		Entity:         objectkey.EntityUpload,
+		EntityID:       id.Hex(),
+		Slot:           string(purpose),
+		FileName:       fileName,
+		Folder:         folder,
+	})
+}
+
+func (s *uploadSessionService) getOwnedSession(ctx context.Context, id string) (*model.UploadSession, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.repo.GetByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.CreatedBy != helper.GetUserID(ctx) {
+		return nil, fmt.Errorf("upload session not found")
+	}
+	return session, nil
+}
+
+// moveBucket chép object của session sang bucket của document, xảy ra khi document đã có từ trước
+// khi routing thay đổi (document giữ bucket cũ, session mới theo routing hiện tại)
+func (s *uploadSessionService) moveBucket(ctx context.Context, session *model.UploadSession, bucket string) error {
+	src, dest := s.s3Service.For(session.Bucket), s.s3Service.For(bucket)
+	if src.Bucket() == dest.Bucket() {
+		return nil
+	}
+	obj, err := src.Open(ctx, session.Key, uploader.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer obj.Body.Close()
+
+	mode := uploader.UploadPrivate
+	if session.Purpose.IsPublic() {
+		mode = uploader.UploadPublic
+	}
+	if _, err := dest.SaveReader(ctx, obj.Body, session.Key, session.ContentType, mode); err != nil {
+		return err
+	}
+	if err := src.Delete(ctx, session.Key); err != nil {
+		logger.WriteLogEx("warn", "delete upload after moving it to another bucket", err)
+	}
+	return nil
+}
+
+func (s *uploadSessionService) ensurePending(session *model.UploadSession) error {
+	if session.Status != model.UploadStatusPending {
+		return fmt.Errorf("upload session is %s", session.Status)
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return fmt.Errorf("upload session has expired")
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Package cliprange parses the start / end times of audio and video clips into milliseconds
+// and checks them against the probed media duration.
+package cliprange
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalid is returned for an unparsable time or a range that is empty or outside the media.
+var ErrInvalid = errors.New("invalid clip range")
+
+// Range is a parsed clip, nil bounds were left empty in the form.
+type Range struct {
+	StartMs *int64
+	EndMs   *int64
+}
+
+// Parse parses both bounds and checks start < end when both are set.
+func Parse(start, end string) (Range, error) {
+	var r Range
+	var err error
+	if r.StartMs, err = ParseTime(start); err != nil {
+		return Range{}, err
+	}
+	if r.EndMs, err = ParseTime(end); err != nil {
+		return Range{}, err
+	}
+	if r.StartMs != nil && r.EndMs != nil && *r.StartMs >= *r.EndMs {
+		return Range{}, fmt.Errorf("%w: start %s must be before end %s", ErrInvalid, Format(*r.StartMs), Format(*r.EndMs))
+	}
+	return r, nil
+}
+
+// Validate checks that both bounds fall within a media of durationMs.
+// An unknown duration (0, probing failed or legacy record) is not checked.
+func (r Range) Validate(durationMs int64) error {
+	if durationMs <= 0 {
+		return nil
+	}
+	if r.StartMs != nil && *r.StartMs >= durationMs {
+		return fmt.Errorf("%w: start %s is past media duration %s", ErrInvalid, Format(*r.StartMs), Format(durationMs))
+	}
+	if r.EndMs != nil && *r.EndMs > durationMs {
+		return fmt.Errorf("%w: end %s is past media duration %s", ErrInvalid, Format(*r.EndMs), Format(durationMs))
+	}
+	return nil
+}
+
+// ParseTime accepts "ss[.fff]", "mm:ss[.fff]" and "hh:mm:ss[.fff]"; surrounding quotes and
+// blanks are ignored. An empty value returns nil. Fractions finer than a millisecond are truncated.
+func ParseTime(s string) (*int64, error) {
+	s = strings.TrimSpace(strings.Trim(strings.TrimSpace(s), "\""))
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+
+	secPart := parts[len(parts)-1]
+	frac := ""
+	if i := strings.IndexByte(secPart, '.'); i >= 0 {
+		secPart, frac = secPart[:i], secPart[i+1:]
+		if frac == "" || len(frac) > 9 || !isDigits(frac) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalid, s)
+		}
+	}
+
+	var total int64
+	for i, p := range append(parts[:len(parts)-1], secPart) {
+		if p == "" || len(p) > 9 || !isDigits(p) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalid, s)
+		}
+		v, _ := strconv.ParseInt(p, 10, 64)
+		// chỉ thành phần đầu tiên được vượt 59 (vd "90" giây hay "75:00" phút)
+		if i > 0 && v >= 60 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalid, s)
+		}
+		total = total*60 + v
+	}
+
+	ms := total * 1000
+	if frac != "" {
+		frac = (frac + "00")[:3]
+		v, _ := strconv.ParseInt(frac, 10, 64)
+		ms += v
+	}
+	return &ms, nil
+}
+
+// Format renders ms in the canonical "hh:mm:ss.mmm" form returned to clients.
+func Format(ms int64) string {
+	if ms < 0 {
+		ms = 0
+	}
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3_600_000, ms/60_000%60, ms/1000%60, ms%1000)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,161 @@
+package cliprange
+
+import (
+	"errors"
+	"testing"
+)
+
+func ms(v int64) *int64 { return &v }
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *int64
+	}{
+		{"", nil},
+		{"   ", nil},
+		{`""`, nil},
+		{"0", ms(0)},
+		{"5", ms(5000)},
+		{"90", ms(90_000)},
+		{"1.5", ms(1500)},
+		{"1.05", ms(1050)},
+		{"1.123456789", ms(1123)},
+		{"01:30", ms(90_000)},
+		{"75:00", ms(4_500_000)},
+		{"1:02:03", ms(3_723_000)},
+		{"01:02:03.004", ms(3_723_004)},
+		{` "00:00:10.250" `, ms(10_250)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTime(tt.in)
+			if err != nil {
+				t.Fatalf("ParseTime(%q): %v", tt.in, err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("ParseTime(%q) = %v, want %v", tt.in, deref(got), deref(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseTimeInvalid(t *testing.T) {
+	for _, in := range []string{
+		"abc",
+		"-1",
+		"1:",
+		":30",
+		"1::2",
+		"1:60",
+		"1:00:60",
+		"1:60:00",
+		"1:2:3:4",
+		"1.",
+		".5",
+		"1.5.0",
+		"1.1234567890",
+		"1,5",
+		"1e3",
+		"1234567890",
+	} {
+		t.Run(in, func(t *testing.T) {
+			if got, err := ParseTime(in); !errors.Is(err, ErrInvalid) {
+				t.Errorf("ParseTime(%q) = %v, %v; want ErrInvalid", in, deref(got), err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		want       Range
+		wantErr    bool
+	}{
+		{"empty", "", "", Range{}, false},
+		{"start only", "00:05", "", Range{StartMs: ms(5000)}, false},
+		{"end only", "", "10", Range{EndMs: ms(10_000)}, false},
+		{"both", "1.5", "00:00:03", Range{StartMs: ms(1500), EndMs: ms(3000)}, false},
+		{"start equals end", "5", "00:05", Range{}, true},
+		{"start after end", "10", "5", Range{}, true},
+		{"invalid start", "x", "5", Range{}, true},
+		{"invalid end", "1", "1:99", Range{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.start, tt.end)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("Parse(%q, %q) error = %v, want ErrInvalid", tt.start, tt.end, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q, %q): %v", tt.start, tt.end, err)
+			}
+			if deref(got.StartMs) != deref(tt.want.StartMs) || deref(got.EndMs) != deref(tt.want.EndMs) {
+				t.Errorf("Parse(%q, %q) = {%v %v}, want {%v %v}", tt.start, tt.end,
+					deref(got.StartMs), deref(got.EndMs), deref(tt.want.StartMs), deref(tt.want.EndMs))
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		r          Range
+		durationMs int64
+		wantErr    bool
+	}{
+		{"unknown duration", Range{StartMs: ms(50_000), EndMs: ms(90_000)}, 0, false},
+		{"open range", Range{}, 10_000, false},
+		{"inside", Range{StartMs: ms(1000), EndMs: ms(9000)}, 10_000, false},
+		{"end at duration", Range{EndMs: ms(10_000)}, 10_000, false},
+		{"end past duration", Range{EndMs: ms(10_001)}, 10_000, true},
+		{"start at duration", Range{StartMs: ms(10_000)}, 10_000, true},
+		{"start past duration", Range{StartMs: ms(20_000)}, 10_000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.r.Validate(tt.durationMs)
+			if tt.wantErr != errors.Is(err, ErrInvalid) || (!tt.wantErr && err != nil) {
+				t.Errorf("Validate(%d) = %v, wantErr %v", tt.durationMs, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		ms   int64
+		want string
+	}{
+		{-5, "00:00:00.000"},
+		{0, "00:00:00.000"},
+		{1500, "00:00:01.500"},
+		{3_723_004, "01:02:03.004"},
+		{4_500_000, "01:15:00.000"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.ms); got != tt.want {
+			t.Errorf("Format(%d) = %q, want %q", tt.ms, got, tt.want)
+		}
+	}
+	// Format là dạng chuẩn trả về client, phải parse lại được đúng giá trị
+	for _, v := range []int64{0, 999, 61_001, 3_723_004} {
+		got, err := ParseTime(Format(v))
+		if err != nil || *got != v {
+			t.Errorf("ParseTime(Format(%d)) = %v, %v", v, deref(got), err)
+		}
+	}
+}
+
+func deref(v *int64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
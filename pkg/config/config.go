@@ -103,22 +103,204 @@ type SenboxFormSubmitBucket struct {
 	SecretKey            string `yaml:"secret_key"`
 	CloudfrontKeyGroupID string `yaml:"cloudfront_key_group_id"`
 	CloudfrontKeyPath    string `yaml:"cloudfront_key_path"`
+
+	// S3-compatible storages (MinIO, Ceph, R2)
+	Endpoint     string `yaml:"endpoint"`       // empty = AWS
+	UsePathStyle bool   `yaml:"use_path_style"` // required by most MinIO / Ceph setups
+	URLStrategy  string `yaml:"url_strategy"`   // "cloudfront" (default) | "presigned"
+
+	// origin phục vụ "public/" không cần chữ ký (CloudFront behavior / bucket policy), rỗng = domain
+	PublicBaseURL string `yaml:"public_base_url"`
+
+	Encryption EncryptionConfig `yaml:"encryption"`
+}
+
+// EncryptionPolicy is the server-side encryption of one group of objects.
+type EncryptionPolicy struct {
+	Type        string `yaml:"type"`         // "none" (default) | "sse-s3" | "sse-kms" | "sse-c"
+	KMSKeyID    string `yaml:"kms_key_id"`   // sse-kms, empty = aws/s3 managed key
+	CustomerKey string `yaml:"customer_key"` // sse-c, base64 encoded 256-bit key
+}
+
+// EncryptionConfig: prefix khớp dài nhất > mode (public / private) > default
+type EncryptionConfig struct {
+	Default  EncryptionPolicy            `yaml:"default"`
+	Modes    map[string]EncryptionPolicy `yaml:"modes"`
+	Prefixes map[string]EncryptionPolicy `yaml:"prefixes"` // sse-c chỉ khai báo được ở đây
 }
 
 type S3 struct {
-	SenboxFormSubmitBucket SenboxFormSubmitBucket `yaml:"senbox-form-submit-bucket"`
+	SenboxFormSubmitBucket SenboxFormSubmitBucket `yaml:"senbox-form-submit-bucket"` // bucket mặc định
+	// các bucket khác theo tên, được chọn qua storage.routing
+	Buckets map[string]SenboxFormSubmitBucket `yaml:"buckets"`
 }
 
 // ---------------- S3 configuration ----------------
 
+// ---------------- Storage configuration ----------------
+type LocalStorage struct {
+	RootDir       string `yaml:"root_dir"`
+	BaseURL       string `yaml:"base_url"`
+	SigningSecret string `yaml:"signing_secret"`
+}
+
+type SignedURLCache struct {
+	Enabled       bool `yaml:"enabled"`
+	BucketMinutes int  `yaml:"bucket_minutes"` // default 60, signed urls expiring in the same window share one cache entry
+}
+
+// StorageRouting chọn bucket (tên trong s3.buckets) cho object mới, không khớp = bucket mặc định
+type StorageRouting struct {
+	Categories    map[string]string `yaml:"categories"`    // topic | vocabulary | student_resource | document | video | media_asset -> bucket
+	Organizations map[string]string `yaml:"organizations"` // organization_id -> bucket, ưu tiên hơn categories (data residency)
+}
+
+type Storage struct {
+	Provider       string         `yaml:"provider"` // "s3" (default) or "local"
+	Local          LocalStorage   `yaml:"local"`
+	SignedURLCache SignedURLCache `yaml:"signed_url_cache"`
+	// thời hạn tối đa client được chọn khi xin signed url (ttl_seconds), default 168 (7 ngày)
+	SignedURLMaxTTLHours int            `yaml:"signed_url_max_ttl_hours"`
+	Routing              StorageRouting `yaml:"routing"`
+	// KeyPolicy đặt tên object mới: "legacy" (default, <folder>/<nanos>_<title>) | "scoped" (<folder>/org/<orgID>/<entity>/<id>/...)
+	KeyPolicy string `yaml:"key_policy"`
+}
+
+// ---------------- Storage configuration ----------------
+
+// ---------------- Upload configuration ----------------
+type UploadConfig struct {
+	MultipartPartSizeMB int `yaml:"multipart_part_size_mb"` // default 8, S3 requires >= 5
+	SessionTTLHours     int `yaml:"session_ttl_hours"`      // default 168 (7 days)
+	PresignTTLMinutes   int `yaml:"presign_ttl_minutes"`    // default 15, lifetime of a presigned PUT url
+	PresignMaxSizeMB    int `yaml:"presign_max_size_mb"`    // default 5120, S3 single PUT limit
+	ExpirySweepMinutes  int `yaml:"expiry_sweep_minutes"`   // default 60, interval of the expired session cleanup
+}
+
+// ---------------- Upload configuration ----------------
+
+// ---------------- Storage GC configuration ----------------
+type GCConfig struct {
+	Enabled          bool     `yaml:"enabled"`            // chạy định kỳ trong service
+	IntervalHours    int      `yaml:"interval_hours"`     // default 24
+	GracePeriodHours int      `yaml:"grace_period_hours"` // default 72, object mới hơn sẽ không bị xem là orphan
+	DryRun           bool     `yaml:"dry_run"`            // chỉ báo cáo, không xoá
+	Prefixes         []string `yaml:"prefixes"`           // thêm prefix ngoài các prefix mặc định
+}
+
+// ---------------- Storage GC configuration ----------------
+
+// ---------------- Deletion outbox configuration ----------------
+type OutboxConfig struct {
+	PollIntervalSeconds int `yaml:"poll_interval_seconds"` // default 10
+	MaxAttempts         int `yaml:"max_attempts"`          // default 10, sau đó entry chuyển sang failed
+}
+
+// ---------------- Deletion outbox configuration ----------------
+
+// ---------------- Trash configuration ----------------
+type TrashConfig struct {
+	RetentionDays      int `yaml:"retention_days"`       // default 30, sau đó item bị xoá vĩnh viễn
+	PurgeIntervalHours int `yaml:"purge_interval_hours"` // default 24
+}
+
+// ---------------- Trash configuration ----------------
+
+// ---------------- Storage quota configuration ----------------
+type QuotaConfig struct {
+	DefaultMB     int64            `yaml:"default_mb"`    // 0 = không giới hạn
+	Organizations map[string]int64 `yaml:"organizations"` // organization_id -> MB, ghi đè default_mb; "global" cho upload không có organization
+}
+
+// ---------------- Storage quota configuration ----------------
+
+// ---------------- Storage tiering configuration ----------------
+// TieringRule chuyển ảnh topic_resources có image_key dưới Prefix và cũ hơn MinAgeDays sang StorageClass
+type TieringRule struct {
+	Prefix       string `yaml:"prefix"`        // ví dụ "topic_resource/"
+	MinAgeDays   int    `yaml:"min_age_days"`  // tính từ created_at của document
+	StorageClass string `yaml:"storage_class"` // STANDARD_IA | INTELLIGENT_TIERING | GLACIER_IR | GLACIER | DEEP_ARCHIVE
+}
+
+type TieringConfig struct {
+	Enabled       bool          `yaml:"enabled"`        // chạy định kỳ trong service
+	IntervalHours int           `yaml:"interval_hours"` // default 24
+	Method        string        `yaml:"method"`         // "copy" (default, copy-in-place đổi class) | "tag" (gắn tag storage-class cho lifecycle rule)
+	RestoreDays   int           `yaml:"restore_days"`   // default 7, thời gian bản khôi phục của object GLACIER / DEEP_ARCHIVE tồn tại
+	RestoreTier   string        `yaml:"restore_tier"`   // "Standard" (default) | "Expedited" | "Bulk"
+	Rules         []TieringRule `yaml:"rules"`
+}
+
+// ---------------- Storage tiering configuration ----------------
+
+// ---------------- Consistency check configuration ----------------
+type ConsistencyConfig struct {
+	Enabled       bool   `yaml:"enabled"`        // quét định kỳ trong service
+	IntervalHours int    `yaml:"interval_hours"` // default 24
+	Action        string `yaml:"action"`         // cho lần quét định kỳ: "report" (default) | "clear" | "flag"
+	Concurrency   int    `yaml:"concurrency"`    // số HEAD chạy song song, default 8
+}
+
+// ---------------- Consistency check configuration ----------------
+
+// ---------------- Image processing configuration ----------------
+type ImageVariantConfig struct {
+	Name  string `yaml:"name"`  // thumbnail | medium | large...
+	Width int    `yaml:"width"` // chiều rộng tối đa, giữ tỉ lệ
+}
+
+type ImageConfig struct {
+	Variants    []ImageVariantConfig `yaml:"variants"`     // rỗng = không tạo variant
+	JPEGQuality int                  `yaml:"jpeg_quality"` // default 82
+	MaxPixels   int                  `yaml:"max_pixels"`   // default 40000000, ảnh lớn hơn không được giải nén (chỉ bỏ metadata)
+}
+
+// ---------------- Image processing configuration ----------------
+
+// ---------------- Media probing configuration ----------------
+type ProbeConfig struct {
+	Backend        string `yaml:"backend"`         // "native" (default, thuần Go) | "ffprobe"
+	FFProbePath    string `yaml:"ffprobe_path"`    // default "ffprobe" trong PATH
+	TimeoutSeconds int    `yaml:"timeout_seconds"` // chỉ áp dụng cho ffprobe, default 15
+}
+
+// ---------------- Media probing configuration ----------------
+
+// ---------------- Storage migration configuration ----------------
+// StorageTarget là một nơi lưu object (bucket S3 hoặc thư mục local) cho mediactl migrate-storage
+type StorageTarget struct {
+	Provider string                 `yaml:"provider"` // "s3" (default) | "local"
+	S3       SenboxFormSubmitBucket `yaml:"s3"`
+	Local    LocalStorage           `yaml:"local"`
+}
+
+type MigrationConfig struct {
+	Source      *StorageTarget `yaml:"source"` // bỏ trống = storage service đang dùng (storage + s3)
+	Destination StorageTarget  `yaml:"destination"`
+	Concurrency int            `yaml:"concurrency"` // default 8
+}
+
+// ---------------- Storage migration configuration ----------------
+
 type AppConfigStruct struct {
-	Server   ServerConfig     `yaml:"server"`
-	Database DatabaseConfig   `yaml:"database"`
-	Consul   ConsulConfig     `yaml:"consul"`
-	Zap      ZapConfig        `mapstructure:"zap"`
-	Registry Registry         `mapstructure:"registry" validate:"required"`
-	App      AppConfiguration `mapstructure:"app"`
-	S3       S3               `yaml:"s3"`
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Consul      ConsulConfig      `yaml:"consul"`
+	Zap         ZapConfig         `mapstructure:"zap"`
+	Registry    Registry          `mapstructure:"registry" validate:"required"`
+	App         AppConfiguration  `mapstructure:"app"`
+	S3          S3                `yaml:"s3"`
+	Storage     Storage           `yaml:"storage"`
+	Upload      UploadConfig      `yaml:"upload"`
+	GC          GCConfig          `yaml:"gc"`
+	Outbox      OutboxConfig      `yaml:"outbox"`
+	Trash       TrashConfig       `yaml:"trash"`
+	Quota       QuotaConfig       `yaml:"quota"`
+	Tiering     TieringConfig     `yaml:"tiering"`
+	Consistency ConsistencyConfig `yaml:"consistency"`
+	Image       ImageConfig       `yaml:"image"`
+	Probe       ProbeConfig       `yaml:"probe"`
+	Migration   MigrationConfig   `yaml:"migration"`
 }
 
 var AppConfig *AppConfigStruct
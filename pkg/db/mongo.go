@@ -18,6 +18,9 @@ var TopicResourceCollection *mongo.Collection
 var VideoUploaderCollection *mongo.Collection
 var MediaAssetCollection *mongo.Collection
 var VocabularyCollection *mongo.Collection
+var UploadSessionCollection *mongo.Collection
+var DeletionOutboxCollection *mongo.Collection
+var StorageUsageCollection *mongo.Collection
 
 func ConnectMongoDB() {
 	d := config.AppConfig.Database.Mongo
@@ -56,5 +59,8 @@ func ConnectMongoDB() {
 	VideoUploaderCollection = MongoClient.Database(d.Name).Collection("video_uploaders")
 	MediaAssetCollection = MongoClient.Database(d.Name).Collection("media_assets")
 	VocabularyCollection = MongoClient.Database(d.Name).Collection("vocabularies")
-	log.Println("Connected to MongoDB and loaded 'topics', 'pdf_resources', 'topic_resources', 'video_uploaders', 'media_assets', 'vocabularies' collections")
+	UploadSessionCollection = MongoClient.Database(d.Name).Collection("upload_sessions")
+	DeletionOutboxCollection = MongoClient.Database(d.Name).Collection("deletion_outbox")
+	StorageUsageCollection = MongoClient.Database(d.Name).Collection("storage_usage")
+	log.Println("Connected to MongoDB and loaded 'topics', 'pdf_resources', 'topic_resources', 'video_uploaders', 'media_assets', 'vocabularies', 'upload_sessions', 'deletion_outbox', 'storage_usage' collections")
 }
@@ -0,0 +1,153 @@
+// Package imagevariant processes uploaded images in pure Go: resized copies for clients
+// and metadata / orientation normalization before storing originals.
+package imagevariant
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"path"
+	"strings"
+
+	"media-service/pkg/config"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	defaultJPEGQuality = 82
+	defaultMaxPixels   = 40_000_000
+)
+
+// ErrTooLarge is returned when the source image has more pixels than image.max_pixels.
+var ErrTooLarge = errors.New("image too large to process")
+
+// Size is one configured variant.
+type Size struct {
+	Name  string
+	Width int
+}
+
+// Variant is an encoded resized copy, ready to be stored next to the original.
+type Variant struct {
+	Name        string
+	Width       int
+	Height      int
+	ContentType string
+	Data        []byte
+}
+
+// Sizes returns the variants configured in image.variants, in config order.
+func Sizes() []Size {
+	sizes := make([]Size, 0, len(config.AppConfig.Image.Variants))
+	for _, v := range config.AppConfig.Image.Variants {
+		name := strings.TrimSpace(v.Name)
+		if name == "" || v.Width <= 0 {
+			continue
+		}
+		sizes = append(sizes, Size{Name: name, Width: v.Width})
+	}
+	return sizes
+}
+
+// Key returns the sibling key of a variant: "a/b/c.png" + "thumbnail" -> "a/b/c_thumbnail.png".
+func Key(originalKey, name string) string {
+	ext := path.Ext(originalKey)
+	return strings.TrimSuffix(originalKey, ext) + "_" + name + ext
+}
+
+// Image is a decoded upload with its EXIF orientation applied. Variants and the placeholder
+// are both derived from it, so the source is decoded only once.
+type Image struct {
+	src    image.Image
+	format string
+}
+
+// Decode decodes a JPEG, PNG or GIF image. Other formats return nil without error.
+func Decode(data []byte) (*Image, error) {
+	// đọc header trước để không giải nén ảnh quá lớn
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png" && format != "gif") {
+		return nil, nil
+	}
+	if cfg.Width*cfg.Height > maxPixels() {
+		return nil, ErrTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	return &Image{src: orient(src, orientation(data, format)), format: format}, nil
+}
+
+// Variants returns one variant per configured size narrower than the image.
+// GIF images return no variants and no error.
+func (img *Image) Variants() ([]Variant, error) {
+	sizes := Sizes()
+	if len(sizes) == 0 || (img.format != "jpeg" && img.format != "png") {
+		return nil, nil
+	}
+	src := img.src
+	bounds := src.Bounds()
+
+	variants := make([]Variant, 0, len(sizes))
+	for _, size := range sizes {
+		if size.Width >= bounds.Dx() {
+			// không phóng to: client dùng ảnh gốc
+			continue
+		}
+		height := bounds.Dy() * size.Width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+		dst := image.NewRGBA(image.Rect(0, 0, size.Width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+		encoded, contentType, err := encode(dst, img.format)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s variant: %w", size.Name, err)
+		}
+		variants = append(variants, Variant{
+			Name:        size.Name,
+			Width:       size.Width,
+			Height:      height,
+			ContentType: contentType,
+			Data:        encoded,
+		})
+	}
+	return variants, nil
+}
+
+// encode giữ định dạng gốc: png giữ được nền trong suốt (clear_background, clip_part...)
+func encode(img image.Image, format string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if format == "png" {
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := enc.Encode(&buf, img); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	}
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality()}); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/jpeg", nil
+}
+
+func jpegQuality() int {
+	q := config.AppConfig.Image.JPEGQuality
+	if q <= 0 || q > 100 {
+		return defaultJPEGQuality
+	}
+	return q
+}
+
+func maxPixels() int {
+	if n := config.AppConfig.Image.MaxPixels; n > 0 {
+		return n
+	}
+	return defaultMaxPixels
+}
@@ -0,0 +1,248 @@
+package imagevariant
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// Normalize rotates a JPEG or PNG image according to its EXIF orientation and re-encodes it,
+// which drops every metadata block (EXIF with GPS / device info, XMP, comments...).
+// ok is false for other formats; callers store those unchanged.
+func Normalize(data []byte) (out []byte, contentType string, ok bool, err error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png") {
+		return nil, "", false, nil
+	}
+	if cfg.Width*cfg.Height > maxPixels() {
+		// quá lớn để giải nén: vẫn bỏ metadata nhưng không xoay được
+		out, err := stripMetadata(data, format)
+		if err != nil {
+			return nil, "", false, err
+		}
+		return out, "image/" + format, true, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", false, fmt.Errorf("decode image: %w", err)
+	}
+	out, contentType, err = encode(orient(src, orientation(data, format)), format)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return out, contentType, true, nil
+}
+
+// Sanitize is the upload pipeline entry point: JPEG and PNG go through Normalize, other formats
+// are returned unchanged. changed reports whether out differs from data.
+func Sanitize(data []byte) (out []byte, changed bool, err error) {
+	out, _, ok, err := Normalize(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid image: %w", err)
+	}
+	if !ok {
+		return data, false, nil
+	}
+	return out, true, nil
+}
+
+// orientation đọc tag Orientation (0x0112) của EXIF, 1 = không cần xoay
+func orientation(data []byte, format string) int {
+	var tiff []byte
+	if format == "jpeg" {
+		tiff = jpegExif(data)
+	} else {
+		tiff = pngExif(data)
+	}
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// jpegExif trả về phần TIFF trong segment APP1 "Exif", nil nếu không có
+func jpegExif(data []byte) []byte {
+	var exif []byte
+	_ = eachJPEGSegment(data, func(marker byte, segment []byte) bool {
+		if marker == 0xE1 && bytes.HasPrefix(segment[4:], []byte("Exif\x00\x00")) {
+			exif = segment[10:]
+			return false
+		}
+		return true
+	})
+	return exif
+}
+
+// pngExif trả về nội dung chunk eXIf, nil nếu không có
+func pngExif(data []byte) []byte {
+	var exif []byte
+	_ = eachPNGChunk(data, func(typ string, chunk []byte) bool {
+		if typ == "eXIf" {
+			exif = chunk[8 : len(chunk)-4]
+			return false
+		}
+		return true
+	})
+	return exif
+}
+
+// orient xoay / lật ảnh về hướng hiển thị đúng theo 8 giá trị Orientation của EXIF
+func orient(src image.Image, o int) image.Image {
+	if o <= 1 || o > 8 {
+		return src
+	}
+	b := src.Bounds()
+	in := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(in, in.Bounds(), src, b.Min, draw.Src)
+
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if o >= 5 {
+		// 5..8 đổi chiều rộng / cao
+		dw, dh = h, w
+	}
+	out := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch o {
+			case 2: // lật ngang
+				dx, dy = w-1-x, y
+			case 3: // xoay 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // lật dọc
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // xoay 90 theo chiều kim đồng hồ
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // xoay 90 ngược chiều kim đồng hồ
+				dx, dy = y, w-1-x
+			}
+			copy(out.Pix[out.PixOffset(dx, dy):out.PixOffset(dx, dy)+4], in.Pix[in.PixOffset(x, y):in.PixOffset(x, y)+4])
+		}
+	}
+	return out
+}
+
+// stripMetadata bỏ metadata mà không giải nén ảnh: APP1..APP15 (trừ APP14 Adobe, cần để đọc đúng màu)
+// và COM của jpeg, các chunk text / eXIf / tIME của png.
+func stripMetadata(data []byte, format string) ([]byte, error) {
+	var out bytes.Buffer
+	if format == "jpeg" {
+		out.Write(data[:2])
+		err := eachJPEGSegment(data, func(marker byte, segment []byte) bool {
+			isApp := marker >= 0xE1 && marker <= 0xEF && marker != 0xEE
+			if !isApp && marker != 0xFE {
+				out.Write(segment)
+			}
+			return true
+		})
+		return out.Bytes(), err
+	}
+
+	out.Write(pngSignature)
+	err := eachPNGChunk(data, func(typ string, chunk []byte) bool {
+		switch typ {
+		case "tEXt", "zTXt", "iTXt", "eXIf", "tIME":
+		default:
+			out.Write(chunk)
+		}
+		return true
+	})
+	return out.Bytes(), err
+}
+
+var errMalformed = errors.New("malformed image")
+
+// eachJPEGSegment gọi fn với từng segment (gồm cả marker và độ dài) trước SOS;
+// phần còn lại từ SOS (dữ liệu ảnh) được trả về như một segment cuối cùng với marker 0xDA.
+func eachJPEGSegment(data []byte, fn func(marker byte, segment []byte) bool) error {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return errMalformed
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return errMalformed
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// byte đệm
+			i++
+			continue
+		}
+		if marker == 0xDA {
+			fn(marker, data[i:])
+			return nil
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			return errMalformed
+		}
+		if !fn(marker, data[i:end]) {
+			return nil
+		}
+		i = end
+	}
+	return errMalformed
+}
+
+// eachPNGChunk gọi fn với từng chunk đầy đủ (length, type, data, crc)
+func eachPNGChunk(data []byte, fn func(typ string, chunk []byte) bool) error {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return errMalformed
+	}
+	for i := len(pngSignature); i+12 <= len(data); {
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i {
+			return errMalformed
+		}
+		chunk := data[i:end]
+		if crc32.ChecksumIEEE(chunk[4:len(chunk)-4]) != binary.BigEndian.Uint32(chunk[len(chunk)-4:]) {
+			return errMalformed
+		}
+		typ := string(chunk[4:8])
+		if !fn(typ, chunk) || typ == "IEND" {
+			return nil
+		}
+		i = end
+	}
+	return errMalformed
+}
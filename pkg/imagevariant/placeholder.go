@@ -0,0 +1,162 @@
+package imagevariant
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // ảnh gif (slot gif của topic) chỉ lấy frame đầu cho placeholder
+	"math"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// kích thước ảnh thu nhỏ dùng để tính placeholder, đủ cho blurhash 4x3
+	placeholderSize = 32
+	blurHashX       = 4
+	blurHashY       = 3
+	base83Chars     = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+)
+
+// Placeholder is shown by clients while the real image loads.
+type Placeholder struct {
+	BlurHash      string `json:"blurhash" bson:"blurhash"`
+	DominantColor string `json:"dominant_color" bson:"dominant_color"` // #rrggbb
+}
+
+// NewPlaceholder computes the BlurHash and dominant color of a JPEG, PNG or GIF image.
+// Other formats return nil without error.
+func NewPlaceholder(data []byte) (*Placeholder, error) {
+	img, err := Decode(data)
+	if err != nil || img == nil {
+		return nil, err
+	}
+	return img.Placeholder(), nil
+}
+
+// Placeholder computes the BlurHash and dominant color of the image.
+func (img *Image) Placeholder() *Placeholder {
+	b := img.src.Bounds()
+	w, h := placeholderSize, placeholderSize
+	if b.Dx() > b.Dy() {
+		h = max(1, placeholderSize*b.Dy()/b.Dx())
+	} else {
+		w = max(1, placeholderSize*b.Dx()/b.Dy())
+	}
+	// nền trong suốt (clear_background...) được coi là trắng như khi app hiển thị
+	small := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(small, small.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), img.src, b, draw.Over, nil)
+
+	return &Placeholder{
+		BlurHash:      blurHash(small),
+		DominantColor: dominantColor(small),
+	}
+}
+
+// blurHash mã hoá ảnh theo thuật toán https://blurha.sh với blurHashX x blurHashY thành phần
+func blurHash(img *image.RGBA) string {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	factors := make([][3]float64, 0, blurHashX*blurHashY)
+	for j := 0; j < blurHashY; j++ {
+		for i := 0; i < blurHashX; i++ {
+			var r, g, b float64
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(w)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(h))
+					p := img.Pix[img.PixOffset(x, y):]
+					r += basis * srgbToLinear(p[0])
+					g += basis * srgbToLinear(p[1])
+					b += basis * srgbToLinear(p[2])
+				}
+			}
+			norm := 2.0
+			if i == 0 && j == 0 {
+				norm = 1
+			}
+			scale := norm / float64(w*h)
+			factors = append(factors, [3]float64{r * scale, g * scale, b * scale})
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(base83((blurHashX-1)+(blurHashY-1)*9, 1))
+
+	dc, ac := factors[0], factors[1:]
+	maxValue := 1.0
+	if len(ac) > 0 {
+		actualMax := 0.0
+		for _, f := range ac {
+			actualMax = math.Max(actualMax, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+		quantisedMax := int(math.Max(0, math.Min(82, math.Floor(actualMax*166-0.5))))
+		maxValue = float64(quantisedMax+1) / 166
+		sb.WriteString(base83(quantisedMax, 1))
+	} else {
+		sb.WriteString(base83(0, 1))
+	}
+
+	sb.WriteString(base83(linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), 4))
+	for _, f := range ac {
+		q := func(v float64) int {
+			return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maxValue, 0.5)*9+9.5))))
+		}
+		sb.WriteString(base83(q(f[0])*19*19+q(f[1])*19+q(f[2]), 2))
+	}
+	return sb.String()
+}
+
+// dominantColor gom màu vào 4096 nhóm (4 bit mỗi kênh) và lấy trung bình của nhóm đông nhất
+func dominantColor(img *image.RGBA) string {
+	type bucket struct{ r, g, b, n int }
+	buckets := map[int]*bucket{}
+	var top *bucket
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		r, g, b := int(img.Pix[i]), int(img.Pix[i+1]), int(img.Pix[i+2])
+		k := r>>4<<8 | g>>4<<4 | b>>4
+		bk := buckets[k]
+		if bk == nil {
+			bk = &bucket{}
+			buckets[k] = bk
+		}
+		bk.r, bk.g, bk.b, bk.n = bk.r+r, bk.g+g, bk.b+b, bk.n+1
+		if top == nil || bk.n > top.n {
+			top = bk
+		}
+	}
+	if top == nil {
+		return "#ffffff"
+	}
+	return fmt.Sprintf("#%02x%02x%02x", top.r/top.n, top.g/top.n, top.b/top.n)
+}
+
+func srgbToLinear(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
+
+func base83(value, length int) string {
+	out := make([]byte, length)
+	for i := 1; i <= length; i++ {
+		digit := value / int(math.Pow(83, float64(length-i))) % 83
+		out[i-1] = base83Chars[digit]
+	}
+	return string(out)
+}
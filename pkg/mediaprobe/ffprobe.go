@@ -0,0 +1,114 @@
+package mediaprobe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultFFProbeTimeout = 15 * time.Second
+
+// ffprobe trả về danh sách demuxer, rút gọn về tên container giống native prober
+var ffprobeContainers = map[string]string{
+	"mov,mp4,m4a,3gp,3g2,mj2": "mp4",
+	"matroska,webm":           "webm",
+	"wav":                     "wav",
+	"ogg":                     "ogg",
+	"mp3":                     "mp3",
+}
+
+// FFProbe runs the ffprobe binary. It understands more containers than NativeProber
+// but needs ffprobe installed on the host.
+type FFProbe struct {
+	path    string
+	timeout time.Duration
+}
+
+// NewFFProbe returns a prober running path ("ffprobe" from PATH when empty).
+func NewFFProbe(path string, timeout time.Duration) *FFProbe {
+	if strings.TrimSpace(path) == "" {
+		path = "ffprobe"
+	}
+	if timeout <= 0 {
+		timeout = defaultFFProbeTimeout
+	}
+	return &FFProbe{path: path, timeout: timeout}
+}
+
+type ffprobeOutput struct {
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"streams"`
+}
+
+func (p *FFProbe) Probe(ctx context.Context, r io.ReaderAt, size int64) (*Info, error) {
+	// ffprobe cần seek được (moov ở cuối file mp4) nên không đọc qua stdin
+	tmp, err := os.CreateTemp("", "mediaprobe-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, io.NewSectionReader(r, 0, size))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, p.path,
+		"-v", "error", "-print_format", "json", "-show_format", "-show_streams", tmp.Name()).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe: %w", err)
+	}
+
+	var parsed ffprobeOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return nil, fmt.Errorf("ffprobe output: %w", err)
+	}
+	if parsed.Format.FormatName == "" {
+		return nil, ErrUnsupported
+	}
+
+	info := &Info{Container: parsed.Format.FormatName}
+	if c, ok := ffprobeContainers[parsed.Format.FormatName]; ok {
+		info.Container = c
+	}
+	if d, err := strconv.ParseFloat(parsed.Format.Duration, 64); err == nil && d > 0 && !math.IsInf(d, 0) {
+		info.DurationMs = int64(d * 1000)
+	}
+	if b, err := strconv.ParseInt(parsed.Format.BitRate, 10, 64); err == nil {
+		info.Bitrate = b
+	}
+	for _, s := range parsed.Streams {
+		switch s.CodecType {
+		case "video":
+			// ảnh bìa (mjpeg / png) của mp3 / m4a cũng là stream video
+			if info.VideoCodec == "" && s.CodecName != "mjpeg" && s.CodecName != "png" {
+				info.VideoCodec, info.Width, info.Height = s.CodecName, s.Width, s.Height
+			}
+		case "audio":
+			if info.AudioCodec == "" {
+				info.AudioCodec = s.CodecName
+			}
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,329 @@
+package mediaprobe
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+)
+
+var ebmlMagic = []byte{0x1A, 0x45, 0xDF, 0xA3}
+
+// id của các element EBML cần đọc (giữ nguyên bit đánh dấu độ dài như trong spec)
+const (
+	ebmlHeaderID    = 0x1A45DFA3
+	ebmlDocTypeID   = 0x4282
+	mkvSegmentID    = 0x18538067
+	mkvInfoID       = 0x1549A966
+	mkvTimecodeID   = 0x2AD7B1
+	mkvDurationID   = 0x4489
+	mkvTracksID     = 0x1654AE6B
+	mkvTrackEntryID = 0xAE
+	mkvTrackTypeID  = 0x83
+	mkvCodecID      = 0x86
+	mkvVideoID      = 0xE0
+	mkvPixelWidth   = 0xB0
+	mkvPixelHeight  = 0xBA
+	mkvClusterID    = 0x1F43B675
+)
+
+var matroskaCodecs = map[string]string{
+	"V_VP8":            "vp8",
+	"V_VP9":            "vp9",
+	"V_AV1":            "av1",
+	"V_MPEG4/ISO/AVC":  "h264",
+	"V_MPEGH/ISO/HEVC": "hevc",
+	"V_MPEG4/ISO/ASP":  "mpeg4",
+	"V_THEORA":         "theora",
+	"A_OPUS":           "opus",
+	"A_VORBIS":         "vorbis",
+	"A_AAC":            "aac",
+	"A_MPEG/L3":        "mp3",
+	"A_FLAC":           "flac",
+	"A_AC3":            "ac3",
+	"A_EAC3":           "eac3",
+	"A_PCM/INT/LIT":    "pcm",
+	"A_PCM/FLOAT/IEEE": "pcm_float",
+}
+
+type ebmlElement struct {
+	id   uint32
+	off  int64 // đầu payload
+	size int64 // -1 = không xác định (segment / cluster đang stream)
+}
+
+// probeMatroska đọc Info và Tracks của segment đầu tiên, dừng ở cluster đầu tiên
+func probeMatroska(r io.ReaderAt, size int64) (*Info, error) {
+	info := &Info{Container: "matroska"}
+	off := int64(0)
+	for off < size {
+		el, err := readEBMLElement(r, off, size)
+		if err != nil {
+			return nil, err
+		}
+		end := elementEnd(el, size)
+		switch el.id {
+		case ebmlHeaderID:
+			if err := walkEBML(r, el.off, end, size, func(child ebmlElement) error {
+				if child.id == ebmlDocTypeID {
+					docType, err := readEBMLString(r, child, size)
+					if err != nil {
+						return err
+					}
+					if docType == "webm" {
+						info.Container = "webm"
+					}
+				}
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		case mkvSegmentID:
+			if err := probeSegment(r, el.off, end, size, info); err != nil {
+				return nil, err
+			}
+			return info, nil
+		}
+		off = end
+	}
+	return nil, errMalformed
+}
+
+func probeSegment(r io.ReaderAt, start, end, size int64, info *Info) error {
+	timecodeScale := uint64(1_000_000)
+	var duration float64
+	foundInfo, foundTracks := false, false
+
+	err := walkEBML(r, start, end, size, func(el ebmlElement) error {
+		var err error
+		switch el.id {
+		case mkvInfoID:
+			foundInfo = true
+			err = walkEBML(r, el.off, elementEnd(el, size), size, func(child ebmlElement) error {
+				switch child.id {
+				case mkvTimecodeID:
+					v, err := readEBMLUint(r, child, size)
+					if err != nil {
+						return err
+					}
+					if v > 0 {
+						timecodeScale = v
+					}
+				case mkvDurationID:
+					v, err := readEBMLFloat(r, child, size)
+					if err != nil {
+						return err
+					}
+					duration = v
+				}
+				return nil
+			})
+		case mkvTracksID:
+			foundTracks = true
+			err = walkEBML(r, el.off, elementEnd(el, size), size, func(track ebmlElement) error {
+				if track.id != mkvTrackEntryID {
+					return nil
+				}
+				return probeTrackEntry(r, track, size, info)
+			})
+		case mkvClusterID:
+			// Info / Tracks luôn đứng trước cluster trong file do muxer thông dụng tạo ra
+			return errStopWalk
+		}
+		if err != nil {
+			return err
+		}
+		if foundInfo && foundTracks {
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil && err != errStopWalk {
+		return err
+	}
+	if !foundTracks {
+		return errMalformed
+	}
+	// Duration tính theo đơn vị TimecodeScale nano giây; webm ghi trực tiếp (MediaRecorder) thường không có
+	if duration > 0 && !math.IsInf(duration, 0) && !math.IsNaN(duration) {
+		info.DurationMs = int64(duration * float64(timecodeScale) / 1e6)
+	}
+	return nil
+}
+
+func probeTrackEntry(r io.ReaderAt, track ebmlElement, size int64, info *Info) error {
+	var trackType uint64
+	var codecID string
+	var width, height uint64
+	err := walkEBML(r, track.off, elementEnd(track, size), size, func(el ebmlElement) error {
+		var err error
+		switch el.id {
+		case mkvTrackTypeID:
+			trackType, err = readEBMLUint(r, el, size)
+		case mkvCodecID:
+			codecID, err = readEBMLString(r, el, size)
+		case mkvVideoID:
+			err = walkEBML(r, el.off, elementEnd(el, size), size, func(v ebmlElement) error {
+				var err error
+				switch v.id {
+				case mkvPixelWidth:
+					width, err = readEBMLUint(r, v, size)
+				case mkvPixelHeight:
+					height, err = readEBMLUint(r, v, size)
+				}
+				return err
+			})
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// TrackType: 1 = video, 2 = audio
+	switch trackType {
+	case 1:
+		if info.VideoCodec == "" {
+			info.VideoCodec = matroskaCodec(codecID)
+			info.Width, info.Height = int(width), int(height)
+		}
+	case 2:
+		if info.AudioCodec == "" {
+			info.AudioCodec = matroskaCodec(codecID)
+		}
+	}
+	return nil
+}
+
+// walkEBML gọi fn với từng element con trong [start, end)
+func walkEBML(r io.ReaderAt, start, end, size int64, fn func(el ebmlElement) error) error {
+	for off := start; off < end; {
+		el, err := readEBMLElement(r, off, size)
+		if err != nil {
+			return err
+		}
+		if err := fn(el); err != nil {
+			return err
+		}
+		next := elementEnd(el, size)
+		if el.size < 0 || next > end {
+			// element không rõ độ dài chỉ có thể là phần tử cuối của cha
+			return nil
+		}
+		off = next
+	}
+	return nil
+}
+
+func elementEnd(el ebmlElement, size int64) int64 {
+	if el.size < 0 || el.off+el.size > size {
+		return size
+	}
+	return el.off + el.size
+}
+
+func readEBMLElement(r io.ReaderAt, off, size int64) (ebmlElement, error) {
+	hdr, err := readAt(r, off, 12, size)
+	if err != nil {
+		return ebmlElement{}, err
+	}
+	idLen := vintLength(hdr[0])
+	if idLen == 0 || idLen > 4 || idLen >= len(hdr) {
+		return ebmlElement{}, errMalformed
+	}
+	var id uint32
+	for _, b := range hdr[:idLen] {
+		id = id<<8 | uint32(b)
+	}
+
+	sizeLen := vintLength(hdr[idLen])
+	if sizeLen == 0 || idLen+sizeLen > len(hdr) {
+		return ebmlElement{}, errMalformed
+	}
+	raw := hdr[idLen : idLen+sizeLen]
+	value := uint64(raw[0]) & (0xFF >> sizeLen)
+	allOnes := value == uint64(0xFF>>sizeLen)
+	for _, b := range raw[1:] {
+		value = value<<8 | uint64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+
+	el := ebmlElement{id: id, off: off + int64(idLen+sizeLen), size: int64(value)}
+	if allOnes {
+		el.size = -1
+	}
+	return el, nil
+}
+
+// vintLength là số byte của một variable-size integer, xác định bởi vị trí bit 1 đầu tiên
+func vintLength(b byte) int {
+	for i := 0; i < 8; i++ {
+		if b&(0x80>>i) != 0 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func readEBMLUint(r io.ReaderAt, el ebmlElement, size int64) (uint64, error) {
+	if el.size < 0 || el.size > 8 {
+		return 0, errMalformed
+	}
+	if el.size == 0 {
+		return 0, nil
+	}
+	data, err := readAt(r, el.off, int(el.size), size)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, b := range data {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+func readEBMLFloat(r io.ReaderAt, el ebmlElement, size int64) (float64, error) {
+	if el.size == 0 {
+		return 0, nil
+	}
+	if el.size != 4 && el.size != 8 {
+		return 0, errMalformed
+	}
+	data, err := readAt(r, el.off, int(el.size), size)
+	if err != nil {
+		return 0, err
+	}
+	if int64(len(data)) != el.size {
+		return 0, errMalformed
+	}
+	if el.size == 4 {
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+}
+
+func readEBMLString(r io.ReaderAt, el ebmlElement, size int64) (string, error) {
+	if el.size < 0 || el.size > 256 {
+		return "", errMalformed
+	}
+	if el.size == 0 {
+		return "", nil
+	}
+	data, err := readAt(r, el.off, int(el.size), size)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\x00"), nil
+}
+
+func matroskaCodec(codecID string) string {
+	if c, ok := matroskaCodecs[codecID]; ok {
+		return c
+	}
+	id := codecID
+	if i := strings.IndexByte(id, '_'); i >= 0 {
+		id = id[i+1:]
+	}
+	return strings.ToLower(id)
+}
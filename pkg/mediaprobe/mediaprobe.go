@@ -0,0 +1,130 @@
+// Package mediaprobe reads duration, dimensions and codecs of uploaded audio / video files.
+// The native prober parses MP4/MOV/M4A, WebM/Matroska, MP3, WAV and Ogg headers in pure Go;
+// an ffprobe-backed prober can be selected with probe.backend.
+package mediaprobe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"media-service/pkg/config"
+)
+
+const (
+	BackendNative  = "native"
+	BackendFFProbe = "ffprobe"
+)
+
+var (
+	// ErrUnsupported is returned for files that are not one of the supported containers.
+	ErrUnsupported = errors.New("unsupported media format")
+	errMalformed   = errors.New("malformed media file")
+)
+
+// Info describes a media file. Zero values mean unknown.
+type Info struct {
+	Container  string `json:"container" bson:"container"` // mp4 | mov | webm | matroska | mp3 | wav | ogg
+	DurationMs int64  `json:"duration_ms" bson:"duration_ms"`
+	Width      int    `json:"width,omitempty" bson:"width,omitempty"`
+	Height     int    `json:"height,omitempty" bson:"height,omitempty"`
+	VideoCodec string `json:"video_codec,omitempty" bson:"video_codec,omitempty"`
+	AudioCodec string `json:"audio_codec,omitempty" bson:"audio_codec,omitempty"`
+	Bitrate    int64  `json:"bitrate,omitempty" bson:"bitrate,omitempty"` // bit/s trung bình của cả file
+}
+
+// Prober extracts Info from a file of the given size.
+type Prober interface {
+	Probe(ctx context.Context, r io.ReaderAt, size int64) (*Info, error)
+}
+
+// New returns the prober selected by probe.backend.
+func New() Prober {
+	cfg := config.AppConfig.Probe
+	switch strings.ToLower(strings.TrimSpace(cfg.Backend)) {
+	case "", BackendNative:
+		return NativeProber{}
+	case BackendFFProbe:
+		return NewFFProbe(cfg.FFProbePath, time.Duration(cfg.TimeoutSeconds)*time.Second)
+	default:
+		panic(fmt.Sprintf("invalid probe config: unknown backend %s", cfg.Backend))
+	}
+}
+
+// ProbeFile probes an uploaded multipart file.
+func ProbeFile(ctx context.Context, p Prober, file *multipart.FileHeader) (*Info, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return p.Probe(ctx, f, file.Size)
+}
+
+// NativeProber parses container headers without external tools.
+type NativeProber struct{}
+
+func (NativeProber) Probe(ctx context.Context, r io.ReaderAt, size int64) (*Info, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	head, err := readAt(r, 0, 64, size)
+	if err != nil || len(head) < 12 {
+		return nil, ErrUnsupported
+	}
+
+	var info *Info
+	switch {
+	case isMP4(head):
+		info, err = probeMP4(r, size)
+	case bytes.HasPrefix(head, ebmlMagic):
+		info, err = probeMatroska(r, size)
+	case string(head[:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		info, err = probeWAV(r, size)
+	case string(head[:4]) == "OggS":
+		info, err = probeOgg(r, size)
+	case string(head[:3]) == "ID3" || (head[0] == 0xFF && head[1]&0xE0 == 0xE0):
+		info, err = probeMP3(r, size)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Bitrate == 0 && info.DurationMs > 0 {
+		info.Bitrate = size * 8 * 1000 / info.DurationMs
+	}
+	return info, nil
+}
+
+// readAt đọc tối đa n byte tại off (bị cắt ở cuối file), lỗi nếu không đọc được byte nào
+func readAt(r io.ReaderAt, off int64, n int, size int64) ([]byte, error) {
+	if off < 0 || off >= size {
+		return nil, errMalformed
+	}
+	if remain := size - off; int64(n) > remain {
+		n = int(remain)
+	}
+	buf := make([]byte, n)
+	read, err := r.ReadAt(buf, off)
+	if read == n {
+		return buf, nil
+	}
+	if err == nil || err == io.EOF {
+		err = errMalformed
+	}
+	return nil, err
+}
+
+// ms đổi value / timescale (giây) sang mili giây, tránh tràn số với duration lớn
+func ms(value, timescale uint64) int64 {
+	if timescale == 0 {
+		return 0
+	}
+	return int64(value/timescale*1000 + value%timescale*1000/timescale)
+}
@@ -0,0 +1,240 @@
+package mediaprobe
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// ---------------- sample builders ----------------
+
+func box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	b := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(b, uint32(8+len(body)))
+	copy(b[4:], typ)
+	return append(b, body...)
+}
+
+// mvhd / mdhd version 0: version+flags, creation, modification, timescale, duration
+func mp4Duration(timescale, duration uint32) []byte {
+	b := make([]byte, 100)
+	binary.BigEndian.PutUint32(b[12:], timescale)
+	binary.BigEndian.PutUint32(b[16:], duration)
+	return b
+}
+
+func mp4Trak(handler, fourcc string, width, height uint32) []byte {
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], width<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], height<<16)
+	hdlr := append(make([]byte, 8), []byte(handler+"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")...)
+	stsd := make([]byte, 24)
+	binary.BigEndian.PutUint32(stsd[4:], 1)
+	binary.BigEndian.PutUint32(stsd[8:], 16)
+	copy(stsd[12:], fourcc)
+	return box("trak",
+		box("tkhd", tkhd),
+		box("mdia",
+			box("mdhd", mp4Duration(1000, 5500)),
+			box("hdlr", hdlr),
+			box("minf", box("stbl", box("stsd", stsd)))))
+}
+
+func sampleMP4() []byte {
+	return bytes.Join([][]byte{
+		box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2")),
+		box("moov",
+			box("mvhd", mp4Duration(1000, 5500)),
+			mp4Trak("vide", "avc1", 1280, 720),
+			mp4Trak("soun", "mp4a", 0, 0)),
+		box("mdat", make([]byte, 64)),
+	}, nil)
+}
+
+func ebml(id uint32, payload ...[]byte) []byte {
+	var b []byte
+	for v := id; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	body := bytes.Join(payload, nil)
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(body)))
+	size[0] = 0x01 // vint 8 byte
+	return append(append(b, size...), body...)
+}
+
+func ebmlUint(id uint32, v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return ebml(id, b)
+}
+
+func ebmlFloat(id uint32, v float64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, math.Float64bits(v))
+	return ebml(id, b)
+}
+
+func sampleWebM() []byte {
+	return bytes.Join([][]byte{
+		ebml(ebmlHeaderID, ebml(ebmlDocTypeID, []byte("webm"))),
+		ebml(mkvSegmentID,
+			ebml(mkvInfoID, ebmlUint(mkvTimecodeID, 1_000_000), ebmlFloat(mkvDurationID, 7250)),
+			ebml(mkvTracksID,
+				ebml(mkvTrackEntryID, ebmlUint(mkvTrackTypeID, 1), ebml(mkvCodecID, []byte("V_VP9")),
+					ebml(mkvVideoID, ebmlUint(mkvPixelWidth, 640), ebmlUint(mkvPixelHeight, 360))),
+				ebml(mkvTrackEntryID, ebmlUint(mkvTrackTypeID, 2), ebml(mkvCodecID, []byte("A_OPUS")))),
+			ebml(mkvClusterID, make([]byte, 32))),
+	}, nil)
+}
+
+// MPEG-1 Layer III, 128 kbit/s, 44.1 kHz: mỗi frame 417 byte
+var mp3Header = []byte{0xFF, 0xFB, 0x90, 0x00}
+
+func sampleMP3CBR() []byte {
+	frame := append(append([]byte{}, mp3Header...), make([]byte, 413)...)
+	return append([]byte("ID3\x04\x00\x00\x00\x00\x00\x00"), bytes.Repeat(frame, 10)...)
+}
+
+func sampleMP3Xing() []byte {
+	frame := append(append([]byte{}, mp3Header...), make([]byte, 32)...)
+	frame = append(frame, []byte("Xing\x00\x00\x00\x01\x00\x00\x03\xE8")...) // 1000 frame
+	return append(frame, make([]byte, 417-len(frame))...)
+}
+
+func sampleWAV(format uint16, fmtChunk []byte) []byte {
+	if fmtChunk == nil {
+		fmtChunk = make([]byte, 16)
+		binary.LittleEndian.PutUint16(fmtChunk[0:], format)
+		binary.LittleEndian.PutUint16(fmtChunk[2:], 1)
+		binary.LittleEndian.PutUint32(fmtChunk[4:], 8000)
+		binary.LittleEndian.PutUint32(fmtChunk[8:], 16000)
+		binary.LittleEndian.PutUint16(fmtChunk[12:], 2)
+		binary.LittleEndian.PutUint16(fmtChunk[14:], 16)
+	}
+	b := []byte("RIFF\x00\x00\x00\x00WAVEfmt ")
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(fmtChunk)))
+	b = append(b, fmtChunk...)
+	b = append(b, "data"...)
+	b = binary.LittleEndian.AppendUint32(b, 24000)
+	return append(b, make([]byte, 24000)...)
+}
+
+func oggPage(granule uint64, packet []byte) []byte {
+	b := []byte("OggS\x00\x02")
+	b = binary.LittleEndian.AppendUint64(b, granule)
+	b = binary.LittleEndian.AppendUint32(b, 7) // serial
+	b = append(b, make([]byte, 8)...)          // sequence + crc
+	b = append(b, 1, byte(len(packet)))
+	return append(b, packet...)
+}
+
+func sampleOpus() []byte {
+	head := []byte("OpusHead\x01\x01\x38\x01\x80\xBB\x00\x00\x00\x00\x00") // pre-skip 312
+	return append(oggPage(0, head), oggPage(144312, []byte{0})...)
+}
+
+func probe(data []byte) (*Info, error) {
+	return NativeProber{}.Probe(context.Background(), bytes.NewReader(data), int64(len(data)))
+}
+
+// ---------------- tests ----------------
+
+func TestNativeProbe(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want Info
+	}{
+		{"mp4", sampleMP4(), Info{Container: "mp4", DurationMs: 5500, Width: 1280, Height: 720, VideoCodec: "h264", AudioCodec: "aac"}},
+		{"webm", sampleWebM(), Info{Container: "webm", DurationMs: 7250, Width: 640, Height: 360, VideoCodec: "vp9", AudioCodec: "opus"}},
+		{"mp3 cbr", sampleMP3CBR(), Info{Container: "mp3", DurationMs: 260, AudioCodec: "mp3"}},
+		{"mp3 xing", sampleMP3Xing(), Info{Container: "mp3", DurationMs: 26122, AudioCodec: "mp3"}},
+		{"wav pcm", sampleWAV(1, nil), Info{Container: "wav", DurationMs: 1500, AudioCodec: "pcm"}},
+		{"ogg opus", sampleOpus(), Info{Container: "ogg", DurationMs: 3000, AudioCodec: "opus"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := probe(tt.data)
+			if err != nil {
+				t.Fatalf("Probe: %v", err)
+			}
+			got.Bitrate = 0
+			if *got != tt.want {
+				t.Errorf("Probe = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// mọi tiền tố của file hợp lệ phải trả về lỗi hoặc Info, không được panic
+func TestNativeProbeTruncated(t *testing.T) {
+	samples := map[string][]byte{
+		"mp4":      sampleMP4(),
+		"webm":     sampleWebM(),
+		"mp3 cbr":  sampleMP3CBR(),
+		"mp3 xing": sampleMP3Xing(),
+		"wav":      sampleWAV(1, nil)[:200],
+		"ogg":      sampleOpus(),
+	}
+	for name, data := range samples {
+		t.Run(name, func(t *testing.T) {
+			for n := 0; n < len(data); n++ {
+				info, err := probe(data[:n])
+				if err == nil && info == nil {
+					t.Fatalf("len %d: nil Info without error", n)
+				}
+			}
+		})
+	}
+}
+
+func TestNativeProbeHostile(t *testing.T) {
+	nested := box("stbl")
+	for i := 0; i < 10_000; i++ {
+		nested = box("mdia", nested)
+	}
+	extensible := make([]byte, 18)
+	binary.LittleEndian.PutUint16(extensible, 0xFFFE)
+	binary.LittleEndian.PutUint32(extensible[8:], 16000)
+	unknownSegment := append(ebml(ebmlHeaderID, ebml(ebmlDocTypeID, []byte("webm"))),
+		0x18, 0x53, 0x80, 0x67, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrUnsupported},
+		{"garbage", bytes.Repeat([]byte{0x42}, 512), ErrUnsupported},
+		{"wav fmt cut after 4 bytes", sampleWAV(1, nil)[:24], errMalformed},
+		{"wav extensible without subformat", sampleWAV(0, extensible), errMalformed},
+		{"wav fmt chunk too small", sampleWAV(0, make([]byte, 8)), errMalformed},
+		{"wav without data chunk", sampleWAV(1, nil)[:36], errMalformed},
+		{"mp4 deeply nested trak", append(box("ftyp", []byte("isom")), box("moov", box("trak", nested))...), errMalformed},
+		{"mp4 box smaller than header", append(box("ftyp", []byte("isom")), 0, 0, 0, 4, 'm', 'o', 'o', 'v'), errMalformed},
+		{"mp4 box past end of file", append(box("ftyp", []byte("isom")), 0x7F, 0, 0, 0, 'm', 'o', 'o', 'v'), errMalformed},
+		{"mp4 64-bit size overflow", append(box("ftyp", []byte("isom")), 0, 0, 0, 1, 'm', 'o', 'o', 'v', 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF), errMalformed},
+		{"mp4 oversized mvhd", box("moov", append([]byte{0x00, 0x20, 0x00, 0x10}, append([]byte("mvhd"), make([]byte, 64)...)...)), errMalformed},
+		{"mp4 without moov", box("ftyp", []byte("isom")), errMalformed},
+		{"ebml unknown-size segment without tracks", unknownSegment, errMalformed},
+		{"ebml invalid vint", append(append([]byte{}, ebmlMagic...), 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00), errMalformed},
+		{"ebml oversized codec id", append(ebml(ebmlHeaderID), ebml(mkvSegmentID, ebml(mkvTracksID, ebml(mkvTrackEntryID, ebml(mkvCodecID, make([]byte, 4096)))))...), errMalformed},
+		{"ogg segment table past end", []byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x07\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xFF"), errMalformed},
+		{"ogg unknown codec", oggPage(0, []byte("\x7FFLAC\x01\x00")), ErrUnsupported},
+		{"mp3 id3 tag past end", []byte("ID3\x04\x00\x00\x7F\x7F\x7F\x7F\xFF\xFB\x90\x00"), errMalformed},
+		{"mp3 without frame", append([]byte("ID3\x04\x00\x00\x00\x00\x00\x00"), make([]byte, 256)...), ErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := probe(tt.data)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Probe = %+v, %v; want error %v", info, err, tt.want)
+			}
+		})
+	}
+}
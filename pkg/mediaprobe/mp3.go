@@ -0,0 +1,136 @@
+package mediaprobe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// phạm vi tìm frame đầu tiên sau tag ID3v2 (một số encoder chèn byte rác / padding)
+const mp3SyncSearch = 64 << 10
+
+var (
+	mp3BitratesV1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	mp3Rates      = [4][3]int{
+		{11025, 12000, 8000},  // MPEG 2.5
+		{},                    // reserved
+		{22050, 24000, 16000}, // MPEG 2
+		{44100, 48000, 32000}, // MPEG 1
+	}
+)
+
+type mp3Frame struct {
+	mpeg1      bool
+	mono       bool
+	bitrate    int // kbit/s
+	sampleRate int
+}
+
+// samplesPerFrame của Layer III
+func (f mp3Frame) samplesPerFrame() int {
+	if f.mpeg1 {
+		return 1152
+	}
+	return 576
+}
+
+// probeMP3 lấy duration từ header Xing / Info / VBRI nếu có, không thì coi là CBR theo frame đầu tiên
+func probeMP3(r io.ReaderAt, size int64) (*Info, error) {
+	start := int64(0)
+	if id3, err := readAt(r, 0, 10, size); err == nil && len(id3) == 10 && string(id3[:3]) == "ID3" {
+		// kích thước tag là syncsafe integer (7 bit mỗi byte)
+		tagSize := int64(id3[6])<<21 | int64(id3[7])<<14 | int64(id3[8])<<7 | int64(id3[9])
+		start = 10 + tagSize
+		if id3[5]&0x10 != 0 {
+			start += 10 // footer
+		}
+	}
+
+	buf, err := readAt(r, start, mp3SyncSearch, size)
+	if err != nil {
+		return nil, errMalformed
+	}
+	var frame mp3Frame
+	pos := -1
+	for i := 0; i+4 <= len(buf); i++ {
+		if f, ok := parseMP3Header(buf[i:]); ok {
+			frame, pos = f, i
+			break
+		}
+	}
+	if pos < 0 {
+		return nil, ErrUnsupported
+	}
+	audioStart := start + int64(pos)
+
+	info := &Info{Container: "mp3", AudioCodec: "mp3"}
+	if frames := mp3VBRFrames(buf[pos:], frame); frames > 0 {
+		info.DurationMs = ms(frames*uint64(frame.samplesPerFrame()), uint64(frame.sampleRate))
+		return info, nil
+	}
+
+	audioSize := size - audioStart
+	if tail, err := readAt(r, size-128, 3, size); err == nil && string(tail) == "TAG" {
+		audioSize -= 128 // ID3v1
+	}
+	info.Bitrate = int64(frame.bitrate) * 1000
+	if audioSize > 0 {
+		info.DurationMs = audioSize * 8 / int64(frame.bitrate)
+	}
+	return info, nil
+}
+
+// parseMP3Header nhận header MPEG audio Layer III hợp lệ
+func parseMP3Header(b []byte) (mp3Frame, bool) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+	version := (b[1] >> 3) & 0x03
+	layer := (b[1] >> 1) & 0x03
+	bitrateIndex := b[2] >> 4
+	rateIndex := (b[2] >> 2) & 0x03
+	if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mp3Frame{}, false
+	}
+
+	f := mp3Frame{
+		mpeg1:      version == 3,
+		mono:       b[3]>>6 == 3,
+		sampleRate: mp3Rates[version][rateIndex],
+	}
+	if f.mpeg1 {
+		f.bitrate = mp3BitratesV1[bitrateIndex]
+	} else {
+		f.bitrate = mp3BitratesV2[bitrateIndex]
+	}
+	return f, true
+}
+
+// mp3VBRFrames đọc số frame từ header Xing / Info (sau side info) hoặc VBRI (offset 32), 0 nếu không có
+func mp3VBRFrames(b []byte, f mp3Frame) uint64 {
+	sideInfo := 32
+	switch {
+	case f.mpeg1 && f.mono:
+		sideInfo = 17
+	case !f.mpeg1 && f.mono:
+		sideInfo = 9
+	case !f.mpeg1:
+		sideInfo = 17
+	}
+
+	if x := 4 + sideInfo; len(b) >= x+12 {
+		tag := b[x : x+4]
+		if bytes.Equal(tag, []byte("Xing")) || bytes.Equal(tag, []byte("Info")) {
+			flags := binary.BigEndian.Uint32(b[x+4:])
+			if flags&0x01 != 0 {
+				return uint64(binary.BigEndian.Uint32(b[x+8:]))
+			}
+			return 0
+		}
+	}
+	if v := 4 + 32; len(b) >= v+18 && bytes.Equal(b[v:v+4], []byte("VBRI")) {
+		return uint64(binary.BigEndian.Uint32(b[v+14:]))
+	}
+	return 0
+}
@@ -0,0 +1,262 @@
+package mediaprobe
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	// box con lớn hơn mức này không được đọc vào bộ nhớ (mvhd, tkhd, hdlr, stsd chỉ vài trăm byte)
+	maxMP4BoxRead = 1 << 20
+	// trak chuẩn chỉ lồng mdia > minf > stbl
+	maxMP4TrakDepth = 3
+)
+
+var errStopWalk = errors.New("stop")
+
+var mp4Codecs = map[string]string{
+	"avc1": "h264",
+	"avc3": "h264",
+	"hvc1": "hevc",
+	"hev1": "hevc",
+	"vp08": "vp8",
+	"vp09": "vp9",
+	"av01": "av1",
+	"mp4v": "mpeg4",
+	"jpeg": "mjpeg",
+	"apcn": "prores",
+	"apch": "prores",
+	"mp4a": "aac",
+	"ac-3": "ac3",
+	"ec-3": "eac3",
+	"Opus": "opus",
+	"fLaC": "flac",
+	".mp3": "mp3",
+	"alac": "alac",
+	"sowt": "pcm",
+	"twos": "pcm",
+	"lpcm": "pcm",
+}
+
+type mp4Box struct {
+	typ  string
+	off  int64 // đầu payload
+	size int64 // độ dài payload
+}
+
+func isMP4(head []byte) bool {
+	switch string(head[4:8]) {
+	case "ftyp", "moov", "mdat", "wide", "free", "skip":
+		return true
+	}
+	return false
+}
+
+// probeMP4 đọc mvhd / tkhd / hdlr / stsd trong moov, bỏ qua mdat nên moov ở cuối file vẫn đọc được
+func probeMP4(r io.ReaderAt, size int64) (*Info, error) {
+	info := &Info{Container: "mp4"}
+	foundMoov := false
+	err := walkMP4(r, 0, size, size, func(b mp4Box) error {
+		switch b.typ {
+		case "ftyp":
+			data, err := readMP4Box(r, b, size)
+			if err != nil {
+				return err
+			}
+			if len(data) >= 4 {
+				switch string(data[:4]) {
+				case "qt  ":
+					info.Container = "mov"
+				case "M4A ", "M4B ":
+					info.Container = "m4a"
+				}
+			}
+		case "moov":
+			foundMoov = true
+			if err := probeMoov(r, b, size, info); err != nil {
+				return err
+			}
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil && err != errStopWalk {
+		return nil, err
+	}
+	if !foundMoov {
+		return nil, errMalformed
+	}
+	return info, nil
+}
+
+func probeMoov(r io.ReaderAt, moov mp4Box, size int64, info *Info) error {
+	var trackDurationMs int64
+	err := walkMP4(r, moov.off, moov.off+moov.size, size, func(b mp4Box) error {
+		switch b.typ {
+		case "mvhd":
+			data, err := readMP4Box(r, b, size)
+			if err != nil {
+				return err
+			}
+			timescale, duration := fullBoxDuration(data)
+			info.DurationMs = ms(duration, timescale)
+		case "trak":
+			d, err := probeTrak(r, b, size, info)
+			if err != nil {
+				return err
+			}
+			trackDurationMs = max(trackDurationMs, d)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// mp4 fragmented có thể để mvhd duration = 0
+	if info.DurationMs == 0 {
+		info.DurationMs = trackDurationMs
+	}
+	return nil
+}
+
+// probeTrak trả về duration của track (mdhd), codec và kích thước được ghi vào info cho track đầu tiên mỗi loại
+func probeTrak(r io.ReaderAt, trak mp4Box, size int64, info *Info) (int64, error) {
+	var width, height int
+	var handler, codec string
+	var durationMs int64
+	depth := 0
+
+	var visit func(b mp4Box) error
+	visit = func(b mp4Box) error {
+		switch b.typ {
+		case "mdia", "minf", "stbl":
+			// file cố tình lồng container vô hạn sẽ làm tràn stack nếu không giới hạn
+			if depth >= maxMP4TrakDepth {
+				return errMalformed
+			}
+			depth++
+			err := walkMP4(r, b.off, b.off+b.size, size, visit)
+			depth--
+			return err
+		case "tkhd":
+			data, err := readMP4Box(r, b, size)
+			if err != nil {
+				return err
+			}
+			if len(data) >= 84 {
+				// width / height là 16.16 fixed point ở 8 byte cuối
+				width = int(binary.BigEndian.Uint32(data[len(data)-8:]) >> 16)
+				height = int(binary.BigEndian.Uint32(data[len(data)-4:]) >> 16)
+			}
+		case "mdhd":
+			data, err := readMP4Box(r, b, size)
+			if err != nil {
+				return err
+			}
+			timescale, duration := fullBoxDuration(data)
+			durationMs = ms(duration, timescale)
+		case "hdlr":
+			data, err := readMP4Box(r, b, size)
+			if err != nil {
+				return err
+			}
+			if len(data) >= 12 {
+				handler = string(data[8:12])
+			}
+		case "stsd":
+			data, err := readMP4Box(r, b, size)
+			if err != nil {
+				return err
+			}
+			// version/flags, entry_count, rồi sample entry đầu tiên: size + format
+			if len(data) >= 16 {
+				codec = string(data[12:16])
+			}
+		}
+		return nil
+	}
+	if err := walkMP4(r, trak.off, trak.off+trak.size, size, visit); err != nil {
+		return 0, err
+	}
+
+	switch handler {
+	case "vide":
+		if info.VideoCodec == "" {
+			info.VideoCodec = mp4Codec(codec)
+			info.Width, info.Height = width, height
+		}
+	case "soun":
+		if info.AudioCodec == "" {
+			info.AudioCodec = mp4Codec(codec)
+		}
+	}
+	return durationMs, nil
+}
+
+// walkMP4 gọi fn với từng box nằm trong [start, end)
+func walkMP4(r io.ReaderAt, start, end, size int64, fn func(b mp4Box) error) error {
+	for off := start; off+8 <= end; {
+		hdr, err := readAt(r, off, 16, size)
+		if err != nil || len(hdr) < 8 {
+			return errMalformed
+		}
+		boxSize := int64(binary.BigEndian.Uint32(hdr[:4]))
+		headerLen := int64(8)
+		switch boxSize {
+		case 0:
+			// box kéo dài tới hết file / box cha
+			boxSize = end - off
+		case 1:
+			if len(hdr) < 16 {
+				return errMalformed
+			}
+			boxSize = int64(binary.BigEndian.Uint64(hdr[8:16]))
+			headerLen = 16
+		}
+		if boxSize < headerLen || off+boxSize > end || off+boxSize < off {
+			return errMalformed
+		}
+		if err := fn(mp4Box{typ: string(hdr[4:8]), off: off + headerLen, size: boxSize - headerLen}); err != nil {
+			return err
+		}
+		off += boxSize
+	}
+	return nil
+}
+
+func readMP4Box(r io.ReaderAt, b mp4Box, size int64) ([]byte, error) {
+	if b.size > maxMP4BoxRead {
+		return nil, errMalformed
+	}
+	if b.size == 0 {
+		return nil, nil
+	}
+	return readAt(r, b.off, int(b.size), size)
+}
+
+// fullBoxDuration đọc timescale + duration của mvhd / mdhd (version 0: 32 bit, version 1: 64 bit),
+// duration toàn bit 1 nghĩa là không xác định
+func fullBoxDuration(data []byte) (timescale, duration uint64) {
+	switch {
+	case len(data) >= 32 && data[0] == 1:
+		timescale, duration = uint64(binary.BigEndian.Uint32(data[20:24])), binary.BigEndian.Uint64(data[24:32])
+		if duration == 0xFFFFFFFFFFFFFFFF {
+			duration = 0
+		}
+	case len(data) >= 20:
+		timescale, duration = uint64(binary.BigEndian.Uint32(data[12:16])), uint64(binary.BigEndian.Uint32(data[16:20]))
+		if duration == 0xFFFFFFFF {
+			duration = 0
+		}
+	}
+	return timescale, duration
+}
+
+func mp4Codec(fourcc string) string {
+	if c, ok := mp4Codecs[fourcc]; ok {
+		return c
+	}
+	return strings.TrimSpace(fourcc)
+}
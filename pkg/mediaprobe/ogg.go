@@ -0,0 +1,81 @@
+package mediaprobe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// page cuối (chứa granule position) nằm trong phần đuôi này của file
+const oggTailSearch = 64 << 10
+
+var oggCapture = []byte("OggS")
+
+// probeOgg nhận stream Vorbis / Opus: codec từ packet đầu tiên, duration từ granule position của page cuối
+func probeOgg(r io.ReaderAt, size int64) (*Info, error) {
+	first, err := readAt(r, 0, 27+255, size)
+	if err != nil || len(first) < 27 {
+		return nil, errMalformed
+	}
+	serial := binary.LittleEndian.Uint32(first[14:18])
+	segments := int(first[26])
+	if len(first) < 27+segments {
+		return nil, errMalformed
+	}
+	packetLen := 0
+	for _, l := range first[27 : 27+segments] {
+		packetLen += int(l)
+	}
+	packet, err := readAt(r, int64(27+segments), min(packetLen, 64), size)
+	if err != nil {
+		return nil, errMalformed
+	}
+
+	info := &Info{Container: "ogg"}
+	var rate, preSkip uint64
+	switch {
+	case len(packet) >= 16 && bytes.HasPrefix(packet, []byte("\x01vorbis")):
+		info.AudioCodec = "vorbis"
+		rate = uint64(binary.LittleEndian.Uint32(packet[12:16]))
+		if len(packet) >= 24 {
+			if nominal := int32(binary.LittleEndian.Uint32(packet[20:24])); nominal > 0 {
+				info.Bitrate = int64(nominal)
+			}
+		}
+	case len(packet) >= 12 && bytes.HasPrefix(packet, []byte("OpusHead")):
+		// granule của opus luôn tính ở 48 kHz, bỏ pre-skip
+		info.AudioCodec = "opus"
+		rate = 48000
+		preSkip = uint64(binary.LittleEndian.Uint16(packet[10:12]))
+	default:
+		return nil, ErrUnsupported
+	}
+
+	granule, ok := lastOggGranule(r, size, serial)
+	if ok && rate > 0 && granule > preSkip {
+		info.DurationMs = ms(granule-preSkip, rate)
+	}
+	return info, nil
+}
+
+// lastOggGranule tìm page cuối cùng của stream serial có granule position hợp lệ
+func lastOggGranule(r io.ReaderAt, size int64, serial uint32) (uint64, bool) {
+	start := max(0, size-oggTailSearch)
+	tail, err := readAt(r, start, int(size-start), size)
+	if err != nil {
+		return 0, false
+	}
+	for i := bytes.LastIndex(tail, oggCapture); i >= 0; i = bytes.LastIndex(tail[:i], oggCapture) {
+		if i+27 > len(tail) || tail[i+4] != 0 {
+			continue
+		}
+		if binary.LittleEndian.Uint32(tail[i+14:i+18]) != serial {
+			continue
+		}
+		// -1 = page không kết thúc packet nào
+		if granule := binary.LittleEndian.Uint64(tail[i+6 : i+14]); granule != 0xFFFFFFFFFFFFFFFF {
+			return granule, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,76 @@
+package mediaprobe
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+var wavCodecs = map[uint16]string{
+	0x0001: "pcm",
+	0x0003: "pcm_float",
+	0x0006: "alaw",
+	0x0007: "mulaw",
+	0x0011: "adpcm_ima",
+	0x0055: "mp3",
+}
+
+// probeWAV đọc chunk fmt và kích thước chunk data: duration = data / byte rate
+func probeWAV(r io.ReaderAt, size int64) (*Info, error) {
+	info := &Info{Container: "wav"}
+	var byteRate uint32
+	var dataSize int64 = -1
+
+	for off := int64(12); off+8 <= size; {
+		hdr, err := readAt(r, off, 8, size)
+		if err != nil {
+			return nil, err
+		}
+		id := string(hdr[:4])
+		chunkSize := int64(binary.LittleEndian.Uint32(hdr[4:]))
+		body := off + 8
+
+		switch id {
+		case "fmt ":
+			if chunkSize < 16 {
+				return nil, errMalformed
+			}
+			data, err := readAt(r, body, int(min(chunkSize, 40)), size)
+			if err != nil {
+				return nil, err
+			}
+			// readAt bị cắt ở cuối file: chunk khai báo đủ 16 byte nhưng file có thể ngắn hơn
+			if len(data) < 16 {
+				return nil, errMalformed
+			}
+			format := binary.LittleEndian.Uint16(data[0:2])
+			if format == 0xFFFE {
+				// WAVE_FORMAT_EXTENSIBLE: format thật nằm ở đầu GUID SubFormat
+				if len(data) < 26 {
+					return nil, errMalformed
+				}
+				format = binary.LittleEndian.Uint16(data[24:26])
+			}
+			byteRate = binary.LittleEndian.Uint32(data[8:12])
+			if c, ok := wavCodecs[format]; ok {
+				info.AudioCodec = c
+			} else {
+				info.AudioCodec = fmt.Sprintf("wav_0x%04x", format)
+			}
+		case "data":
+			// file ghi dở / stream có thể để size = 0xFFFFFFFF hoặc lớn hơn phần còn lại
+			dataSize = min(chunkSize, size-body)
+		}
+		if byteRate > 0 && dataSize >= 0 {
+			break
+		}
+		off = body + chunkSize + chunkSize%2
+	}
+
+	if byteRate == 0 || dataSize < 0 {
+		return nil, errMalformed
+	}
+	info.Bitrate = int64(byteRate) * 8
+	info.DurationMs = ms(uint64(dataSize), uint64(byteRate))
+	return info, nil
+}
@@ -0,0 +1,143 @@
+package objectkey
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"media-service/pkg/config"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PolicyScoped = "scoped"
+	PolicyLegacy = "legacy"
+)
+
+// Entity is the kind of document an object belongs to, used as a key segment by the scoped policy.
+type Entity string
+
+const (
+	EntityTopic         Entity = "topics"
+	EntityVocabulary    Entity = "vocabularies"
+	EntityTopicResource Entity = "topic_resources"
+	EntityDocument      Entity = "documents"
+	EntityVideo         Entity = "videos"
+	EntityUpload        Entity = "uploads" // upload session chưa gắn vào document nào
+)
+
+// Object describes the file a key is built for. Policies ignore the fields they don't use.
+type Object struct {
+	OrganizationID string
+	Entity         Entity
+	EntityID       string
+	LanguageID     uint   // 0 = file không theo ngôn ngữ
+	Slot           string // vai trò của file trong document: audio, video, image_full_background, pdf...
+	FileName       string // tên file gốc, chỉ lấy phần mở rộng
+	Title          string // tên hiển thị, chỉ policy legacy dùng
+	Folder         string // thư mục gốc của key ở cả hai policy, ví dụ "topic_media/audio"
+}
+
+// Policy builds the object key for a new file. Keys of existing objects are never rebuilt.
+type Policy interface {
+	Key(obj Object) string
+}
+
+// PolicyFunc adapts a function to Policy.
+type PolicyFunc func(obj Object) string
+
+func (f PolicyFunc) Key(obj Object) string { return f(obj) }
+
+// ErrUnknownPolicy is returned when storage.key_policy names a policy that was never registered.
+var ErrUnknownPolicy = errors.New("unknown key policy")
+
+var (
+	mu       sync.RWMutex
+	policies = map[string]Policy{
+		PolicyScoped: PolicyFunc(scopedKey),
+		PolicyLegacy: PolicyFunc(legacyKey),
+	}
+	current Policy
+)
+
+// Register makes a policy selectable through storage.key_policy. Call it before Init.
+func Register(name string, p Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	policies[name] = p
+}
+
+// Init selects the policy configured in storage.key_policy. Binaries call it at startup
+// so a misconfigured policy fails the boot instead of the first upload.
+func Init() error {
+	_, err := active()
+	return err
+}
+
+// Build returns the key of obj under the configured policy.
+func Build(obj Object) (string, error) {
+	p, err := active()
+	if err != nil {
+		return "", err
+	}
+	return p.Key(obj), nil
+}
+
+func active() (Policy, error) {
+	mu.RLock()
+	p := current
+	mu.RUnlock()
+	if p != nil {
+		return p, nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		name := strings.ToLower(strings.TrimSpace(config.AppConfig.Storage.KeyPolicy))
+		if name == "" {
+			name = PolicyLegacy
+		}
+		selected, ok := policies[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid storage config: %w %q", ErrUnknownPolicy, name)
+		}
+		current = selected
+	}
+	return current, nil
+}
+
+// scopedKey: <folder>/org/<orgID>/<entity>/<entityID>/<lang>/<slot>/<uuid>.<ext>, segment rỗng được bỏ qua.
+// folder đứng đầu như key legacy để rule theo prefix (encryption.prefixes, tiering.rules, gc) khớp cả hai policy
+func scopedKey(obj Object) string {
+	org := segment(obj.OrganizationID)
+	if org == "" {
+		// video uploader của super admin không thuộc organization nào
+		org = "global"
+	}
+	parts := []string{strings.Trim(obj.Folder, "/"), "org", org, segment(string(obj.Entity)), segment(obj.EntityID)}
+	if obj.LanguageID != 0 {
+		parts = append(parts, strconv.FormatUint(uint64(obj.LanguageID), 10))
+	}
+	parts = append(parts, segment(obj.Slot), uuid.NewString()+Ext(obj.FileName))
+	return path.Join(parts...)
+}
+
+// legacyKey giữ định dạng cũ <folder>/<nanos>_<title>.<ext>, title đã được làm sạch
+func legacyKey(obj Object) string {
+	ext := Ext(obj.FileName)
+	name := Sanitize(obj.Title)
+	if name == "" {
+		name = Sanitize(strings.TrimSuffix(filepath.Base(obj.FileName), filepath.Ext(obj.FileName)))
+	}
+	if name == "" {
+		name = "file"
+	}
+	return fmt.Sprintf("%s/%d_%s%s", strings.Trim(obj.Folder, "/"), time.Now().UnixNano(), name, ext)
+}
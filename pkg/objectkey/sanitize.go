@@ -0,0 +1,74 @@
+package objectkey
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxNameLength = 80
+
+// stripMarks tách dấu (NFD) rồi bỏ các dấu kết hợp: "Bài học" -> "Bai hoc"
+var stripMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// Sanitize turns a title into a lowercase ASCII slug safe for object keys,
+// e.g. "Đồ chơi / Toys #1" -> "do-choi-toys-1". It may return "".
+func Sanitize(s string) string {
+	// đ / Đ không phải chữ có dấu kết hợp nên NFD không tách được
+	s = strings.NewReplacer("đ", "d", "Đ", "D").Replace(s)
+	if out, _, err := transform.String(stripMarks, s); err == nil {
+		s = out
+	}
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	out := strings.TrimRight(b.String(), "-")
+	if len(out) > maxNameLength {
+		out = strings.TrimRight(out[:maxNameLength], "-")
+	}
+	return out
+}
+
+// Ext returns the lowercase extension of a file name (with the dot), or "" when it isn't a plain extension.
+func Ext(fileName string) string {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if len(ext) < 2 || len(ext) > 10 {
+		return ""
+	}
+	for _, r := range ext[1:] {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return ""
+		}
+	}
+	return ext
+}
+
+// segment giữ id / slot làm một segment của key: không có "/" hay "..", chỉ ký tự an toàn
+func segment(s string) string {
+	s = strings.TrimSpace(s)
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
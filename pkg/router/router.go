@@ -1,7 +1,15 @@
 package router
 
 import (
+	"context"
+	"media-service/helper"
+	consistencyHandler "media-service/internal/consistency/handler"
+	consistencyRepo "media-service/internal/consistency/repository"
+	consistencyRoute "media-service/internal/consistency/route"
+	consistencyService "media-service/internal/consistency/service"
 	"media-service/internal/gateway"
+	localstorageHandler "media-service/internal/localstorage/handler"
+	localstorageRoute "media-service/internal/localstorage/route"
 	"media-service/internal/media/route"
 	"media-service/internal/media/v2/handler"
 	"media-service/internal/media/v2/repository"
@@ -12,10 +20,39 @@ import (
 	mediaassetRoute "media-service/internal/mediaasset/route"
 	mediaassetService "media-service/internal/mediaasset/service"
 	"media-service/internal/middleware"
+	outboxRepo "media-service/internal/outbox/repository"
+	outboxService "media-service/internal/outbox/service"
 	"media-service/internal/pdf/domain"
 	route2 "media-service/internal/pdf/route"
+	quotaHandler "media-service/internal/quota/handler"
+	quotaModel "media-service/internal/quota/model"
+	quotaRepo "media-service/internal/quota/repository"
+	quotaRoute "media-service/internal/quota/route"
+	quotaService "media-service/internal/quota/service"
 	"media-service/internal/redis"
 	s3svc "media-service/internal/s3"
+	storagegcHandler "media-service/internal/storagegc/handler"
+	storagegcRepo "media-service/internal/storagegc/repository"
+	storagegcRoute "media-service/internal/storagegc/route"
+	storagegcService "media-service/internal/storagegc/service"
+	tieringHandler "media-service/internal/tiering/handler"
+	tieringRepo "media-service/internal/tiering/repository"
+	tieringRoute "media-service/internal/tiering/route"
+	tieringService "media-service/internal/tiering/service"
+	trashHandler "media-service/internal/trash/handler"
+	trashModel "media-service/internal/trash/model"
+	trashRepo "media-service/internal/trash/repository"
+	trashRoute "media-service/internal/trash/route"
+	trashService "media-service/internal/trash/service"
+	uploadsessionHandler "media-service/internal/uploadsession/handler"
+	uploadsessionRepo "media-service/internal/uploadsession/repository"
+	uploadsessionRoute "media-service/internal/uploadsession/route"
+	uploadsessionService "media-service/internal/uploadsession/service"
+	"media-service/logger"
+	"media-service/pkg/cliprange"
+	"media-service/pkg/config"
+	"media-service/pkg/mediaprobe"
+	"net/http"
 
 	"github.com/gofiber/fiber/v2"
 	fiberLogger "github.com/gofiber/fiber/v2/middleware/logger"
@@ -25,18 +62,86 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func SetupRouter(app *fiber.App, consulClient *api.Client, cacheClientRedis *cache.RedisCache, topicCollection, pdfCollection, topicResourceCollection, videoUploaderCollection, mediaAssetCollection, vocabularyCollection *mongo.Collection) *fiber.App {
+func SetupRouter(app *fiber.App, consulClient *api.Client, cacheClientRedis *cache.RedisCache, topicCollection, pdfCollection, topicResourceCollection, videoUploaderCollection, mediaAssetCollection, vocabularyCollection, uploadSessionCollection, deletionOutboxCollection, storageUsageCollection *mongo.Collection) *fiber.App {
 
 	app.Use(fiberLogger.New())
 	// Apply CORS for all routes
 	app.Use(middleware.CORS())
 
+	// phần phụ thuộc domain của storage và response helper được truyền vào từ đây,
+	// phải chạy trước lần đầu s3svc.NewFromConfig
+	s3svc.Configure(s3svc.Options{
+		MirrorPrefixes: []string{trashModel.Prefix},
+		Usage:          quotaRepo.NewUsageRepository(storageUsageCollection),
+		Categories:     quotaModel.CategoryNames(),
+	})
+	helper.RegisterError(quotaModel.ErrQuotaExceeded, http.StatusRequestEntityTooLarge, helper.ErrQuotaExceeded)
+	helper.RegisterError(cliprange.ErrInvalid, http.StatusBadRequest, helper.ErrInvalidRequest)
+
 	// gateway
 	cachedMainGateway := cached.NewCachedMainGateway(cacheClientRedis)
 	userGateway := gateway.NewUserGateway("go-main-service", consulClient, cachedMainGateway)
 	fileGateway := gateway.NewFileGateway("go-main-service", consulClient)
 	redisService := redis.NewRedisService()
 
+	// ========================  Deletion Outbox ======================== //
+	deletionOutboxRepo := outboxRepo.NewDeletionRepository(deletionOutboxCollection)
+	deletionOutbox := outboxService.NewDeletionService(deletionOutboxRepo, s3svc.NewFromConfig())
+	deletionOutbox.Start(context.Background())
+	// ========================  Deletion Outbox ======================== //
+
+	// ========================  Trash (soft delete) ======================== //
+	trashRepository := trashRepo.NewTrashRepository(topicResourceCollection, pdfCollection, videoUploaderCollection, mediaAssetCollection)
+	trashSvc := trashService.NewTrashService(trashRepository, s3svc.NewFromConfig(), deletionOutbox)
+	trashSvc.Start(context.Background())
+	trashHandlerv2 := trashHandler.NewTrashHandler(trashSvc)
+	// ========================  Trash (soft delete) ======================== //
+
+	// ========================  Storage Quota ======================== //
+	quotaRepository := quotaRepo.NewUsageRepository(storageUsageCollection)
+	if err := quotaRepository.EnsureIndexes(context.Background()); err != nil {
+		logger.WriteLogEx("error", "failed to create storage usage indexes", err)
+	}
+	quotaObjectRepository := quotaRepo.NewObjectRepository(quotaRepo.ObjectCollections{
+		Topics:         topicCollection,
+		Vocabularies:   vocabularyCollection,
+		TopicResources: topicResourceCollection,
+		PDFResources:   pdfCollection,
+		VideoUploaders: videoUploaderCollection,
+		MediaAssets:    mediaAssetCollection,
+	})
+	quotaSvc := quotaService.NewQuotaService(quotaRepository, quotaObjectRepository, s3svc.NewFromConfig())
+	quotaHandlerv2 := quotaHandler.NewQuotaHandler(quotaSvc)
+	// ========================  Storage Quota ======================== //
+
+	// ========================  Storage Tiering ======================== //
+	tieringSvc := tieringService.NewTieringService(tieringRepo.NewTierRepository(topicResourceCollection), s3svc.NewFromConfig())
+	tieringHandlerv2 := tieringHandler.NewTieringHandler(tieringSvc)
+	if config.AppConfig.Tiering.Enabled {
+		tieringSvc.Start(context.Background())
+	}
+	// ========================  Storage Tiering ======================== //
+
+	// ========================  Media Assets (direct S3) ======================== //
+	mediaRepo := mediaassetRepo.NewMediaRepository(mediaAssetCollection)
+	if err := mediaRepo.EnsureIndexes(context.Background()); err != nil {
+		logger.WriteLogEx("error", "failed to create media asset indexes", err)
+	}
+	mediaSvc := mediaassetService.NewMediaService(mediaRepo, deletionOutbox, trashSvc, quotaSvc)
+	mediaHandler := mediaassetHandler.NewMediaHandler(mediaSvc)
+	// ========================  Media Assets (direct S3) ======================== //
+
+	// ========================  Upload Session (resumable / presigned) ======================== //
+	uploadSessionRepository := uploadsessionRepo.NewUploadSessionRepository(uploadSessionCollection)
+	uploadSessionSvc := uploadsessionService.NewUploadSessionService(uploadSessionRepository, s3svc.NewFromConfig(), mediaSvc, quotaSvc)
+	uploadSessionSvc.Start(context.Background())
+	uploadSessionHandler := uploadsessionHandler.NewUploadSessionHandler(uploadSessionSvc)
+	// ========================  Upload Session (resumable / presigned) ======================== //
+
+	// ========================  Media probing ======================== //
+	prober := mediaprobe.New()
+	// ========================  Media probing ======================== //
+
 	// ========================  Topic ======================== //
 	// --- Repo ---
 	topicRepov2 := repository.NewTopicRepository(topicCollection)
@@ -44,14 +149,14 @@ func SetupRouter(app *fiber.App, consulClient *api.Client, cacheClientRedis *cac
 	vocabularyRepo := repository.NewVocabularyRepository(vocabularyCollection)
 
 	// --- UseCase ---
-	uploadTopicUseCasev2 := usecase.NewUploadTopicUseCase(topicRepov2, s3svc.NewFromConfig())
+	uploadTopicUseCasev2 := usecase.NewUploadTopicUseCase(topicRepov2, s3svc.NewFromConfig(), uploadSessionSvc, quotaSvc, prober, deletionOutbox)
 	getTopicWebUseCasev2 := usecase.NewGetTopicWebUseCase(topicRepov2, topicResourceRepov2, s3svc.NewFromConfig())
 	getTopicGatewayUseCasev2 := usecase.NewGetTopicGatewayUseCase(topicRepov2, userGateway, s3svc.NewFromConfig())
 	getUploadProgressUseCasev2 := usecase.NewGetUploadProgressUseCase(topicRepov2, redisService)
-	deleteTopicFileUseCasev2 := usecase.NewDeleteTopicFileUseCase(topicRepov2, s3svc.NewFromConfig())
-	getTopicResourcesWebUseCasev2 := usecase.NewGetTopicResourcesWebUseCase(topicResourceRepov2, topicRepov2, s3svc.NewFromConfig())
+	deleteTopicFileUseCasev2 := usecase.NewDeleteTopicFileUseCase(topicRepov2, deletionOutbox)
+	getTopicResourcesWebUseCasev2 := usecase.NewGetTopicResourcesWebUseCase(topicResourceRepov2, topicRepov2, s3svc.NewFromConfig(), tieringSvc)
 	getTopicResourceAppUseCasev2 := usecase.NewGetTopicResourceAppUseCase(topicRepov2, topicResourceRepov2, s3svc.NewFromConfig())
-	uploadVocabularyUseCase := usecase.NewUploadVocabularyUseCase(topicRepov2, vocabularyRepo, s3svc.NewFromConfig())
+	uploadVocabularyUseCase := usecase.NewUploadVocabularyUseCase(topicRepov2, vocabularyRepo, s3svc.NewFromConfig(), uploadSessionSvc, quotaSvc, prober, deletionOutbox)
 	getVocabularyWebUseCase := usecase.NewGetVocabularyWebUseCase(vocabularyRepo, s3svc.NewFromConfig())
 	vocabularyUseCase := usecase.NewVocabularyUseCase(vocabularyRepo, s3svc.NewFromConfig())
 	getTopicAppUseCasev2 := usecase.NewGetTopicAppUseCase(topicRepov2, s3svc.NewFromConfig(), vocabularyUseCase)
@@ -69,16 +174,16 @@ func SetupRouter(app *fiber.App, consulClient *api.Client, cacheClientRedis *cac
 
 	// ========================  PDF ======================== //
 	pdfRepov2 := domain.NewUserResourceRepository(pdfCollection)
-	pdfServicev2 := domain.NewUserResourceService(pdfRepov2, s3svc.NewFromConfig(), userGateway)
+	pdfServicev2 := domain.NewUserResourceService(pdfRepov2, s3svc.NewFromConfig(), userGateway, trashSvc, quotaSvc, deletionOutbox)
 	pdfHandlerv2 := domain.NewUserResourceHandler(pdfServicev2)
 	// ========================  PDF ======================== //
 
-	topicResourceServicev2 := service.NewTopicResourceService(topicResourceRepov2, topicRepov2, s3svc.NewFromConfig(), userGateway, getTopicResourcesWebUseCasev2, getTopicResourceAppUseCasev2)
+	topicResourceServicev2 := service.NewTopicResourceService(topicResourceRepov2, topicRepov2, s3svc.NewFromConfig(), userGateway, getTopicResourcesWebUseCasev2, getTopicResourceAppUseCasev2, trashSvc, quotaSvc, deletionOutbox)
 	topicResourceHandlerv2 := handler.NewTopicResourceHandler(topicResourceServicev2)
 
 	// ========================  Video Uploader ======================== //
 	videoUploaderRepo := repository.NewVideoUploaderRepository(videoUploaderCollection)
-	videoUploaderService := service.NewVideoUploaderService(videoUploaderRepo, s3svc.NewFromConfig(), userGateway)
+	videoUploaderService := service.NewVideoUploaderService(videoUploaderRepo, s3svc.NewFromConfig(), userGateway, uploadSessionSvc, trashSvc, quotaSvc, prober, deletionOutbox)
 	videoUploaderHandler := handler.NewVideoUploaderHandler(videoUploaderService)
 	// ========================  Video Uploader ======================== //
 
@@ -87,11 +192,40 @@ func SetupRouter(app *fiber.App, consulClient *api.Client, cacheClientRedis *cac
 	route.RegisterTopicResourceRoutes(app, topicResourceHandlerv2, userGateway)
 	route.RegisterVideoUploaderRoutes(app, videoUploaderHandler, userGateway)
 	route2.RegisterRoutes(app, pdfHandlerv2, userGateway)
+	uploadsessionRoute.RegisterUploadSessionRoutes(app, uploadSessionHandler, userGateway)
 
-	// ========================  Media Assets (direct S3) ======================== //
-	mediaRepo := mediaassetRepo.NewMediaRepository(mediaAssetCollection)
-	mediaSvc := mediaassetService.NewMediaService(mediaRepo)
-	mediaHandler := mediaassetHandler.NewMediaHandler(mediaSvc)
-	mediaassetRoute.RegisterMediaRoutes(app, mediaHandler)
+	mediaassetRoute.RegisterMediaRoutes(app, mediaHandler, userGateway)
+	trashRoute.RegisterTrashRoutes(app, trashHandlerv2, userGateway)
+	quotaRoute.RegisterQuotaRoutes(app, quotaHandlerv2, userGateway)
+	tieringRoute.RegisterTieringRoutes(app, tieringHandlerv2, userGateway)
+
+	// ========================  Storage GC (orphaned objects) ======================== //
+	gcReferenceRepo := storagegcRepo.NewReferenceRepository(mediaAssetCollection, uploadSessionCollection,
+		topicCollection, vocabularyCollection, topicResourceCollection, videoUploaderCollection, pdfCollection)
+	gcService := storagegcService.NewGCService(gcReferenceRepo, s3svc.NewFromConfig())
+	gcHandler := storagegcHandler.NewGCHandler(gcService)
+	storagegcRoute.RegisterGCRoutes(app, gcHandler, userGateway)
+	if config.AppConfig.GC.Enabled {
+		gcService.Start(context.Background())
+	}
+
+	// ========================  Storage consistency (missing objects) ======================== //
+	consistencyReferenceRepo := consistencyRepo.NewReferenceRepository(topicCollection,
+		vocabularyCollection, topicResourceCollection, videoUploaderCollection, pdfCollection)
+	consistencySvc := consistencyService.NewConsistencyService(consistencyReferenceRepo, s3svc.NewFromConfig())
+	consistencyHandlerv2 := consistencyHandler.NewConsistencyHandler(consistencySvc)
+	consistencyRoute.RegisterConsistencyRoutes(app, consistencyHandlerv2, userGateway)
+	if config.AppConfig.Consistency.Enabled {
+		consistencySvc.Start(context.Background())
+	}
+
+	// ========================  Local Storage (dev / tests) ======================== //
+	if config.AppConfig.Storage.Provider == s3svc.ProviderLocal {
+		// dùng chung provider của storage service, không dựng instance thứ hai
+		if localProvider, ok := s3svc.NewFromConfig().Provider().(localstorageHandler.LocalFileStore); ok {
+			localFileHandler := localstorageHandler.NewLocalFileHandler(localProvider)
+			localstorageRoute.RegisterLocalFileRoutes(app, localFileHandler)
+		}
+	}
 	return app
 }
@@ -0,0 +1,136 @@
+package uploader
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type ChecksumAlgorithm string
+
+const (
+	ChecksumMD5    ChecksumAlgorithm = "md5"
+	ChecksumSHA256 ChecksumAlgorithm = "sha256"
+	ChecksumCRC32C ChecksumAlgorithm = "crc32c"
+)
+
+var (
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrInvalidChecksum  = errors.New("invalid checksum")
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+func (a ChecksumAlgorithm) IsValid() bool {
+	switch a {
+	case ChecksumMD5, ChecksumSHA256, ChecksumCRC32C:
+		return true
+	}
+	return false
+}
+
+// HeaderName là header S3 dùng để gửi checksum theo thuật toán này
+func (a ChecksumAlgorithm) HeaderName() string {
+	switch a {
+	case ChecksumMD5:
+		return "Content-Md5"
+	case ChecksumCRC32C:
+		return "X-Amz-Checksum-Crc32c"
+	default:
+		return "X-Amz-Checksum-Sha256"
+	}
+}
+
+func (a ChecksumAlgorithm) newHash() hash.Hash {
+	switch a {
+	case ChecksumMD5:
+		return md5.New()
+	case ChecksumCRC32C:
+		return crc32.New(crc32cTable)
+	default:
+		return sha256.New()
+	}
+}
+
+// Checksum is the digest of an object, persisted next to its key.
+type Checksum struct {
+	Algorithm ChecksumAlgorithm `json:"algorithm" bson:"algorithm"`
+	Value     string            `json:"value" bson:"value"` // base64 của digest, cùng định dạng header của S3
+}
+
+// ParseChecksum accepts the digest either base64 (S3 / Content-MD5 style) or hex encoded.
+func ParseChecksum(algorithm, value string) (*Checksum, error) {
+	alg := ChecksumAlgorithm(strings.ToLower(strings.TrimSpace(algorithm)))
+	if !alg.IsValid() {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidChecksum, algorithm)
+	}
+	value = strings.TrimSpace(value)
+	size := alg.newHash().Size()
+
+	var digest []byte
+	if b, err := hex.DecodeString(value); err == nil && len(b) == size {
+		digest = b
+	} else if b, err := base64.StdEncoding.DecodeString(value); err == nil && len(b) == size {
+		digest = b
+	} else {
+		return nil, fmt.Errorf("%w: malformed %s value", ErrInvalidChecksum, alg)
+	}
+	return &Checksum{Algorithm: alg, Value: base64.StdEncoding.EncodeToString(digest)}, nil
+}
+
+// ComputeChecksum tính checksum của toàn bộ data
+func ComputeChecksum(alg ChecksumAlgorithm, data []byte) *Checksum {
+	h := NewChecksumHasher(alg)
+	_, _ = h.Write(data)
+	return h.Checksum()
+}
+
+// Verify so sánh với checksum thực tế của nội dung; c == nil nghĩa là client không gửi checksum.
+func (c *Checksum) Verify(actual *Checksum) error {
+	if c == nil {
+		return nil
+	}
+	if actual == nil || c.Algorithm != actual.Algorithm || c.Value != actual.Value {
+		return fmt.Errorf("%w: expected %s %s", ErrChecksumMismatch, c.Algorithm, c.Value)
+	}
+	return nil
+}
+
+// ChecksumHasher is an io.Writer computing a Checksum of everything written to it.
+type ChecksumHasher struct {
+	alg ChecksumAlgorithm
+	h   hash.Hash
+}
+
+func NewChecksumHasher(alg ChecksumAlgorithm) *ChecksumHasher {
+	if !alg.IsValid() {
+		alg = ChecksumSHA256
+	}
+	return &ChecksumHasher{alg: alg, h: alg.newHash()}
+}
+
+func (h *ChecksumHasher) Write(p []byte) (int, error) {
+	return h.h.Write(p)
+}
+
+func (h *ChecksumHasher) Checksum() *Checksum {
+	return &Checksum{Algorithm: h.alg, Value: base64.StdEncoding.EncodeToString(h.h.Sum(nil))}
+}
+
+// ChecksumFromHeader đọc checksum theo tên header của S3 (x-amz-checksum-sha256, x-amz-checksum-crc32c, Content-MD5).
+// Trả về nil nếu không có header nào; có nhiều thì ưu tiên thuật toán mạnh hơn.
+func ChecksumFromHeader(get func(string) string) (*Checksum, error) {
+	for _, alg := range []ChecksumAlgorithm{ChecksumSHA256, ChecksumCRC32C, ChecksumMD5} {
+		if v := get(alg.HeaderName()); v != "" {
+			return ParseChecksum(string(alg), v)
+		}
+	}
+	return nil, nil
+}
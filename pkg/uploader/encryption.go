@@ -0,0 +1,120 @@
+package uploader
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type EncryptionType string
+
+const (
+	EncryptionNone   EncryptionType = "none"   // không gửi header, dùng mặc định của bucket
+	EncryptionSSES3  EncryptionType = "sse-s3" // AES256 do S3 quản lý key
+	EncryptionSSEKMS EncryptionType = "sse-kms"
+	EncryptionSSEC   EncryptionType = "sse-c" // key do service giữ, phải gửi kèm mọi request đọc / ghi
+)
+
+var ErrPresignNotSupported = errors.New("presigned upload is not supported for objects encrypted with a customer key")
+
+// Encryption is the server-side encryption applied to one object.
+type Encryption struct {
+	Type     EncryptionType
+	KMSKeyID string // sse-kms, rỗng = key aws/s3 mặc định
+
+	customerKey    string // sse-c: base64 của key 256-bit
+	customerKeyMD5 string
+}
+
+func NewEncryption(typ, kmsKeyID, customerKey string) (Encryption, error) {
+	t := EncryptionType(strings.ToLower(strings.TrimSpace(typ)))
+	if t == "" {
+		t = EncryptionNone
+	}
+
+	switch t {
+	case EncryptionNone, EncryptionSSES3:
+		return Encryption{Type: t}, nil
+	case EncryptionSSEKMS:
+		return Encryption{Type: t, KMSKeyID: kmsKeyID}, nil
+	case EncryptionSSEC:
+		raw, err := base64.StdEncoding.DecodeString(customerKey)
+		if err != nil || len(raw) != 32 {
+			return Encryption{}, fmt.Errorf("sse-c customer key must be a base64 encoded 256-bit key")
+		}
+		sum := md5.Sum(raw)
+		return Encryption{
+			Type:           t,
+			customerKey:    customerKey,
+			customerKeyMD5: base64.StdEncoding.EncodeToString(sum[:]),
+		}, nil
+	default:
+		return Encryption{}, fmt.Errorf("invalid encryption type: %s", typ)
+	}
+}
+
+func (e Encryption) isCustomerKey() bool {
+	return e.Type == EncryptionSSEC
+}
+
+// EncryptionRules chọn Encryption cho một object: prefix dài nhất khớp key > UploadMode > Default.
+type EncryptionRules struct {
+	Default  Encryption
+	Modes    map[UploadMode]Encryption
+	Prefixes map[string]Encryption
+}
+
+// Validate: sse-c chỉ được khai báo theo prefix. Khi đọc object không biết nó được ghi ở mode nào,
+// nên key SSE-C phải suy ra được từ chính object key.
+func (r *EncryptionRules) Validate() error {
+	if r.Default.isCustomerKey() {
+		return fmt.Errorf("sse-c can only be configured per key prefix")
+	}
+	for mode, enc := range r.Modes {
+		if enc.isCustomerKey() {
+			return fmt.Errorf("sse-c can only be configured per key prefix, not for mode %s", mode)
+		}
+	}
+	// CDN đọc vùng public không có key SSE-C
+	for prefix, enc := range r.Prefixes {
+		if enc.isCustomerKey() && (strings.HasPrefix(prefix, PublicPrefix) || strings.HasPrefix(PublicPrefix, prefix)) {
+			return fmt.Errorf("sse-c prefix %s overlaps the public prefix %s", prefix, PublicPrefix)
+		}
+	}
+	return nil
+}
+
+// ForWrite trả về encryption dùng khi ghi object mới
+func (r *EncryptionRules) ForWrite(key string, mode UploadMode) Encryption {
+	if enc, ok := r.byPrefix(key); ok {
+		return enc
+	}
+	if enc, ok := r.Modes[mode]; ok {
+		return enc
+	}
+	return r.Default
+}
+
+// forRead trả về encryption cần gửi kèm khi đọc object (chỉ SSE-C mới cần)
+func (r *EncryptionRules) forRead(key string) Encryption {
+	if enc, ok := r.byPrefix(key); ok && enc.isCustomerKey() {
+		return enc
+	}
+	return Encryption{Type: EncryptionNone}
+}
+
+func (r *EncryptionRules) byPrefix(key string) (Encryption, bool) {
+	var (
+		best    Encryption
+		bestLen = -1
+	)
+	for prefix, enc := range r.Prefixes {
+		if strings.HasPrefix(key, prefix) && len(prefix) > bestLen {
+			best, bestLen = enc, len(prefix)
+		}
+	}
+	return best, bestLen >= 0
+}
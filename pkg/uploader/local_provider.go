@@ -0,0 +1,477 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// multipartDir chứa các part của multipart upload đang dở, không phải object
+const multipartDir = ".multipart"
+
+var (
+	ErrInvalidSignature = errors.New("invalid url signature")
+	ErrURLExpired       = errors.New("url has expired")
+	ErrInvalidKey       = errors.New("invalid object key")
+)
+
+// localProvider stores objects on the local filesystem and hands out
+// HMAC-signed, expiring URLs that are served back by the service itself.
+// It is meant for development and tests where no AWS account is available.
+type localProvider struct {
+	rootDir       string
+	baseURL       string
+	signingSecret []byte
+}
+
+// NewLocalProvider requires baseURL (urls are served back by the service, a relative url is useless
+// to clients) and signingSecret (without it anyone could forge GET and PUT urls).
+func NewLocalProvider(rootDir, baseURL, signingSecret string) (*localProvider, error) {
+	if strings.TrimSpace(baseURL) == "" {
+		return nil, errors.New("local storage: base_url is required")
+	}
+	if strings.TrimSpace(signingSecret) == "" {
+		return nil, errors.New("local storage: signing_secret is required")
+	}
+	if rootDir == "" {
+		rootDir = "./storage"
+	}
+	absRoot, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("local storage: %w", err)
+	}
+	if err := os.MkdirAll(absRoot, 0o755); err != nil {
+		return nil, fmt.Errorf("local storage: %w", err)
+	}
+
+	return &localProvider{
+		rootDir:       absRoot,
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		signingSecret: []byte(signingSecret),
+	}, nil
+}
+
+func (p *localProvider) SaveFileUploaded(ctx context.Context, data []byte, key string, mode UploadMode, checksum *Checksum) (*string, error) {
+	return p.SaveFileUploadedReader(ctx, bytes.NewReader(data), key, "", mode, checksum)
+}
+
+func (p *localProvider) SaveFileUploadedReader(ctx context.Context, r io.Reader, key string, contentType string, mode UploadMode, checksum *Checksum) (*string, error) {
+	if mode != UploadPrivate && mode != UploadPublic {
+		return nil, errors.New("invalid upload mode")
+	}
+	dest, err := p.FilePath(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// ghi ra file tạm rồi rename để tránh đọc phải file ghi dở
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	var w io.Writer = tmp
+	var hasher *ChecksumHasher
+	if checksum != nil {
+		hasher = NewChecksumHasher(checksum.Algorithm)
+		w = io.MultiWriter(tmp, hasher)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, fmt.Errorf("failed to write file %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, fmt.Errorf("failed to write file %w", err)
+	}
+	// giống S3: nội dung không khớp checksum thì không được ghi vào key đích
+	if hasher != nil {
+		if err := checksum.Verify(hasher.Checksum()); err != nil {
+			_ = os.Remove(tmp.Name())
+			return nil, err
+		}
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, fmt.Errorf("failed to move file into place: %w", err)
+	}
+
+	// key dưới PublicPrefix nhận url không ký, còn lại là signed url mặc định
+	return p.GetFileUploaded(ctx, key, nil)
+}
+
+func (p *localProvider) GetFileUploaded(ctx context.Context, key string, duration *time.Duration) (*string, error) {
+	if _, err := p.FilePath(key); err != nil {
+		return nil, err
+	}
+
+	if IsPublicKey(key) {
+		publicURL := fmt.Sprintf("%s/%s", p.baseURL, (&url.URL{Path: key}).EscapedPath())
+		return &publicURL, nil
+	}
+
+	if duration == nil {
+		duration = aws.Duration(24 * time.Hour)
+	}
+	expires := time.Now().Add(*duration).Unix()
+
+	escaped := (&url.URL{Path: key}).EscapedPath()
+	signedURL := fmt.Sprintf("%s/%s?expires=%d&signature=%s", p.baseURL, escaped, expires, p.sign(http.MethodGet, key, expires))
+	return &signedURL, nil
+}
+
+func (p *localProvider) URLLifetime(key string, duration time.Duration) time.Duration {
+	if IsPublicKey(key) {
+		return 0
+	}
+	return duration
+}
+
+func (p *localProvider) PresignPutObject(ctx context.Context, key string, contentType string, mode UploadMode, duration time.Duration) (string, map[string]string, error) {
+	if _, err := p.FilePath(key); err != nil {
+		return "", nil, err
+	}
+	expires := time.Now().Add(duration).Unix()
+
+	escaped := (&url.URL{Path: key}).EscapedPath()
+	signedURL := fmt.Sprintf("%s/%s?expires=%d&signature=%s", p.baseURL, escaped, expires, p.sign(http.MethodPut, key, expires))
+	return signedURL, nil, nil
+}
+
+func (p *localProvider) HeadObject(ctx context.Context, key string) (*ObjectInfo, error) {
+	dest, err := p.FilePath(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(dest)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to head object: %w", err)
+	}
+
+	// không lưu content type riêng → đoán theo đuôi file, sau đó theo nội dung
+	contentType := mime.TypeByExtension(filepath.Ext(dest))
+	if contentType == "" {
+		f, err := os.Open(dest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to head object: %w", err)
+		}
+		defer f.Close()
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(f, head)
+		contentType = http.DetectContentType(head[:n])
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		ContentType:  contentType,
+		ETag:         fmt.Sprintf("%x-%x", stat.ModTime().UnixNano(), stat.Size()),
+		LastModified: stat.ModTime(),
+	}, nil
+}
+
+func (p *localProvider) DeleteFileUploaded(ctx context.Context, key string) error {
+	dest, err := p.FilePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+func (p *localProvider) CreateMultipartUpload(ctx context.Context, key string, contentType string) (string, error) {
+	if _, err := p.FilePath(key); err != nil {
+		return "", err
+	}
+
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "", fmt.Errorf("failed to generate upload id: %w", err)
+	}
+	uploadID := hex.EncodeToString(randBytes)
+
+	if err := os.MkdirAll(p.partsDir(uploadID), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+	return uploadID, nil
+}
+
+func (p *localProvider) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, r io.Reader, size int64) (string, error) {
+	dir := p.partsDir(uploadID)
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("multipart upload not found: %w", err)
+	}
+
+	f, err := os.Create(filepath.Join(dir, strconv.Itoa(int(partNumber))))
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+	defer f.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), r); err != nil {
+		return "", fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (p *localProvider) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error {
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+
+	dir := p.partsDir(uploadID)
+	readers := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		f, err := os.Open(filepath.Join(dir, strconv.Itoa(int(part.PartNumber))))
+		if err != nil {
+			return fmt.Errorf("failed to complete multipart upload: %w", err)
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+
+	if _, err := p.SaveFileUploadedReader(ctx, io.MultiReader(readers...), key, "", UploadPrivate, nil); err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return os.RemoveAll(dir)
+}
+
+func (p *localProvider) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	if err := os.RemoveAll(p.partsDir(uploadID)); err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	return nil
+}
+
+func (p *localProvider) ListObjects(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	err := filepath.WalkDir(p.rootDir, func(fullPath string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == multipartDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// file tạm đang ghi dở
+		if strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(p.rootDir, fullPath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		stat, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(ObjectInfo{
+			Key:          key,
+			Size:         stat.Size(),
+			ETag:         fmt.Sprintf("%x-%x", stat.ModTime().UnixNano(), stat.Size()),
+			LastModified: stat.ModTime(),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list objects: %w", err)
+	}
+	return nil
+}
+
+func (p *localProvider) GetObject(ctx context.Context, key string, opts GetObjectOptions) (*ObjectReader, error) {
+	info, err := p.HeadObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	etag := `"` + info.ETag + `"`
+	if opts.IfNoneMatch != "" && (opts.IfNoneMatch == etag || opts.IfNoneMatch == "*") {
+		return &ObjectReader{ETag: etag, Size: info.Size}, ErrNotModified
+	}
+
+	start, end := int64(0), info.Size-1
+	contentRange := ""
+	if opts.Range != "" {
+		start, end, err = parseByteRange(opts.Range, info.Size)
+		if err != nil {
+			return &ObjectReader{ETag: etag, Size: info.Size}, err
+		}
+		contentRange = fmt.Sprintf("bytes %d-%d/%d", start, end, info.Size)
+	}
+
+	dest, _ := p.FilePath(key)
+	f, err := os.Open(dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	length := end - start + 1
+	return &ObjectReader{
+		Body: struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(f, length), f},
+		ContentLength: length,
+		ContentRange:  contentRange,
+		ContentType:   info.ContentType,
+		ETag:          etag,
+		LastModified:  info.LastModified,
+		Size:          info.Size,
+	}, nil
+}
+
+func (p *localProvider) CopyObject(ctx context.Context, srcKey, destKey string) error {
+	src, err := p.FilePath(srcKey)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrObjectNotFound
+		}
+		return fmt.Errorf("failed to copy object: %w", err)
+	}
+	defer f.Close()
+
+	_, err = p.SaveFileUploadedReader(ctx, f, destKey, "", UploadPrivate, nil)
+	return err
+}
+
+// thư mục local không có storage class: object luôn đọc được ngay nên các thao tác tiering là no-op
+
+func (p *localProvider) SetStorageClass(ctx context.Context, key string, class StorageClass) error {
+	_, err := p.HeadObject(ctx, key)
+	return err
+}
+
+func (p *localProvider) TagObject(ctx context.Context, key string, tags map[string]string) error {
+	_, err := p.HeadObject(ctx, key)
+	return err
+}
+
+func (p *localProvider) RestoreObject(ctx context.Context, key string, days int32, tier string) error {
+	return nil
+}
+
+// parseByteRange hỗ trợ một khoảng duy nhất: "bytes=a-b", "bytes=a-", "bytes=-n"
+func parseByteRange(header string, size int64) (int64, int64, error) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") || size == 0 {
+		return 0, 0, ErrInvalidRange
+	}
+	first, last, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, ErrInvalidRange
+	}
+
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, ErrInvalidRange
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, ErrInvalidRange
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, ErrInvalidRange
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+	return start, end, nil
+}
+
+func (p *localProvider) partsDir(uploadID string) string {
+	return filepath.Join(p.rootDir, multipartDir, filepath.Base(uploadID))
+}
+
+// VerifySignature checks the expires/signature pair of a url signed for method: GET for
+// GetFileUploaded, PUT for PresignPutObject. A url signed for one method is rejected for the other.
+func (p *localProvider) VerifySignature(method, key, expires, signature string) error {
+	if method != http.MethodGet && method != http.MethodPut {
+		return ErrInvalidSignature
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	expected := p.sign(method, key, exp)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	if time.Now().Unix() > exp {
+		return ErrURLExpired
+	}
+	return nil
+}
+
+// FilePath resolves an object key to its absolute path under the root directory.
+// Keys under the reserved multipart directory are rejected.
+func (p *localProvider) FilePath(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned == "/" || strings.Contains(key, "..") {
+		return "", ErrInvalidKey
+	}
+	if cleaned == "/"+multipartDir || strings.HasPrefix(cleaned, "/"+multipartDir+"/") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(p.rootDir, filepath.FromSlash(cleaned)), nil
+}
+
+// sign ký "<method>\n<key>\n<expires>", method tách url đọc và url upload của cùng key
+func (p *localProvider) sign(method, key string, expires int64) string {
+	mac := hmac.New(sha256.New, p.signingSecret)
+	mac.Write([]byte(method))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(key))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
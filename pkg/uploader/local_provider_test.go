@@ -0,0 +1,222 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestProvider(t *testing.T) *localProvider {
+	t.Helper()
+	p, err := NewLocalProvider(t.TempDir(), "http://localhost:8022/api/v2/files/", "test-secret")
+	if err != nil {
+		t.Fatalf("NewLocalProvider: %v", err)
+	}
+	return p
+}
+
+// signedParams tách expires / signature từ url local đã ký
+func signedParams(t *testing.T, raw string) (string, string) {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse url %q: %v", raw, err)
+	}
+	return u.Query().Get("expires"), u.Query().Get("signature")
+}
+
+func TestNewLocalProviderRequiresConfig(t *testing.T) {
+	tests := []struct {
+		name, baseURL, secret string
+	}{
+		{"no base url", "", "secret"},
+		{"no secret", "http://localhost/files", ""},
+		{"blank secret", "http://localhost/files", "   "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLocalProvider(t.TempDir(), tt.baseURL, tt.secret); err == nil {
+				t.Fatal("NewLocalProvider: want error")
+			}
+		})
+	}
+}
+
+func TestLocalSignedGetURL(t *testing.T) {
+	p := newTestProvider(t)
+	ctx := context.Background()
+	key := "topic_media/image/a b.png"
+
+	signed, err := p.GetFileUploaded(ctx, key, nil)
+	if err != nil {
+		t.Fatalf("GetFileUploaded: %v", err)
+	}
+	if !strings.HasPrefix(*signed, "http://localhost:8022/api/v2/files/topic_media/image/a%20b.png?") {
+		t.Errorf("GetFileUploaded = %q", *signed)
+	}
+	expires, signature := signedParams(t, *signed)
+
+	if err := p.VerifySignature(http.MethodGet, key, expires, signature); err != nil {
+		t.Errorf("VerifySignature(GET) = %v", err)
+	}
+	// url đọc không được dùng để upload, và không dùng được cho key khác
+	if err := p.VerifySignature(http.MethodPut, key, expires, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifySignature(PUT) = %v, want ErrInvalidSignature", err)
+	}
+	if err := p.VerifySignature(http.MethodGet, key+"x", expires, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifySignature(other key) = %v, want ErrInvalidSignature", err)
+	}
+	if err := p.VerifySignature(http.MethodDelete, key, expires, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifySignature(DELETE) = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestLocalPresignPut(t *testing.T) {
+	p := newTestProvider(t)
+	key := "uploads/video.mp4"
+
+	signed, _, err := p.PresignPutObject(context.Background(), key, "video/mp4", UploadPrivate, time.Hour)
+	if err != nil {
+		t.Fatalf("PresignPutObject: %v", err)
+	}
+	expires, signature := signedParams(t, signed)
+	if err := p.VerifySignature(http.MethodPut, key, expires, signature); err != nil {
+		t.Errorf("VerifySignature(PUT) = %v", err)
+	}
+	if err := p.VerifySignature(http.MethodGet, key, expires, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifySignature(GET) = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestLocalVerifySignatureRejects(t *testing.T) {
+	p := newTestProvider(t)
+	key := "a/b.txt"
+	past := time.Now().Add(-time.Minute).Unix()
+	future := time.Now().Add(time.Minute).Unix()
+
+	other, err := NewLocalProvider(t.TempDir(), "http://localhost/files", "other-secret")
+	if err != nil {
+		t.Fatalf("NewLocalProvider: %v", err)
+	}
+
+	tests := []struct {
+		name, expires, signature string
+		want                     error
+	}{
+		{"expired", strconv.FormatInt(past, 10), p.sign(http.MethodGet, key, past), ErrURLExpired},
+		{"expires changed", strconv.FormatInt(future+1, 10), p.sign(http.MethodGet, key, future), ErrInvalidSignature},
+		{"expires not a number", "soon", p.sign(http.MethodGet, key, future), ErrInvalidSignature},
+		{"empty signature", strconv.FormatInt(future, 10), "", ErrInvalidSignature},
+		{"other secret", strconv.FormatInt(future, 10), other.sign(http.MethodGet, key, future), ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.VerifySignature(http.MethodGet, key, tt.expires, tt.signature); !errors.Is(err, tt.want) {
+				t.Errorf("VerifySignature = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalPublicURLIsUnsigned(t *testing.T) {
+	p := newTestProvider(t)
+	key := PublicKey("videos/a.mp4")
+
+	got, err := p.GetFileUploaded(context.Background(), key, nil)
+	if err != nil {
+		t.Fatalf("GetFileUploaded: %v", err)
+	}
+	if want := "http://localhost:8022/api/v2/files/" + key; *got != want {
+		t.Errorf("GetFileUploaded = %q, want %q", *got, want)
+	}
+}
+
+func TestLocalFilePath(t *testing.T) {
+	p := newTestProvider(t)
+	for _, key := range []string{"", "/", "../etc/passwd", "a/../../b", ".multipart", ".multipart/abc/1", "/.multipart/abc", "./.multipart/x"} {
+		if _, err := p.FilePath(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("FilePath(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+	got, err := p.FilePath("a/.multipart/b.txt")
+	if err != nil {
+		t.Fatalf("FilePath: %v", err)
+	}
+	if want := filepath.Join(p.rootDir, "a", ".multipart", "b.txt"); got != want {
+		t.Errorf("FilePath = %q, want %q", got, want)
+	}
+}
+
+func TestLocalSaveRejectsInvalidModeBeforeWriting(t *testing.T) {
+	p := newTestProvider(t)
+	key := "a/b.txt"
+
+	if _, err := p.SaveFileUploaded(context.Background(), []byte("data"), key, Unknown, nil); err == nil {
+		t.Fatal("SaveFileUploaded: want error for invalid mode")
+	}
+	dest, _ := p.FilePath(key)
+	if _, err := os.Stat(filepath.Dir(dest)); !os.IsNotExist(err) {
+		t.Errorf("stat %s = %v, want not exist", filepath.Dir(dest), err)
+	}
+}
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		header     string
+		size       int64
+		start, end int64
+	}{
+		{"bytes=0-99", 1000, 0, 99},
+		{"bytes=100-", 1000, 100, 999},
+		{"bytes=-100", 1000, 900, 999},
+		{"bytes=-5000", 1000, 0, 999},
+		{"bytes=900-5000", 1000, 900, 999},
+		{"bytes=999-999", 1000, 999, 999},
+		{" bytes=0-0 ", 1, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			start, end, err := parseByteRange(tt.header, tt.size)
+			if err != nil {
+				t.Fatalf("parseByteRange(%q, %d): %v", tt.header, tt.size, err)
+			}
+			if start != tt.start || end != tt.end {
+				t.Errorf("parseByteRange(%q, %d) = %d-%d, want %d-%d", tt.header, tt.size, start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestParseByteRangeInvalid(t *testing.T) {
+	tests := []struct {
+		header string
+		size   int64
+	}{
+		{"", 1000},
+		{"0-99", 1000},
+		{"items=0-99", 1000},
+		{"bytes=0-99,200-299", 1000},
+		{"bytes=", 1000},
+		{"bytes=-", 1000},
+		{"bytes=-0", 1000},
+		{"bytes=1000-", 1000},
+		{"bytes=100-50", 1000},
+		{"bytes=a-b", 1000},
+		{"bytes=-1-2", 1000},
+		{"bytes=0-0", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if start, end, err := parseByteRange(tt.header, tt.size); !errors.Is(err, ErrInvalidRange) {
+				t.Errorf("parseByteRange(%q, %d) = %d-%d, %v; want ErrInvalidRange", tt.header, tt.size, start, end, err)
+			}
+		})
+	}
+}
@@ -34,9 +34,100 @@ func UploadModeFromString(s string) (UploadMode, error) {
 	}
 }
 
+// PublicPrefix là vùng object được đọc công khai (CloudFront behavior / bucket policy không yêu cầu chữ ký).
+// Url của object dưới prefix này không ký và không hết hạn.
+const PublicPrefix = "public/"
+
+// IsPublicKey reports whether the object is served without a signature.
+func IsPublicKey(key string) bool {
+	return strings.HasPrefix(key, PublicPrefix)
+}
+
+// PublicKey moves a key under PublicPrefix.
+func PublicKey(key string) string {
+	if IsPublicKey(key) {
+		return key
+	}
+	return PublicPrefix + strings.TrimLeft(key, "/")
+}
+
+// CompletedPart identifies one uploaded part of a multipart upload.
+type CompletedPart struct {
+	PartNumber int32
+	ETag       string
+}
+
+// ObjectInfo is the metadata returned by a HEAD request on a stored object.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+	StorageClass StorageClass  // rỗng = STANDARD (hoặc provider không có storage class)
+	Restore      RestoreStatus // chỉ có ý nghĩa với class đã lưu trữ (GLACIER, DEEP_ARCHIVE)
+}
+
+var (
+	ErrObjectNotFound = errors.New("object not found")
+	ErrNotModified    = errors.New("object not modified")
+	ErrInvalidRange   = errors.New("requested range not satisfiable")
+)
+
+// GetObjectOptions mirrors the conditional / partial request headers of a GET.
+type GetObjectOptions struct {
+	Range       string // nguyên giá trị header Range, ví dụ "bytes=0-1023"
+	IfNoneMatch string
+}
+
+// ObjectReader is an open object body. Caller must close Body.
+// Together with ErrNotModified / ErrInvalidRange, GetObject returns an ObjectReader
+// without Body carrying only ETag and Size, so the 304 / 416 response can echo them.
+type ObjectReader struct {
+	Body          io.ReadCloser
+	ContentLength int64  // số byte của Body
+	ContentRange  string // khác rỗng khi trả về một phần (206)
+	ContentType   string
+	ETag          string
+	LastModified  time.Time
+	Size          int64 // dung lượng cả object, 0 nếu provider không biết
+}
+
 type UploadProvider interface {
-	SaveFileUploaded(ctx context.Context, data []byte, dest string, mode UploadMode) (*string, error)
-	SaveFileUploadedReader(ctx context.Context, r io.Reader, dest string, contentType string, mode UploadMode) (*string, error)
+	// checksum != nil: storage phải từ chối nội dung không khớp (ErrChecksumMismatch) thay vì lưu lại
+	SaveFileUploaded(ctx context.Context, data []byte, dest string, mode UploadMode, checksum *Checksum) (*string, error)
+	SaveFileUploadedReader(ctx context.Context, r io.Reader, dest string, contentType string, mode UploadMode, checksum *Checksum) (*string, error)
 	GetFileUploaded(ctx context.Context, key string, duration *time.Duration) (*string, error)
+	// URLLifetime là thời hạn thực tế của url GetFileUploaded ký cho key với duration yêu cầu,
+	// 0 = url không hết hạn (object public)
+	URLLifetime(key string, duration time.Duration) time.Duration
 	DeleteFileUploaded(ctx context.Context, key string) error
+
+	// multipart (resumable) uploads
+	CreateMultipartUpload(ctx context.Context, key string, contentType string) (string, error)
+	UploadPart(ctx context.Context, key, uploadID string, partNumber int32, r io.Reader, size int64) (string, error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+
+	// direct-to-bucket uploads
+	// PresignPutObject trả về thêm các header (encryption) client phải gửi kèm url đã ký
+	PresignPutObject(ctx context.Context, key string, contentType string, mode UploadMode, duration time.Duration) (string, map[string]string, error)
+	HeadObject(ctx context.Context, key string) (*ObjectInfo, error)
+
+	// ListObjects gọi fn cho từng object dưới prefix (ContentType không được điền)
+	ListObjects(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+
+	// GetObject mở object để stream, hỗ trợ Range và If-None-Match
+	GetObject(ctx context.Context, key string, opts GetObjectOptions) (*ObjectReader, error)
+
+	// CopyObject sao chép object trong cùng bucket, trả về ErrObjectNotFound nếu srcKey không tồn tại
+	CopyObject(ctx context.Context, srcKey, destKey string) error
+
+	// storage class: SetStorageClass chép đè object lên chính nó với class mới,
+	// TagObject gắn tag để lifecycle rule của bucket tự chuyển class
+	SetStorageClass(ctx context.Context, key string, class StorageClass) error
+	TagObject(ctx context.Context, key string, tags map[string]string) error
+	// RestoreObject yêu cầu tạo bản đọc được trong days ngày cho object GLACIER / DEEP_ARCHIVE,
+	// gọi lại khi đang khôi phục không lỗi
+	RestoreObject(ctx context.Context, key string, days int32, tier string) error
 }
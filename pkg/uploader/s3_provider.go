@@ -10,17 +10,63 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/feature/cloudfront/sign"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 )
 
+const (
+	URLStrategyCloudFront = "cloudfront" // mặc định: ký url qua CloudFront key group
+	URLStrategyPresigned  = "presigned"  // presigned GET của chính S3 (MinIO, Ceph, R2...)
+
+	// presigned GET của S3 (SigV4) chỉ hợp lệ tối đa 7 ngày
+	maxPresignedGetDuration = 7 * 24 * time.Hour
+)
+
+// S3Option customises the S3 client for S3-compatible storages.
+type S3Option func(*s3Provider)
+
+// WithEndpoint points the client at a custom endpoint, e.g. http://minio:9000.
+func WithEndpoint(endpoint string) S3Option {
+	return func(p *s3Provider) { p.endpoint = endpoint }
+}
+
+// WithPathStyle addresses buckets as endpoint/bucket/key instead of bucket.endpoint/key.
+func WithPathStyle(enabled bool) S3Option {
+	return func(p *s3Provider) { p.usePathStyle = enabled }
+}
+
+// WithURLStrategy selects how download urls are signed: "cloudfront" (default) or "presigned".
+func WithURLStrategy(strategy string) S3Option {
+	return func(p *s3Provider) {
+		if strategy != "" {
+			p.urlStrategy = strategy
+		}
+	}
+}
+
+// WithPublicBaseURL sets the origin of unsigned urls for keys under PublicPrefix (default: domain).
+func WithPublicBaseURL(baseURL string) S3Option {
+	return func(p *s3Provider) { p.publicBaseURL = strings.TrimRight(baseURL, "/") }
+}
+
+// WithEncryption sets the server-side encryption rules applied to written objects.
+func WithEncryption(rules EncryptionRules) S3Option {
+	return func(p *s3Provider) { p.encryption = rules }
+}
+
 type s3Provider struct {
 	accessKey            string
 	secretKey            string
@@ -30,9 +76,18 @@ type s3Provider struct {
 	cloudFrontKeyGroupID string
 	cloudFrontKeyPath    string
 	config               aws.Config
+	endpoint             string
+	usePathStyle         bool
+	urlStrategy          string
+	encryption           EncryptionRules
+	publicBaseURL        string
+
+	// private key được đọc và parse một lần khi khởi tạo
+	signer    *sign.URLSigner
+	signerErr error
 }
 
-func NewS3Provider(accessKey, secretKey, bucketName, region, domain, cloudFrontKeyGroupID, cloudFrontKeyPath string) *s3Provider {
+func NewS3Provider(accessKey, secretKey, bucketName, region, domain, cloudFrontKeyGroupID, cloudFrontKeyPath string, opts ...S3Option) *s3Provider {
 	provider := &s3Provider{
 		accessKey:            accessKey,
 		secretKey:            secretKey,
@@ -41,6 +96,19 @@ func NewS3Provider(accessKey, secretKey, bucketName, region, domain, cloudFrontK
 		domain:               domain,
 		cloudFrontKeyGroupID: cloudFrontKeyGroupID,
 		cloudFrontKeyPath:    cloudFrontKeyPath,
+		urlStrategy:          URLStrategyCloudFront,
+	}
+	for _, opt := range opts {
+		opt(provider)
+	}
+	if provider.urlStrategy != URLStrategyCloudFront && provider.urlStrategy != URLStrategyPresigned {
+		panic(fmt.Sprintf("invalid s3 url strategy: %s", provider.urlStrategy))
+	}
+	if provider.publicBaseURL == "" {
+		provider.publicBaseURL = strings.TrimRight(domain, "/")
+	}
+	if err := provider.encryption.Validate(); err != nil {
+		panic(fmt.Sprintf("invalid s3 encryption config: %v", err))
 	}
 
 	creds := aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(
@@ -58,73 +126,209 @@ func NewS3Provider(accessKey, secretKey, bucketName, region, domain, cloudFrontK
 	}
 
 	provider.config = cfg
+	if provider.urlStrategy == URLStrategyCloudFront {
+		provider.signer, provider.signerErr = loadCloudFrontSigner(cloudFrontKeyGroupID, cloudFrontKeyPath)
+	}
 
 	return provider
 }
 
-func (p *s3Provider) SaveFileUploaded(ctx context.Context, data []byte, key string, mode UploadMode) (*string, error) {
+func (p *s3Provider) client() *s3.Client {
+	return s3.NewFromConfig(p.config, func(o *s3.Options) {
+		if p.endpoint != "" {
+			o.BaseEndpoint = aws.String(p.endpoint)
+		}
+		o.UsePathStyle = p.usePathStyle
+	})
+}
+
+func (p *s3Provider) SaveFileUploaded(ctx context.Context, data []byte, key string, mode UploadMode, checksum *Checksum) (*string, error) {
 	fileBytes := bytes.NewReader(data)
 	fileType := http.DetectContentType(data)
 
-	client := s3.NewFromConfig(p.config)
+	client := p.client()
 
-	_, err := client.PutObject(ctx, &s3.PutObjectInput{
+	input := &s3.PutObjectInput{
 		Bucket:      aws.String(p.bucketName),
 		Key:         aws.String(key),
 		Body:        fileBytes,
 		ContentType: aws.String(fileType),
 		ACL:         types.ObjectCannedACLPrivate,
-	})
+	}
+	applyChecksum(input, checksum)
+	applyPutEncryption(input, p.encryption.ForWrite(key, mode))
+	_, err := client.PutObject(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload file to S3 %w", err)
+		return nil, fmt.Errorf("failed to upload file to S3 %w", mapChecksumError(err))
 	}
 
-	switch mode {
-	case UploadPrivate:
-		return p.GetFileUploaded(ctx, key, nil)
-	case UploadPublic:
-		duration := 100 * 365 * 24 * time.Hour
-		return p.GetFileUploaded(ctx, key, &duration)
-	default:
+	if mode != UploadPrivate && mode != UploadPublic {
 		return nil, errors.New("invalid upload mode")
 	}
+	// key dưới PublicPrefix nhận url không ký, còn lại là signed url mặc định
+	return p.GetFileUploaded(ctx, key, nil)
 }
 
-func (p *s3Provider) SaveFileUploadedReader(ctx context.Context, r io.Reader, key string, contentType string, mode UploadMode) (*string, error) {
+func (p *s3Provider) SaveFileUploadedReader(ctx context.Context, r io.Reader, key string, contentType string, mode UploadMode, checksum *Checksum) (*string, error) {
 	if contentType == "" {
 		contentType = "application/octet-stream"
 	}
 
-	client := s3.NewFromConfig(p.config)
-	_, err := client.PutObject(ctx, &s3.PutObjectInput{
+	client := p.client()
+	input := &s3.PutObjectInput{
 		Bucket:      aws.String(p.bucketName),
 		Key:         aws.String(key),
 		Body:        r,
 		ContentType: aws.String(contentType),
 		ACL:         types.ObjectCannedACLPrivate,
-	})
+	}
+	applyChecksum(input, checksum)
+	applyPutEncryption(input, p.encryption.ForWrite(key, mode))
+	_, err := client.PutObject(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload stream to S3 %w", err)
+		return nil, fmt.Errorf("failed to upload stream to S3 %w", mapChecksumError(err))
 	}
 
-	switch mode {
-	case UploadPrivate:
-		return p.GetFileUploaded(ctx, key, nil)
-	case UploadPublic:
-		duration := 100 * 365 * 24 * time.Hour
-		return p.GetFileUploaded(ctx, key, &duration)
-	default:
+	if mode != UploadPrivate && mode != UploadPublic {
 		return nil, errors.New("invalid upload mode")
 	}
+	// key dưới PublicPrefix nhận url không ký, còn lại là signed url mặc định
+	return p.GetFileUploaded(ctx, key, nil)
 }
 
 func (p *s3Provider) GetFileUploaded(ctx context.Context, key string, duration *time.Duration) (*string, error) {
+	if IsPublicKey(key) && p.publicBaseURL != "" {
+		publicURL := p.publicBaseURL + "/" + (&url.URL{Path: key}).EscapedPath()
+		return &publicURL, nil
+	}
+
+	if duration == nil {
+		duration = aws.Duration(24 * time.Hour)
+	}
+	if p.urlStrategy == URLStrategyPresigned {
+		return p.presignGet(ctx, key, *duration)
+	}
+
+	if p.signerErr != nil {
+		return nil, p.signerErr
+	}
+
+	url := fmt.Sprintf("%s/%s", p.domain, key)
+
+	signedURL, err := p.signer.Sign(url, time.Now().Add(*duration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign URL: %w", err)
+	}
+
+	return &signedURL, nil
+}
+
+func (p *s3Provider) URLLifetime(key string, duration time.Duration) time.Duration {
+	if IsPublicKey(key) && p.publicBaseURL != "" {
+		return 0
+	}
+	if p.urlStrategy == URLStrategyPresigned && duration > maxPresignedGetDuration {
+		return maxPresignedGetDuration
+	}
+	return duration
+}
+
+// applyChecksum gửi checksum đã biết trước kèm PutObject để S3 tự từ chối nội dung không khớp
+func applyChecksum(input *s3.PutObjectInput, checksum *Checksum) {
+	if checksum == nil {
+		return
+	}
+	switch checksum.Algorithm {
+	case ChecksumMD5:
+		input.ContentMD5 = aws.String(checksum.Value)
+	case ChecksumSHA256:
+		input.ChecksumSHA256 = aws.String(checksum.Value)
+	case ChecksumCRC32C:
+		input.ChecksumCRC32C = aws.String(checksum.Value)
+	}
+}
+
+// putEncryptionInput là các request ghi object mới, đều mang cùng bộ header SSE
+type putEncryptionInput interface {
+	*s3.PutObjectInput | *s3.CreateMultipartUploadInput | *s3.CopyObjectInput
+}
+
+// applyPutEncryption gắn header SSE-S3 / SSE-KMS / SSE-C của enc vào request ghi object
+func applyPutEncryption[T putEncryptionInput](input T, enc Encryption) {
+	var (
+		sse      types.ServerSideEncryption
+		kmsKeyID *string
+	)
+	switch enc.Type {
+	case EncryptionSSES3:
+		sse = types.ServerSideEncryptionAes256
+	case EncryptionSSEKMS:
+		sse = types.ServerSideEncryptionAwsKms
+		if enc.KMSKeyID != "" {
+			kmsKeyID = aws.String(enc.KMSKeyID)
+		}
+	}
+	algorithm, key, keyMD5 := customerKey(enc)
+
+	switch in := any(input).(type) {
+	case *s3.PutObjectInput:
+		in.ServerSideEncryption, in.SSEKMSKeyId = sse, kmsKeyID
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = algorithm, key, keyMD5
+	case *s3.CreateMultipartUploadInput:
+		in.ServerSideEncryption, in.SSEKMSKeyId = sse, kmsKeyID
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = algorithm, key, keyMD5
+	case *s3.CopyObjectInput:
+		in.ServerSideEncryption, in.SSEKMSKeyId = sse, kmsKeyID
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = algorithm, key, keyMD5
+	}
+}
+
+// customerKey trả về bộ (algorithm, key, key MD5) phải gửi kèm mọi request đọc / ghi object SSE-C,
+// cả ba là nil với các loại encryption khác
+func customerKey(enc Encryption) (algorithm, key, keyMD5 *string) {
+	if !enc.isCustomerKey() {
+		return nil, nil, nil
+	}
+	return aws.String("AES256"), aws.String(enc.customerKey), aws.String(enc.customerKeyMD5)
+}
+
+func mapChecksumError(err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "BadDigest", "InvalidDigest", "XAmzContentChecksumMismatch":
+			return fmt.Errorf("%w: %s", ErrChecksumMismatch, apiErr.ErrorMessage())
+		}
+	}
+	return err
+}
+
+// presignGet ký url bằng SigV4 của S3, dùng khi không có CloudFront phía trước bucket.
+// Thời hạn dài hơn 7 ngày sẽ bị giới hạn xuống 7 ngày.
+func (p *s3Provider) presignGet(ctx context.Context, key string, duration time.Duration) (*string, error) {
+	if duration > maxPresignedGetDuration {
+		duration = maxPresignedGetDuration
+	}
+
+	presignClient := s3.NewPresignClient(p.client())
+	req, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.bucketName),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(duration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign get object: %w", err)
+	}
+	return &req.URL, nil
+}
+
+// loadCloudFrontSigner reads the CloudFront private key (path relative to the working directory).
+func loadCloudFrontSigner(keyGroupID, keyPath string) (*sign.URLSigner, error) {
 	dir, err := os.Getwd()
 	if err != nil {
 		return nil, errors.New("failed to get current directory")
 	}
 
-	privKeyBytes, err := os.ReadFile(dir + p.cloudFrontKeyPath)
+	privKeyBytes, err := os.ReadFile(dir + keyPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read private key file: %w", err)
 	}
@@ -156,22 +360,11 @@ func (p *s3Provider) GetFileUploaded(ctx context.Context, key string, duration *
 		return nil, fmt.Errorf("unsupported key type: %s", block.Type)
 	}
 
-	signer := sign.NewURLSigner(p.cloudFrontKeyGroupID, privKey)
-	url := fmt.Sprintf("%s/%s", p.domain, key)
-
-	if duration == nil {
-		duration = aws.Duration(24 * time.Hour)
-	}
-	signedURL, err := signer.Sign(url, time.Now().Add(*duration))
-	if err != nil {
-		return nil, fmt.Errorf("failed to sign URL: %w", err)
-	}
-
-	return &signedURL, nil
+	return sign.NewURLSigner(keyGroupID, privKey), nil
 }
 
 func (p *s3Provider) DeleteFileUploaded(ctx context.Context, key string) error {
-	client := s3.NewFromConfig(p.config)
+	client := p.client()
 
 	_, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(p.bucketName),
@@ -192,3 +385,302 @@ func (p *s3Provider) DeleteFileUploaded(ctx context.Context, key string) error {
 
 	return nil
 }
+
+func (p *s3Provider) CreateMultipartUpload(ctx context.Context, key string, contentType string) (string, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	client := p.client()
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(p.bucketName),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		ACL:         types.ObjectCannedACLPrivate,
+	}
+	// upload session luôn ở chế độ private
+	applyPutEncryption(input, p.encryption.ForWrite(key, UploadPrivate))
+	out, err := client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+	return aws.ToString(out.UploadId), nil
+}
+
+func (p *s3Provider) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, r io.Reader, size int64) (string, error) {
+	client := p.client()
+	input := &s3.UploadPartInput{
+		Bucket:        aws.String(p.bucketName),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(partNumber),
+		Body:          r,
+		ContentLength: aws.Int64(size),
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = customerKey(p.encryption.forRead(key))
+	out, err := client.UploadPart(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+	return aws.ToString(out.ETag), nil
+}
+
+func (p *s3Provider) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error {
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completed = append(completed, types.CompletedPart{
+			PartNumber: aws.Int32(part.PartNumber),
+			ETag:       aws.String(part.ETag),
+		})
+	}
+
+	client := p.client()
+	input := &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(p.bucketName),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = customerKey(p.encryption.forRead(key))
+	_, err := client.CompleteMultipartUpload(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+func (p *s3Provider) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	client := p.client()
+	_, err := client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(p.bucketName),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		var noSuchUpload *types.NoSuchUpload
+		if errors.As(err, &noSuchUpload) {
+			return ErrObjectNotFound
+		}
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	return nil
+}
+
+func (p *s3Provider) PresignPutObject(ctx context.Context, key string, contentType string, mode UploadMode, duration time.Duration) (string, map[string]string, error) {
+	enc := p.encryption.ForWrite(key, mode)
+	// không thể đưa key SSE-C cho client
+	if enc.isCustomerKey() {
+		return "", nil, ErrPresignNotSupported
+	}
+
+	client := p.client()
+	presignClient := s3.NewPresignClient(client)
+
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(p.bucketName),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	}
+	applyPutEncryption(input, enc)
+	req, err := presignClient.PresignPutObject(ctx, input, s3.WithPresignExpires(duration))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to presign put object: %w", err)
+	}
+
+	// header SSE nằm trong chữ ký, client phải gửi đúng như vậy
+	headers := map[string]string{}
+	if input.ServerSideEncryption != "" {
+		headers["x-amz-server-side-encryption"] = string(input.ServerSideEncryption)
+	}
+	if input.SSEKMSKeyId != nil {
+		headers["x-amz-server-side-encryption-aws-kms-key-id"] = *input.SSEKMSKeyId
+	}
+	return req.URL, headers, nil
+}
+
+func (p *s3Provider) HeadObject(ctx context.Context, key string) (*ObjectInfo, error) {
+	client := p.client()
+
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(p.bucketName),
+		Key:    aws.String(key),
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = customerKey(p.encryption.forRead(key))
+	out, err := client.HeadObject(ctx, input)
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to head object: %w", err)
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(out.ContentLength),
+		ContentType:  aws.ToString(out.ContentType),
+		ETag:         aws.ToString(out.ETag),
+		LastModified: aws.ToTime(out.LastModified),
+		StorageClass: StorageClass(out.StorageClass),
+		Restore:      parseRestoreHeader(aws.ToString(out.Restore)),
+	}, nil
+}
+
+func (p *s3Provider) ListObjects(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	paginator := s3.NewListObjectsV2Paginator(p.client(), &s3.ListObjectsV2Input{
+		Bucket: aws.String(p.bucketName),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			if err := fn(ObjectInfo{
+				Key:          aws.ToString(obj.Key),
+				Size:         aws.ToInt64(obj.Size),
+				ETag:         aws.ToString(obj.ETag),
+				LastModified: aws.ToTime(obj.LastModified),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *s3Provider) GetObject(ctx context.Context, key string, opts GetObjectOptions) (*ObjectReader, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(p.bucketName),
+		Key:    aws.String(key),
+	}
+	if opts.Range != "" {
+		input.Range = aws.String(opts.Range)
+	}
+	if opts.IfNoneMatch != "" {
+		input.IfNoneMatch = aws.String(opts.IfNoneMatch)
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = customerKey(p.encryption.forRead(key))
+
+	out, err := p.client().GetObject(ctx, input)
+	if err != nil {
+		var respErr *awshttp.ResponseError
+		if errors.As(err, &respErr) {
+			switch respErr.HTTPStatusCode() {
+			case http.StatusNotModified:
+				return &ObjectReader{ETag: respErr.Response.Header.Get("ETag")}, ErrNotModified
+			case http.StatusRequestedRangeNotSatisfiable:
+				// S3 không trả kích thước object kèm 416, cần HEAD để client biết khoảng hợp lệ
+				obj := &ObjectReader{}
+				if info, err := p.HeadObject(ctx, key); err == nil {
+					obj.ETag, obj.Size = info.ETag, info.Size
+				}
+				return obj, ErrInvalidRange
+			case http.StatusNotFound:
+				return nil, ErrObjectNotFound
+			}
+		}
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	obj := &ObjectReader{
+		Body:          out.Body,
+		ContentLength: aws.ToInt64(out.ContentLength),
+		ContentRange:  aws.ToString(out.ContentRange),
+		ContentType:   aws.ToString(out.ContentType),
+		ETag:          aws.ToString(out.ETag),
+		LastModified:  aws.ToTime(out.LastModified),
+		Size:          aws.ToInt64(out.ContentLength),
+	}
+	// "bytes a-b/size": phần sau dấu / là dung lượng cả object
+	if _, total, ok := strings.Cut(obj.ContentRange, "/"); ok {
+		if n, err := strconv.ParseInt(total, 10, 64); err == nil {
+			obj.Size = n
+		}
+	}
+	return obj, nil
+}
+
+func (p *s3Provider) CopyObject(ctx context.Context, srcKey, destKey string) error {
+	return p.copyObject(ctx, srcKey, destKey, "")
+}
+
+func (p *s3Provider) copyObject(ctx context.Context, srcKey, destKey string, class StorageClass) error {
+	input := &s3.CopyObjectInput{
+		Bucket:       aws.String(p.bucketName),
+		Key:          aws.String(destKey),
+		CopySource:   aws.String(url.PathEscape(p.bucketName + "/" + srcKey)),
+		StorageClass: types.StorageClass(class),
+	}
+	// S3 không giữ encryption của object nguồn khi copy, bản sao theo rule của destKey
+	applyPutEncryption(input, p.encryption.ForWrite(destKey, UploadPrivate))
+	input.CopySourceSSECustomerAlgorithm, input.CopySourceSSECustomerKey, input.CopySourceSSECustomerKeyMD5 = customerKey(p.encryption.forRead(srcKey))
+	_, err := p.client().CopyObject(ctx, input)
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return ErrObjectNotFound
+		}
+		var respErr *awshttp.ResponseError
+		if errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotFound {
+			return ErrObjectNotFound
+		}
+		return fmt.Errorf("failed to copy object: %w", err)
+	}
+	return nil
+}
+
+func (p *s3Provider) SetStorageClass(ctx context.Context, key string, class StorageClass) error {
+	// S3 cho phép copy đè lên chính nó khi đổi storage class, metadata được giữ nguyên
+	return p.copyObject(ctx, key, key, class.Normalize())
+}
+
+func (p *s3Provider) TagObject(ctx context.Context, key string, tags map[string]string) error {
+	tagSet := make([]types.Tag, 0, len(tags))
+	for k, v := range tags {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	_, err := p.client().PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(p.bucketName),
+		Key:     aws.String(key),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		var respErr *awshttp.ResponseError
+		if errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotFound {
+			return ErrObjectNotFound
+		}
+		return fmt.Errorf("failed to tag object: %w", err)
+	}
+	return nil
+}
+
+func (p *s3Provider) RestoreObject(ctx context.Context, key string, days int32, tier string) error {
+	_, err := p.client().RestoreObject(ctx, &s3.RestoreObjectInput{
+		Bucket: aws.String(p.bucketName),
+		Key:    aws.String(key),
+		RestoreRequest: &types.RestoreRequest{
+			Days:                 aws.Int32(days),
+			GlacierJobParameters: &types.GlacierJobParameters{Tier: types.Tier(tier)},
+		},
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress" {
+			return nil
+		}
+		var activeTier *types.ObjectAlreadyInActiveTierError
+		if errors.As(err, &activeTier) {
+			return nil
+		}
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return ErrObjectNotFound
+		}
+		return fmt.Errorf("failed to restore object: %w", err)
+	}
+	return nil
+}
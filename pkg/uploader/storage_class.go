@@ -0,0 +1,90 @@
+package uploader
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StorageClass is the S3 storage class of an object; empty means STANDARD.
+type StorageClass string
+
+const (
+	StorageClassStandard           StorageClass = "STANDARD"
+	StorageClassStandardIA         StorageClass = "STANDARD_IA"
+	StorageClassIntelligentTiering StorageClass = "INTELLIGENT_TIERING"
+	StorageClassGlacierIR          StorageClass = "GLACIER_IR"
+	StorageClassGlacier            StorageClass = "GLACIER"
+	StorageClassDeepArchive        StorageClass = "DEEP_ARCHIVE"
+)
+
+// coldness dùng để so sánh hai class, số càng lớn càng rẻ / đọc càng chậm
+var coldness = map[StorageClass]int{
+	StorageClassStandard:           0,
+	StorageClassIntelligentTiering: 1,
+	StorageClassStandardIA:         1,
+	StorageClassGlacierIR:          2,
+	StorageClassGlacier:            3,
+	StorageClassDeepArchive:        4,
+}
+
+// ParseStorageClass accepts the S3 names case-insensitively.
+func ParseStorageClass(s string) (StorageClass, error) {
+	class := StorageClass(strings.ToUpper(strings.TrimSpace(s)))
+	if class == "" {
+		return StorageClassStandard, nil
+	}
+	if _, ok := coldness[class]; !ok {
+		return "", fmt.Errorf("unknown storage class %q", s)
+	}
+	return class, nil
+}
+
+// Normalize maps the empty class reported for standard objects to STANDARD.
+func (c StorageClass) Normalize() StorageClass {
+	if c == "" {
+		return StorageClassStandard
+	}
+	return c
+}
+
+// ColderThan reports whether c is a cheaper tier than other.
+func (c StorageClass) ColderThan(other StorageClass) bool {
+	return coldness[c.Normalize()] > coldness[other.Normalize()]
+}
+
+// IsArchived reports whether objects in this class must be restored before they can be read.
+func (c StorageClass) IsArchived() bool {
+	return c == StorageClassGlacier || c == StorageClassDeepArchive
+}
+
+// AtLeastAsCold returns every known class not warmer than c.
+func (c StorageClass) AtLeastAsCold() []StorageClass {
+	out := make([]StorageClass, 0, len(coldness))
+	for class, level := range coldness {
+		if level >= coldness[c.Normalize()] {
+			out = append(out, class)
+		}
+	}
+	return out
+}
+
+// RestoreStatus is the state of the temporary copy of an archived object.
+type RestoreStatus string
+
+const (
+	RestoreNone       RestoreStatus = ""            // chưa yêu cầu khôi phục (hoặc bản tạm đã hết hạn)
+	RestoreInProgress RestoreStatus = "in_progress" // đang khôi phục, GLACIER mất vài phút tới vài giờ
+	RestoreCompleted  RestoreStatus = "completed"   // bản tạm đọc được như object thường
+)
+
+// parseRestoreHeader đọc header x-amz-restore, ví dụ `ongoing-request="false", expiry-date="..."`
+func parseRestoreHeader(header string) RestoreStatus {
+	switch {
+	case header == "":
+		return RestoreNone
+	case strings.Contains(header, `ongoing-request="true"`):
+		return RestoreInProgress
+	default:
+		return RestoreCompleted
+	}
+}